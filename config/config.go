@@ -1,21 +1,27 @@
 package config
 
 import (
+	"errors"
+	"io"
 	"os"
 
 	"github.com/adrianliechti/wingman/pkg/auth"
 	"github.com/adrianliechti/wingman/pkg/extractor"
 	"github.com/adrianliechti/wingman/pkg/guard"
+	"github.com/adrianliechti/wingman/pkg/limiter"
 	"github.com/adrianliechti/wingman/pkg/mcp"
+	"github.com/adrianliechti/wingman/pkg/memory"
 	"github.com/adrianliechti/wingman/pkg/policy"
 	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/researcher"
+	"github.com/adrianliechti/wingman/pkg/router/classifier"
 	"github.com/adrianliechti/wingman/pkg/scraper"
 	"github.com/adrianliechti/wingman/pkg/searcher"
 	"github.com/adrianliechti/wingman/pkg/segmenter"
 	"github.com/adrianliechti/wingman/pkg/summarizer"
 	"github.com/adrianliechti/wingman/pkg/tool"
 	"github.com/adrianliechti/wingman/pkg/translator"
+	"github.com/adrianliechti/wingman/pkg/workspace"
 
 	"go.yaml.in/yaml/v4"
 )
@@ -26,6 +32,12 @@ type Config struct {
 	Policy      policy.Provider
 	Authorizers []auth.Provider
 
+	Priority *limiter.Selector
+
+	// CodeInterpreter is the sandbox tool running the code_interpreter tool
+	// of Responses API requests, if enabled
+	CodeInterpreter tool.Provider
+
 	models map[string]provider.Model
 
 	completer   map[string]provider.Completer
@@ -46,10 +58,47 @@ type Config struct {
 	searcher   map[string]searcher.Provider
 	researcher map[string]researcher.Provider
 
+	memory map[string]memory.Provider
+
+	// memoryAdmins are the memory stores whose memories callers granted
+	// access by the policy may manage for other users
+	memoryAdmins map[string]bool
+
+	// upstreams are the provider model names of the configured models,
+	// which may differ from the ids clients and routers use
+	upstreams map[string]string
+
+	workspace map[string]workspace.Provider
+
 	tools  map[string]tool.Provider
 	agents map[string]provider.Completer
 
+	// prompters are the tools offering prompts agents can import
+	prompters map[string]prompter
+
+	// agentMessages are the messages of the agents, offered as prompts by
+	// MCP servers
+	agentMessages map[string][]provider.Message
+
 	mcps map[string]mcp.Provider
+
+	classifiers map[string]*classifier.Completer
+
+	// closers are the providers holding processes or connections to release
+	// on shutdown
+	closers []io.Closer
+}
+
+// Close releases the providers holding processes or connections, such as
+// stdio MCP servers
+func (cfg *Config) Close() error {
+	var result error
+
+	for _, c := range cfg.closers {
+		result = errors.Join(result, c.Close())
+	}
+
+	return result
 }
 
 func Parse(path string) (*Config, error) {
@@ -71,6 +120,10 @@ func Parse(path string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := c.registerPriority(file); err != nil {
+		return nil, err
+	}
+
 	if err := c.registerProviders(file); err != nil {
 		return nil, err
 	}
@@ -111,6 +164,14 @@ func Parse(path string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := c.registerMemories(file); err != nil {
+		return nil, err
+	}
+
+	if err := c.registerWorkspaces(file); err != nil {
+		return nil, err
+	}
+
 	if err := c.registerTools(file); err != nil {
 		return nil, err
 	}
@@ -131,6 +192,8 @@ type configFile struct {
 
 	Policy *policyConfig `yaml:"policy"`
 
+	Priority *priorityConfig `yaml:"priority"`
+
 	Providers []providerConfig `yaml:"providers"`
 
 	Extractors  yaml.Node `yaml:"extractors"`
@@ -144,6 +207,10 @@ type configFile struct {
 	Searchers   yaml.Node `yaml:"searchers"`
 	Researchers yaml.Node `yaml:"researchers"`
 
+	Memories yaml.Node `yaml:"memories"`
+
+	Workspaces yaml.Node `yaml:"workspaces"`
+
 	Tools  yaml.Node `yaml:"tools"`
 	Agents yaml.Node `yaml:"agents"`
 
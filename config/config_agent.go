@@ -1,13 +1,17 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/adrianliechti/wingman/pkg/agent/assistant"
+	"github.com/adrianliechti/wingman/pkg/agent/planner"
 	"github.com/adrianliechti/wingman/pkg/agent/react"
+	"github.com/adrianliechti/wingman/pkg/memory"
 	"github.com/adrianliechti/wingman/pkg/otel"
 	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/tool"
@@ -30,12 +34,73 @@ type agentConfig struct {
 
 	Messages []message `yaml:"messages"`
 
+	// Prompt imports a prompt of an MCP tool as the first messages
+	Prompt *agentPromptConfig `yaml:"prompt"`
+
 	Tools []string `yaml:"tools"`
 
+	// RequiresApproval lists tools of a react agent whose calls wait for
+	// the caller's approval before they run
+	RequiresApproval []string `yaml:"requires_approval"`
+
 	Effort    string `yaml:"effort"`
 	Verbosity string `yaml:"verbosity"`
 
 	Temperature *float32 `yaml:"temperature"`
+
+	// Limits bounds each run of a react agent's tool loop
+	Limits *agentLimitsConfig `yaml:"limits"`
+
+	// ParallelToolCalls is the number of tool calls of a turn a react agent
+	// runs concurrently. Up to 1 keeps calls serial.
+	ParallelToolCalls int `yaml:"parallel_tool_calls"`
+
+	// Memory is the memory store a react agent keeps per-user memories in
+	Memory string `yaml:"memory"`
+
+	// Compaction summarizes older turns of a react agent's loop as the
+	// prompt grows
+	Compaction *agentCompactionConfig `yaml:"compaction"`
+
+	// StreamToolProgress reports a react agent's own tool calls to API
+	// clients while they run
+	StreamToolProgress bool `yaml:"stream_tool_progress"`
+
+	// MaxSteps bounds the steps a planner agent runs per request
+	MaxSteps *int `yaml:"max_steps"`
+
+	// MaxRevisions bounds how often a planner agent revises its plan after
+	// failed steps
+	MaxRevisions *int `yaml:"max_revisions"`
+}
+
+type agentLimitsConfig struct {
+	MaxTurns  int    `yaml:"max_turns"`
+	MaxTokens int    `yaml:"max_tokens"`
+	Timeout   string `yaml:"timeout"`
+
+	// MaxRepeatedCalls is how often a tool may be called with identical
+	// arguments before the loop is ended
+	MaxRepeatedCalls int `yaml:"max_repeated_calls"`
+}
+
+type agentCompactionConfig struct {
+	// Threshold is the estimated prompt size in tokens that triggers
+	// compaction; defaults to 80% of the model's max_context
+	Threshold int `yaml:"threshold"`
+
+	KeepTurns int `yaml:"keep_turns"`
+
+	// Summarizer writes the summaries instead of the agent's model
+	Summarizer string `yaml:"summarizer"`
+}
+
+type agentPromptConfig struct {
+	// Tool is the mcp tool whose server offers the prompt
+	Tool string `yaml:"tool"`
+
+	Name      string            `yaml:"name"`
+	Arguments map[string]string `yaml:"arguments"`
 }
 
 type agentContext struct {
@@ -45,10 +110,26 @@ type agentContext struct {
 
 	Tools map[string]tool.Provider
 
+	// Approval holds the ids of tools that need the caller's approval
+	Approval map[string]bool
+
 	Effort    provider.Effort
 	Verbosity provider.Verbosity
 
 	Temperature *float32
+
+	Limits *react.Limits
+
+	ParallelToolCalls int
+
+	Memory memory.Provider
+
+	Compaction *react.Compaction
+
+	StreamToolProgress bool
+
+	MaxSteps     *int
+	MaxRevisions *int
 }
 
 func (cfg *Config) registerAgents(f *configFile) error {
@@ -58,6 +139,8 @@ func (cfg *Config) registerAgents(f *configFile) error {
 		return err
 	}
 
+	cfg.agentMessages = make(map[string][]provider.Message)
+
 	for _, node := range f.Agents.Content {
 		id := node.Value
 
@@ -70,12 +153,34 @@ func (cfg *Config) registerAgents(f *configFile) error {
 		context := agentContext{
 			Messages: make([]provider.Message, 0),
 
-			Tools: make(map[string]tool.Provider),
+			Tools:    make(map[string]tool.Provider),
+			Approval: make(map[string]bool),
 
 			Effort:    provider.Effort(config.Effort),
 			Verbosity: provider.Verbosity(config.Verbosity),
 
 			Temperature: config.Temperature,
+
+			ParallelToolCalls: config.ParallelToolCalls,
+
+			StreamToolProgress: config.StreamToolProgress,
+
+			MaxSteps:     config.MaxSteps,
+			MaxRevisions: config.MaxRevisions,
+		}
+
+		if config.ParallelToolCalls < 0 {
+			return errors.New("invalid parallel_tool_calls: must not be negative")
+		}
+
+		if config.Limits != nil {
+			limits, err := agentLimits(*config.Limits)
+
+			if err != nil {
+				return err
+			}
+
+			context.Limits = limits
 		}
 
 		if config.Model != "" {
@@ -84,6 +189,26 @@ func (cfg *Config) registerAgents(f *configFile) error {
 			}
 		}
 
+		if config.Compaction != nil {
+			compaction, err := cfg.agentCompaction(config.Model, *config.Compaction)
+
+			if err != nil {
+				return err
+			}
+
+			context.Compaction = compaction
+		}
+
+		if config.Memory != "" {
+			p, err := cfg.Memory(config.Memory)
+
+			if err != nil {
+				return err
+			}
+
+			context.Memory = p
+		}
+
 		for _, t := range config.Tools {
 			tool, err := cfg.Tool(t)
 
@@ -94,6 +219,24 @@ func (cfg *Config) registerAgents(f *configFile) error {
 			context.Tools[t] = tool
 		}
 
+		for _, t := range config.RequiresApproval {
+			if _, ok := context.Tools[t]; !ok {
+				return errors.New("requires_approval tool not in agent tools: " + t)
+			}
+
+			context.Approval[t] = true
+		}
+
+		if config.Prompt != nil {
+			messages, err := cfg.importPrompt(*config.Prompt)
+
+			if err != nil {
+				return err
+			}
+
+			context.Messages = append(context.Messages, messages...)
+		}
+
 		if config.Messages != nil {
 			messages, err := parseMessages(config.Messages)
 
@@ -101,9 +244,11 @@ func (cfg *Config) registerAgents(f *configFile) error {
 				return err
 			}
 
-			context.Messages = messages
+			context.Messages = append(context.Messages, messages...)
 		}
 
+		cfg.agentMessages[id] = context.Messages
+
 		a, err := createAgent(config, context)
 
 		if err != nil {
@@ -118,6 +263,25 @@ func (cfg *Config) registerAgents(f *configFile) error {
 	return nil
 }
 
+// prompter is implemented by tools offering prompts, such as MCP servers
+type prompter interface {
+	Prompt(ctx context.Context, name string, arguments map[string]string) ([]provider.Message, error)
+}
+
+// importPrompt renders an MCP prompt once, when the config is loaded
+func (cfg *Config) importPrompt(c agentPromptConfig) ([]provider.Message, error) {
+	p, ok := cfg.prompters[c.Tool]
+
+	if !ok {
+		return nil, errors.New("prompt tool not found or without prompts: " + c.Tool)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return p.Prompt(ctx, c.Name, c.Arguments)
+}
+
 func createAgent(cfg agentConfig, context agentContext) (provider.Completer, error) {
 	switch strings.ToLower(cfg.Type) {
 	case "react":
@@ -126,19 +290,40 @@ func createAgent(cfg agentConfig, context agentContext) (provider.Completer, err
 	case "assistant":
 		return assistantAgent(cfg, context)
 
+	case "planner":
+		return plannerAgent(cfg, context)
+
 	default:
 		return nil, errors.New("invalid agent type: " + cfg.Type)
 	}
 }
 
 func reactAgent(cfg agentConfig, context agentContext) (provider.Completer, error) {
+	if context.MaxSteps != nil || context.MaxRevisions != nil {
+		return nil, errors.New("max_steps and max_revisions are only supported for planner agents")
+	}
+
 	var options []react.Option
 
 	if context.Completer != nil {
 		options = append(options, react.WithCompleter(context.Completer))
 	}
 
-	options = append(options, react.WithTools(slices.Collect(maps.Values(context.Tools))...))
+	var tools, approvalTools []tool.Provider
+
+	for _, id := range slices.Sorted(maps.Keys(context.Tools)) {
+		if context.Approval[id] {
+			approvalTools = append(approvalTools, context.Tools[id])
+		} else {
+			tools = append(tools, context.Tools[id])
+		}
+	}
+
+	options = append(options, react.WithTools(tools...))
+
+	if len(approvalTools) > 0 {
+		options = append(options, react.WithApprovalTools(approvalTools...))
+	}
 
 	if context.Messages != nil {
 		options = append(options, react.WithMessages(context.Messages...))
@@ -156,10 +341,115 @@ func reactAgent(cfg agentConfig, context agentContext) (provider.Completer, erro
 		options = append(options, react.WithTemperature(*context.Temperature))
 	}
 
+	if context.Limits != nil {
+		options = append(options, react.WithLimits(*context.Limits))
+	}
+
+	if context.ParallelToolCalls > 1 {
+		options = append(options, react.WithParallelToolCalls(context.ParallelToolCalls))
+	}
+
+	if context.Memory != nil {
+		options = append(options, react.WithMemory(context.Memory))
+	}
+
+	if context.Compaction != nil {
+		options = append(options, react.WithCompaction(*context.Compaction))
+	}
+
+	if context.StreamToolProgress {
+		options = append(options, react.WithToolProgress())
+	}
+
 	return react.New(cfg.Model, options...)
 }
 
+func agentLimits(cfg agentLimitsConfig) (*react.Limits, error) {
+	if cfg.MaxTurns < 0 || cfg.MaxTokens < 0 || cfg.MaxRepeatedCalls < 0 {
+		return nil, errors.New("invalid agent limits: must not be negative")
+	}
+
+	limits := &react.Limits{
+		MaxTurns:         cfg.MaxTurns,
+		MaxTokens:        cfg.MaxTokens,
+		MaxRepeatedCalls: cfg.MaxRepeatedCalls,
+	}
+
+	if cfg.Timeout != "" {
+		timeout, err := parseTimeout("timeout", cfg.Timeout)
+
+		if err != nil {
+			return nil, err
+		}
+
+		limits.MaxDuration = timeout
+	}
+
+	return limits, nil
+}
+
+func (cfg *Config) agentCompaction(model string, c agentCompactionConfig) (*react.Compaction, error) {
+	if c.Threshold < 0 || c.KeepTurns < 0 {
+		return nil, errors.New("invalid compaction: must not be negative")
+	}
+
+	compaction := &react.Compaction{
+		Threshold: c.Threshold,
+		KeepTurns: c.KeepTurns,
+	}
+
+	if compaction.Threshold == 0 {
+		if m, err := cfg.Model(model); err == nil && m.MaxContext > 0 {
+			compaction.Threshold = m.MaxContext * 8 / 10
+		}
+	}
+
+	if compaction.Threshold == 0 {
+		return nil, errors.New("compaction requires a threshold or the max_context of model " + model)
+	}
+
+	if c.Summarizer != "" {
+		p, err := cfg.Summarizer(c.Summarizer)
+
+		if err != nil {
+			return nil, err
+		}
+
+		compaction.Summarizer = p
+	}
+
+	return compaction, nil
+}
+
 func assistantAgent(cfg agentConfig, context agentContext) (provider.Completer, error) {
+	if context.Limits != nil {
+		return nil, errors.New("limits are only supported for react and planner agents")
+	}
+
+	if len(context.Approval) > 0 {
+		return nil, errors.New("requires_approval is only supported for react agents")
+	}
+
+	if context.ParallelToolCalls > 1 {
+		return nil, errors.New("parallel_tool_calls is only supported for react and planner agents")
+	}
+
+	if context.Memory != nil {
+		return nil, errors.New("memory is only supported for react agents")
+	}
+
+	if context.Compaction != nil {
+		return nil, errors.New("compaction is only supported for react agents")
+	}
+
+	if context.StreamToolProgress {
+		return nil, errors.New("stream_tool_progress is only supported for react and planner agents")
+	}
+
+	if context.MaxSteps != nil || context.MaxRevisions != nil {
+		return nil, errors.New("max_steps and max_revisions are only supported for planner agents")
+	}
+
 	var options []assistant.Option
 
 	if context.Completer != nil {
@@ -184,3 +474,70 @@ func assistantAgent(cfg agentConfig, context agentContext) (provider.Completer,
 
 	return assistant.New(cfg.Model, options...)
 }
+
+func plannerAgent(cfg agentConfig, context agentContext) (provider.Completer, error) {
+	if len(context.Approval) > 0 {
+		return nil, errors.New("requires_approval is not supported for planner agents")
+	}
+
+	if context.Memory != nil {
+		return nil, errors.New("memory is not supported for planner agents")
+	}
+
+	if context.Compaction != nil {
+		return nil, errors.New("compaction is not supported for planner agents")
+	}
+
+	var options []planner.Option
+
+	if context.Completer != nil {
+		options = append(options, planner.WithCompleter(context.Completer))
+	}
+
+	var tools []tool.Provider
+
+	for _, id := range slices.Sorted(maps.Keys(context.Tools)) {
+		tools = append(tools, context.Tools[id])
+	}
+
+	options = append(options, planner.WithTools(tools...))
+
+	if context.Messages != nil {
+		options = append(options, planner.WithMessages(context.Messages...))
+	}
+
+	if context.Effort != "" {
+		options = append(options, planner.WithEffort(context.Effort))
+	}
+
+	if context.Verbosity != "" {
+		options = append(options, planner.WithVerbosity(context.Verbosity))
+	}
+
+	if context.Temperature != nil {
+		options = append(options, planner.WithTemperature(*context.Temperature))
+	}
+
+	if context.MaxSteps != nil {
+		options = append(options, planner.WithMaxSteps(*context.MaxSteps))
+	}
+
+	if context.MaxRevisions != nil {
+		options = append(options, planner.WithMaxRevisions(*context.MaxRevisions))
+	}
+
+	// Limits, parallel calls and progress apply to the run of each step
+	if context.Limits != nil {
+		options = append(options, planner.WithStepOptions(react.WithLimits(*context.Limits)))
+	}
+
+	if context.ParallelToolCalls > 1 {
+		options = append(options, planner.WithStepOptions(react.WithParallelToolCalls(context.ParallelToolCalls)))
+	}
+
+	if context.StreamToolProgress {
+		options = append(options, planner.WithStepOptions(react.WithToolProgress()))
+	}
+
+	return planner.New(cfg.Model, options...)
+}
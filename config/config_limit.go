@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/limiter"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// limitConfig caps the concurrent requests to a model or router. Requests
+// beyond max_concurrency wait in a queue of up to max_queue entries for at
+// most queue_timeout, interactive requests ahead of batch requests.
+type limitConfig struct {
+	MaxConcurrency int    `yaml:"max_concurrency"`
+	MaxQueue       int    `yaml:"max_queue"`
+	QueueTimeout   string `yaml:"queue_timeout"`
+}
+
+type priorityConfig struct {
+	// Header selects the priority class per request ("interactive" or
+	// "batch"). Defaults to X-Priority
+	Header string `yaml:"header"`
+
+	// BatchGroups are auth groups whose requests are always queued as batch
+	BatchGroups []string `yaml:"batch_groups"`
+}
+
+func (cfg *Config) registerPriority(f *configFile) error {
+	cfg.Priority = &limiter.Selector{}
+
+	if f.Priority == nil {
+		return nil
+	}
+
+	cfg.Priority.Header = f.Priority.Header
+	cfg.Priority.BatchGroups = f.Priority.BatchGroups
+
+	return nil
+}
+
+// limitCompleter wraps the completer with a concurrency limiter when a limit
+// is configured
+func limitCompleter(id string, c limitConfig, completer provider.Completer) (provider.Completer, error) {
+	if c.MaxConcurrency == 0 {
+		if c.MaxQueue != 0 || c.QueueTimeout != "" {
+			return nil, errors.New("max_queue and queue_timeout require max_concurrency")
+		}
+
+		return completer, nil
+	}
+
+	if c.MaxConcurrency < 0 {
+		return nil, errors.New("invalid max_concurrency: must not be negative")
+	}
+
+	var timeout time.Duration
+
+	if c.QueueTimeout != "" {
+		t, err := parseTimeout("queue_timeout", c.QueueTimeout)
+
+		if err != nil {
+			return nil, err
+		}
+
+		timeout = t
+	}
+
+	l, err := limiter.New(id, c.MaxConcurrency, c.MaxQueue, timeout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return limiter.NewCompleter(l, completer), nil
+}
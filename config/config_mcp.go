@@ -3,14 +3,20 @@ package config
 import (
 	"errors"
 	"maps"
+	"path"
 	"slices"
 	"sort"
 	"strings"
 
 	"github.com/adrianliechti/wingman/pkg/mcp"
+	"github.com/adrianliechti/wingman/pkg/mcp/platform"
 	"github.com/adrianliechti/wingman/pkg/mcp/proxy"
 	"github.com/adrianliechti/wingman/pkg/mcp/server"
+	"github.com/adrianliechti/wingman/pkg/policy"
 	"github.com/adrianliechti/wingman/pkg/tool"
+	"github.com/adrianliechti/wingman/pkg/workspace"
+
+	mcptool "github.com/adrianliechti/wingman/pkg/tool/mcp"
 )
 
 func (cfg *Config) RegisterMCP(id string, p mcp.Provider) {
@@ -50,6 +56,11 @@ type mcpConfig struct {
 
 	URL string `yaml:"url"`
 
+	// Command runs a stdio MCP server a proxy exposes over HTTP
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	Env     map[string]string `yaml:"env"`
+
 	Tools []string `yaml:"tools"`
 
 	Vars  map[string]string `yaml:"vars"`
@@ -57,10 +68,27 @@ type mcpConfig struct {
 	Proxy *proxyConfig      `yaml:"proxy"`
 
 	Instructions string `yaml:"instructions"`
+
+	// Prompts offers the messages of these agents as prompts
+	Prompts []string `yaml:"prompts"`
+
+	// Workspace offers the files of the caller's session as resources
+	Workspace string `yaml:"workspace"`
 }
 
 type mcpContext struct {
+	ID string
+
 	Tools map[string]tool.Provider
+
+	// Process is the client of the stdio server of a proxy
+	Process tool.Provider
+
+	Prompts   []server.Prompt
+	Workspace workspace.Provider
+
+	Policy       policy.Provider
+	Capabilities platform.Capabilities
 }
 
 func (cfg *Config) registerMCP(f *configFile) error {
@@ -80,7 +108,25 @@ func (cfg *Config) registerMCP(f *configFile) error {
 		}
 
 		context := mcpContext{
+			ID: id,
+
 			Tools: make(map[string]tool.Provider),
+
+			Policy: cfg.Policy,
+
+			Capabilities: platform.Capabilities{
+				Extractors:  cfg.extractor,
+				Translators: cfg.translator,
+
+				Searchers:   cfg.searcher,
+				Scrapers:    cfg.scraper,
+				Researchers: cfg.researcher,
+
+				Renderers:    cfg.renderer,
+				Transcribers: cfg.transcriber,
+
+				Agents: cfg.agents,
+			},
 		}
 
 		for _, t := range config.Tools {
@@ -93,6 +139,43 @@ func (cfg *Config) registerMCP(f *configFile) error {
 			context.Tools[t] = tool
 		}
 
+		for _, a := range config.Prompts {
+			messages, ok := cfg.agentMessages[a]
+
+			if !ok {
+				return errors.New("agent not found: " + a)
+			}
+
+			context.Prompts = append(context.Prompts, server.Prompt{
+				Name:        a,
+				Description: "Instructions of the " + a + " agent",
+
+				Messages: messages,
+			})
+		}
+
+		if config.Workspace != "" {
+			p, err := cfg.Workspace(config.Workspace)
+
+			if err != nil {
+				return err
+			}
+
+			context.Workspace = p
+		}
+
+		if config.Command != "" {
+			client, err := mcptool.NewCommand(config.Command, config.Args, config.Env)
+
+			if err != nil {
+				return err
+			}
+
+			cfg.closers = append(cfg.closers, client)
+
+			context.Process = policy.NewTool(cfg.Policy, id, client)
+		}
+
 		mcp, err := createMCP(config, context)
 
 		if err != nil {
@@ -111,6 +194,8 @@ func createMCP(cfg mcpConfig, context mcpContext) (mcp.Provider, error) {
 		return serverMCP(cfg, context)
 	case "proxy":
 		return proxyMCP(cfg, context)
+	case "platform":
+		return platformMCP(cfg, context)
 	default:
 		return nil, errors.New("invalid mcp type: " + cfg.Type)
 	}
@@ -119,15 +204,50 @@ func createMCP(cfg mcpConfig, context mcpContext) (mcp.Provider, error) {
 func serverMCP(cfg mcpConfig, context mcpContext) (mcp.Provider, error) {
 	tools := slices.Collect(maps.Values(context.Tools))
 
-	return server.New(cfg.Name, cfg.Instructions, tools)
+	var options []server.Option
+
+	if len(context.Prompts) > 0 {
+		options = append(options, server.WithPrompts(context.Prompts...))
+	}
+
+	if context.Workspace != nil {
+		options = append(options, server.WithWorkspace(context.Workspace))
+	}
+
+	return server.New(cfg.Name, cfg.Instructions, tools, options...)
+}
+
+func platformMCP(cfg mcpConfig, context mcpContext) (mcp.Provider, error) {
+	name := cfg.Name
+
+	if name == "" {
+		name = "wingman"
+	}
+
+	return platform.New(name, cfg.Instructions, context.Capabilities, context.Policy)
 }
 
 func proxyMCP(cfg mcpConfig, context mcpContext) (mcp.Provider, error) {
+	// A stdio server is served over HTTP with the tools it provides
+	if context.Process != nil {
+		if cfg.URL != "" {
+			return nil, errors.New("mcp proxy takes either a url or a command")
+		}
+
+		name := cfg.Name
+
+		if name == "" {
+			name = path.Base(cfg.Command)
+		}
+
+		return server.New(name, cfg.Instructions, []tool.Provider{context.Process})
+	}
+
 	exchanger, err := createClientAuth(cfg.Auth)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return proxy.New(cfg.URL, cfg.Vars, exchanger)
+	return proxy.New(cfg.URL, cfg.Vars, exchanger, proxy.WithPolicy(context.Policy, context.ID))
 }
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/memory"
+	"github.com/adrianliechti/wingman/pkg/memory/file"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+func (cfg *Config) RegisterMemory(id string, p memory.Provider) {
+	if cfg.memory == nil {
+		cfg.memory = make(map[string]memory.Provider)
+	}
+
+	cfg.memory[id] = p
+}
+
+// MemoryAdmin reports whether callers the policy grants access to the memory
+// store id may manage the memories of other users
+func (cfg *Config) MemoryAdmin(id string) bool {
+	return cfg.memoryAdmins[id]
+}
+
+func (cfg *Config) Memory(id string) (memory.Provider, error) {
+	if cfg.memory != nil {
+		if p, ok := cfg.memory[id]; ok {
+			return p, nil
+		}
+	}
+
+	return nil, errors.New("memory not found: " + id)
+}
+
+// Memories returns the ids of the configured memory stores
+func (cfg *Config) Memories() []string {
+	var ids []string
+
+	for id := range cfg.memory {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
+type memoryConfig struct {
+	Type string `yaml:"type"`
+
+	Path string `yaml:"path"`
+
+	// Embedder recalls memories by semantic similarity instead of keywords
+	Embedder string `yaml:"embedder"`
+
+	// MaxMemories limits the memories kept per user
+	MaxMemories int `yaml:"max_memories"`
+
+	// Admin lets callers the policy grants access to the store manage the
+	// memories of other users over the API
+	Admin bool `yaml:"admin"`
+}
+
+type memoryContext struct {
+	Embedder provider.Embedder
+}
+
+func (cfg *Config) registerMemories(f *configFile) error {
+	var configs map[string]memoryConfig
+
+	if err := decodeStrict(&f.Memories, &configs); err != nil {
+		return err
+	}
+
+	for _, node := range f.Memories.Content {
+		id := node.Value
+
+		config, ok := configs[node.Value]
+
+		if !ok {
+			continue
+		}
+
+		if config.Admin && f.Policy == nil {
+			return errors.New("memory " + id + ": admin requires a policy")
+		}
+
+		context := memoryContext{}
+
+		if config.Embedder != "" {
+			p, err := cfg.Embedder(config.Embedder)
+
+			if err != nil {
+				return err
+			}
+
+			context.Embedder = p
+		}
+
+		memory, err := createMemory(config, context)
+
+		if err != nil {
+			return err
+		}
+
+		cfg.RegisterMemory(id, memory)
+
+		if config.Admin {
+			if cfg.memoryAdmins == nil {
+				cfg.memoryAdmins = make(map[string]bool)
+			}
+
+			cfg.memoryAdmins[id] = true
+		}
+	}
+
+	return nil
+}
+
+func createMemory(cfg memoryConfig, context memoryContext) (memory.Provider, error) {
+	switch strings.ToLower(cfg.Type) {
+	case "file", "":
+		return fileMemory(cfg, context)
+
+	default:
+		return nil, errors.New("invalid memory type: " + cfg.Type)
+	}
+}
+
+func fileMemory(cfg memoryConfig, context memoryContext) (memory.Provider, error) {
+	var options []file.Option
+
+	if context.Embedder != nil {
+		options = append(options, file.WithEmbedder(context.Embedder))
+	}
+
+	if cfg.MaxMemories > 0 {
+		options = append(options, file.WithMaxMemories(cfg.MaxMemories))
+	}
+
+	return file.New(cfg.Path, options...)
+}
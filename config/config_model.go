@@ -23,6 +23,38 @@ func (cfg *Config) RegisterModel(id string) {
 	}
 }
 
+// describeModel attaches the configured metadata to a registered model
+func (cfg *Config) describeModel(id string, m modelConfig) {
+	model, ok := cfg.models[id]
+
+	if !ok {
+		return
+	}
+
+	if cfg.upstreams == nil {
+		cfg.upstreams = make(map[string]string)
+	}
+
+	cfg.upstreams[id] = m.ID
+
+	if m.MaxContext > 0 {
+		model.MaxContext = m.MaxContext
+	}
+
+	if m.Dimensions > 0 {
+		model.Dimensions = m.Dimensions
+	}
+
+	if m.Pricing != nil {
+		model.Pricing = &provider.Pricing{
+			Input:  m.Pricing.Input,
+			Output: m.Pricing.Output,
+		}
+	}
+
+	cfg.models[id] = model
+}
+
 func (cfg *Config) Models() []provider.Model {
 	var result []provider.Model
 
@@ -66,6 +98,29 @@ type modelConfig struct {
 	Description string `yaml:"description"`
 
 	MaxRetries *int `yaml:"max_retries"`
+
+	// SchemaRetries overrides the provider's schema_retries
+	SchemaRetries *int `yaml:"schema_retries"`
+
+	// MaxContext is the model's context window in tokens. Routers use it to
+	// skip members that cannot fit a prompt.
+	MaxContext int `yaml:"max_context"`
+
+	// Dimensions is an embedder's vector size. Embedding routers reject
+	// members of different dimensions.
+	Dimensions int `yaml:"dimensions"`
+
+	// Pricing is the model's list price. Cost-aware routers require it on
+	// their members.
+	Pricing *pricingConfig `yaml:"pricing"`
+
+	limitConfig `yaml:",inline"`
+}
+
+// pricingConfig is a list price in USD per million tokens
+type pricingConfig struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
 }
 
 type modelContext struct {
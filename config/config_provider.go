@@ -8,6 +8,7 @@ import (
 	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/provider/adapter/reranker"
 	"github.com/adrianliechti/wingman/pkg/provider/adapter/signatures"
+	"github.com/adrianliechti/wingman/pkg/provider/adapter/structured"
 
 	"go.yaml.in/yaml/v4"
 )
@@ -100,6 +101,26 @@ func (cfg *Config) registerProviders(f *configFile) error {
 					completer = otel.NewCompleter(p.Type, id, completer)
 				}
 
+				schemaRetries := m.SchemaRetries
+
+				if schemaRetries == nil {
+					schemaRetries = p.SchemaRetries
+				}
+
+				if schemaRetries != nil {
+					if *schemaRetries < 0 {
+						return errors.New("invalid schema_retries: must not be negative")
+					}
+
+					completer = structured.FromCompleter(id, completer, *schemaRetries)
+				}
+
+				completer, err = limitCompleter(id, m.limitConfig, completer)
+
+				if err != nil {
+					return err
+				}
+
 				cfg.RegisterCompleter(id, completer)
 				cfg.RegisterReranker(id, reranker.FromCompleter(id, completer))
 
@@ -176,6 +197,8 @@ func (cfg *Config) registerProviders(f *configFile) error {
 			default:
 				return errors.New("invalid model type: " + id)
 			}
+
+			cfg.describeModel(id, m)
 		}
 	}
 
@@ -198,6 +221,10 @@ type providerConfig struct {
 	MaxRetries          *int  `yaml:"max_retries"`
 	ReasoningSignatures *bool `yaml:"reasoning_signatures"`
 
+	// SchemaRetries enables validation of strict structured output and is
+	// the number of repair attempts for output that does not match
+	SchemaRetries *int `yaml:"schema_retries"`
+
 	Models yaml.Node `yaml:"models"`
 }
 
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/provider/custom"
 )
 
 func (cfg *Config) RegisterReranker(id string, p provider.Reranker) {
@@ -33,8 +34,16 @@ func (cfg *Config) Reranker(id string) (provider.Reranker, error) {
 
 func createReranker(cfg providerConfig, model modelContext) (provider.Reranker, error) {
 	switch strings.ToLower(cfg.Type) {
+	case "custom":
+		return customReranker(cfg, model)
 
 	default:
 		return nil, errors.New("invalid reranker type: " + cfg.Type)
 	}
 }
+
+func customReranker(cfg providerConfig, model modelContext) (provider.Reranker, error) {
+	var options []custom.Option
+
+	return custom.NewReranker(cfg.URL, model.ID, options...)
+}
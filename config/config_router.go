@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,13 +13,51 @@ import (
 	"github.com/adrianliechti/wingman/pkg/provider/adapter/signatures"
 	"github.com/adrianliechti/wingman/pkg/router"
 	"github.com/adrianliechti/wingman/pkg/router/adaptive"
+	"github.com/adrianliechti/wingman/pkg/router/budget"
 	"github.com/adrianliechti/wingman/pkg/router/classifier"
 	"github.com/adrianliechti/wingman/pkg/router/roundrobin"
 )
 
+// RegisterClassifier keeps a classifier router reachable for routing feedback
+func (cfg *Config) RegisterClassifier(id string, c *classifier.Completer) {
+	if cfg.classifiers == nil {
+		cfg.classifiers = make(map[string]*classifier.Completer)
+	}
+
+	cfg.classifiers[id] = c
+}
+
+func (cfg *Config) Classifiers() []string {
+	var result []string
+
+	for id := range cfg.classifiers {
+		result = append(result, id)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func (cfg *Config) Classifier(id string) (*classifier.Completer, error) {
+	if cfg.classifiers != nil {
+		if c, ok := cfg.classifiers[id]; ok {
+			return c, nil
+		}
+	}
+
+	return nil, errors.New("classifier not found: " + id)
+}
+
 type routerConfig struct {
 	Type string `yaml:"type"`
 
+	// ModelType is the kind of model balanced by a roundrobin or adaptive
+	// router (completer, embedder, reranker, renderer, synthesizer,
+	// transcriber). Detected from the first model when omitted; rerankers
+	// must be set explicitly.
+	ModelType ModelType `yaml:"model_type"`
+
 	Models   []string `yaml:"models"`
 	Fallback string   `yaml:"fallback"`
 
@@ -38,6 +78,21 @@ type routerConfig struct {
 	// probe request (e.g. "1m"). Defaults to 30s
 	RecoveryTimeout string `yaml:"recovery_timeout"`
 
+	// SLO is the target time to first token for type "budget" (e.g. "2s").
+	SLO string `yaml:"slo"`
+
+	// MaxRequestCost is the highest estimated cost in USD a single request
+	// may incur on a "budget" router.
+	MaxRequestCost float64 `yaml:"max_request_cost"`
+
+	// HourlyBudget is the spend in USD over the trailing hour above which a
+	// "budget" router shifts traffic to its cheapest models.
+	HourlyBudget float64 `yaml:"hourly_budget"`
+
+	// BudgetThreshold is the fraction of HourlyBudget at which the shift
+	// starts (default 0.8).
+	BudgetThreshold float64 `yaml:"budget_threshold"`
+
 	// Candidates lists the per-task routing options for type "classifier".
 	Candidates []routerCandidateConfig `yaml:"candidates"`
 
@@ -59,6 +114,13 @@ type routerConfig struct {
 	// "model" elsewhere). The classifier uses it as the optional LLM-as-judge
 	// tier; omit to keep it off (the default).
 	Completer string `yaml:"completer"`
+
+	// State is a local JSON file persisting what the classifier learned from
+	// feedback (difficulty thresholds, exemplar centroids, accuracy). Omit to
+	// keep it in memory only.
+	State string `yaml:"state"`
+
+	limitConfig `yaml:",inline"`
 }
 
 // routerCandidateConfig describes one classifier candidate. Model is a completer
@@ -76,11 +138,6 @@ type routerCandidateConfig struct {
 	Examples []string `yaml:"examples"`
 }
 
-type routerContext struct {
-	Completers []provider.Completer
-	Fallback   provider.Completer
-}
-
 func (cfg *Config) registerRouters(f *configFile) error {
 	var configs map[string]routerConfig
 
@@ -101,39 +158,17 @@ func (cfg *Config) registerRouters(f *configFile) error {
 			continue
 		}
 
-		context := routerContext{}
-
-		for _, m := range config.Models {
-			completer, err := cfg.Completer(m)
-
-			if err != nil {
+		if strings.ToLower(config.Type) == "budget" {
+			if err := cfg.registerBudget(id, config); err != nil {
 				return err
 			}
 
-			context.Completers = append(context.Completers, completer)
-		}
-
-		if config.Fallback != "" {
-			fallback, err := cfg.Completer(config.Fallback)
-
-			if err != nil {
-				return err
-			}
-
-			context.Fallback = fallback
+			continue
 		}
 
-		completer, err := createRouter(config, context)
-
-		if err != nil {
+		if err := cfg.registerRouter(id, config); err != nil {
 			return err
 		}
-
-		if config.ReasoningSignatures != nil && !*config.ReasoningSignatures {
-			completer = signatures.FromCompleter(completer)
-		}
-
-		cfg.RegisterCompleter(id, otel.NewCompleterSpan("router "+id, completer))
 	}
 
 	// Classifiers register last, so their candidates can reference sibling
@@ -148,7 +183,15 @@ func (cfg *Config) registerRouters(f *configFile) error {
 			continue
 		}
 
-		completer, err := cfg.createClassifier(config)
+		classifier, err := cfg.createClassifier(config)
+
+		if err != nil {
+			return err
+		}
+
+		cfg.RegisterClassifier(id, classifier)
+
+		completer, err := limitCompleter(id, config.limitConfig, classifier)
 
 		if err != nil {
 			return err
@@ -164,7 +207,36 @@ func (cfg *Config) registerRouters(f *configFile) error {
 	return nil
 }
 
-func (cfg *Config) createClassifier(config routerConfig) (provider.Completer, error) {
+// contextWindows resolves the declared context window of each routed model.
+// Prompts are estimated with the provider model behind an id, as the id may
+// be an alias. Routed models without a max_context (e.g. nested routers) stay
+// unknown.
+func (cfg *Config) contextWindows(models []string) []router.ContextWindow {
+	windows := make([]router.ContextWindow, len(models))
+
+	var known bool
+
+	for i, id := range models {
+		windows[i].Model = id
+
+		if upstream, ok := cfg.upstreams[id]; ok {
+			windows[i].Model = upstream
+		}
+
+		if m, err := cfg.Model(id); err == nil && m.MaxContext > 0 {
+			windows[i].Tokens = m.MaxContext
+			known = true
+		}
+	}
+
+	if !known {
+		return nil
+	}
+
+	return windows
+}
+
+func (cfg *Config) createClassifier(config routerConfig) (*classifier.Completer, error) {
 	if len(config.Candidates) == 0 {
 		return nil, errors.New("classifier router requires candidates")
 	}
@@ -185,6 +257,12 @@ func (cfg *Config) createClassifier(config routerConfig) (provider.Completer, er
 			return nil, err
 		}
 
+		maxContext := cc.MaxContext
+
+		if m, err := cfg.Model(cc.Model); err == nil && maxContext == 0 {
+			maxContext = m.MaxContext
+		}
+
 		candidates = append(candidates, classifier.Candidate{
 			Completer: completer,
 
@@ -194,7 +272,7 @@ func (cfg *Config) createClassifier(config routerConfig) (provider.Completer, er
 			Cost:          cc.Cost,
 			MaxDifficulty: cc.MaxDifficulty,
 			Vision:        cc.Vision,
-			MaxContext:    cc.MaxContext,
+			MaxContext:    maxContext,
 
 			Examples: cc.Examples,
 		})
@@ -212,6 +290,8 @@ func (cfg *Config) createClassifier(config routerConfig) (provider.Completer, er
 	options := classifier.Options{
 		Margin:       config.Margin,
 		DefaultIndex: defaultIndex,
+
+		StatePath: config.State,
 	}
 
 	if config.Embedder != "" {
@@ -237,32 +317,397 @@ func (cfg *Config) createClassifier(config routerConfig) (provider.Completer, er
 	return classifier.NewCompleter(candidates, options)
 }
 
-func createRouter(cfg routerConfig, context routerContext) (provider.Completer, error) {
-	options, err := routerOptions(cfg, context)
+// registerRouter builds a load-balancing router over models of a single type
+// and registers it under its id.
+func (cfg *Config) registerRouter(id string, config routerConfig) error {
+	strategy, err := routerStrategy(config.Type)
+
+	if err != nil {
+		return err
+	}
+
+	options, err := routerOptions(config)
+
+	if err != nil {
+		return err
+	}
+
+	modelType := config.ModelType
+
+	if modelType == ModelTypeAuto {
+		modelType = cfg.routerModelType(config.Models)
+	}
+
+	if modelType != ModelTypeCompleter {
+		if config.limitConfig != (limitConfig{}) {
+			return errors.New("max_concurrency is only supported for completer routers")
+		}
+
+		if config.ReasoningSignatures != nil {
+			return errors.New("reasoning_signatures is only supported for completer routers")
+		}
+	}
+
+	switch modelType {
+	case ModelTypeCompleter:
+		completers, err := resolveModels(config.Models, cfg.Completer)
+
+		if err != nil {
+			return err
+		}
+
+		options, err = appendFallback(options, config.Fallback, cfg.Completer)
+
+		if err != nil {
+			return err
+		}
+
+		if windows := cfg.contextWindows(config.Models); windows != nil {
+			options = append(options, router.WithContextWindows(windows))
+		}
+
+		var completer provider.Completer
+
+		completer, err = router.NewCompleter(completers, strategy, options...)
+
+		if err != nil {
+			return err
+		}
+
+		completer, err = limitCompleter(id, config.limitConfig, completer)
+
+		if err != nil {
+			return err
+		}
+
+		if config.ReasoningSignatures != nil && !*config.ReasoningSignatures {
+			completer = signatures.FromCompleter(completer)
+		}
+
+		cfg.RegisterCompleter(id, otel.NewCompleterSpan("router "+id, completer))
+
+	case ModelTypeEmbedder:
+		embedders, err := resolveModels(config.Models, cfg.Embedder)
+
+		if err != nil {
+			return err
+		}
+
+		dimensions, err := cfg.routerDimensions(append(slices.Clone(config.Models), config.Fallback))
+
+		if err != nil {
+			return err
+		}
+
+		if dimensions > 0 {
+			options = append(options, router.WithDimensions(dimensions))
+		}
+
+		options, err = appendFallback(options, config.Fallback, cfg.Embedder)
+
+		if err != nil {
+			return err
+		}
+
+		embedder, err := router.NewEmbedder(embedders, strategy, options...)
+
+		if err != nil {
+			return err
+		}
+
+		cfg.RegisterEmbedder(id, embedder)
+
+	case ModelTypeReranker:
+		rerankers, err := resolveModels(config.Models, cfg.Reranker)
+
+		if err != nil {
+			return err
+		}
+
+		options, err = appendFallback(options, config.Fallback, cfg.Reranker)
+
+		if err != nil {
+			return err
+		}
+
+		reranker, err := router.NewReranker(rerankers, strategy, options...)
+
+		if err != nil {
+			return err
+		}
+
+		cfg.RegisterReranker(id, reranker)
+
+	case ModelTypeRenderer:
+		renderers, err := resolveModels(config.Models, cfg.Renderer)
+
+		if err != nil {
+			return err
+		}
+
+		options, err = appendFallback(options, config.Fallback, cfg.Renderer)
+
+		if err != nil {
+			return err
+		}
+
+		renderer, err := router.NewRenderer(renderers, strategy, options...)
+
+		if err != nil {
+			return err
+		}
+
+		cfg.RegisterRenderer(id, renderer)
+
+	case ModelTypeSynthesizer:
+		synthesizers, err := resolveModels(config.Models, cfg.Synthesizer)
+
+		if err != nil {
+			return err
+		}
+
+		options, err = appendFallback(options, config.Fallback, cfg.Synthesizer)
+
+		if err != nil {
+			return err
+		}
+
+		synthesizer, err := router.NewSynthesizer(synthesizers, strategy, options...)
+
+		if err != nil {
+			return err
+		}
+
+		cfg.RegisterSynthesizer(id, synthesizer)
+
+	case ModelTypeTranscriber:
+		transcribers, err := resolveModels(config.Models, cfg.Transcriber)
+
+		if err != nil {
+			return err
+		}
+
+		options, err = appendFallback(options, config.Fallback, cfg.Transcriber)
+
+		if err != nil {
+			return err
+		}
+
+		transcriber, err := router.NewTranscriber(transcribers, strategy, options...)
+
+		if err != nil {
+			return err
+		}
+
+		cfg.RegisterTranscriber(id, transcriber)
+
+	default:
+		return errors.New("invalid router model type: " + string(modelType))
+	}
+
+	return nil
+}
+
+// registerBudget builds a cost- and SLO-aware router over priced completers
+func (cfg *Config) registerBudget(id string, config routerConfig) error {
+	if config.ModelType != ModelTypeAuto && config.ModelType != ModelTypeCompleter {
+		return errors.New("budget routers only support completers")
+	}
+
+	options, err := routerOptions(config)
+
+	if err != nil {
+		return err
+	}
+
+	var members []budget.Member
+
+	for _, model := range config.Models {
+		completer, err := cfg.Completer(model)
+
+		if err != nil {
+			return err
+		}
+
+		m, err := cfg.Model(model)
+
+		if err != nil || m.Pricing == nil {
+			return errors.New("budget router model requires pricing: " + model)
+		}
+
+		members = append(members, budget.Member{
+			Completer: completer,
+
+			Model:   model,
+			Pricing: *m.Pricing,
+		})
+	}
+
+	if windows := cfg.contextWindows(config.Models); windows != nil {
+		options = append(options, router.WithContextWindows(windows))
+	}
+
+	budgetOptions := budget.Options{
+		MaxRequestCost: config.MaxRequestCost,
+		HourlyBudget:   config.HourlyBudget,
+		Threshold:      config.BudgetThreshold,
+	}
+
+	// The fallback is priced like the members, so its cost can be held
+	// against the request limit
+	if config.Fallback != "" {
+		completer, err := cfg.Completer(config.Fallback)
+
+		if err != nil {
+			return err
+		}
+
+		fallback := &budget.Member{
+			Completer: completer,
+
+			Model: config.Fallback,
+		}
+
+		if m, err := cfg.Model(config.Fallback); err == nil && m.Pricing != nil {
+			fallback.Pricing = *m.Pricing
+		} else if config.MaxRequestCost > 0 {
+			return errors.New("budget router fallback requires pricing with max_request_cost: " + config.Fallback)
+		}
+
+		budgetOptions.Fallback = fallback
+	}
+
+	if config.SLO != "" {
+		slo, err := parseTimeout("slo", config.SLO)
+
+		if err != nil {
+			return err
+		}
+
+		budgetOptions.SLO = slo
+	}
+
+	var completer provider.Completer
+
+	completer, err = budget.NewCompleter(members, budgetOptions, options...)
+
+	if err != nil {
+		return err
+	}
+
+	completer, err = limitCompleter(id, config.limitConfig, completer)
+
+	if err != nil {
+		return err
+	}
+
+	if config.ReasoningSignatures != nil && !*config.ReasoningSignatures {
+		completer = signatures.FromCompleter(completer)
+	}
+
+	cfg.RegisterCompleter(id, otel.NewCompleterSpan("router "+id, completer))
+
+	return nil
+}
+
+// routerModelType detects the type of the routed models from the first
+// member. Rerankers are never detected, as every completer and embedder also
+// serves as a reranker: they require an explicit model_type.
+func (cfg *Config) routerModelType(models []string) ModelType {
+	if len(models) == 0 {
+		return ModelTypeCompleter
+	}
+
+	id := models[0]
+
+	if _, ok := cfg.embedder[id]; ok {
+		return ModelTypeEmbedder
+	}
+
+	if _, ok := cfg.renderer[id]; ok {
+		return ModelTypeRenderer
+	}
+
+	if _, ok := cfg.synthesizer[id]; ok {
+		return ModelTypeSynthesizer
+	}
+
+	if _, ok := cfg.transcriber[id]; ok {
+		return ModelTypeTranscriber
+	}
+
+	return ModelTypeCompleter
+}
+
+// routerDimensions returns the dimensions the embedders declare, and rejects
+// embedders of different ones, which would mix vector spaces behind a single
+// router id
+func (cfg *Config) routerDimensions(models []string) (int, error) {
+	var dimensions int
+
+	for _, id := range models {
+		m, err := cfg.Model(id)
+
+		if err != nil || m.Dimensions == 0 {
+			continue
+		}
+
+		if dimensions != 0 && m.Dimensions != dimensions {
+			return 0, fmt.Errorf("router embedders have different dimensions: %s has %d, expected %d", id, m.Dimensions, dimensions)
+		}
+
+		dimensions = m.Dimensions
+	}
+
+	return dimensions, nil
+}
+
+func resolveModels[P any](ids []string, resolve func(string) (P, error)) ([]P, error) {
+	var result []P
+
+	for _, id := range ids {
+		p, err := resolve(id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+func appendFallback[P any](options []router.Option, id string, resolve func(string) (P, error)) ([]router.Option, error) {
+	if id == "" {
+		return options, nil
+	}
+
+	fallback, err := resolve(id)
 
 	if err != nil {
 		return nil, err
 	}
 
-	switch strings.ToLower(cfg.Type) {
+	return append(options, router.WithFallback(fallback)), nil
+}
+
+func routerStrategy(t string) (router.Strategy, error) {
+	switch strings.ToLower(t) {
 	case "roundrobin":
-		return roundrobin.NewCompleter(context.Completers, options...)
+		return roundrobin.NewStrategy(), nil
 
 	case "adaptive":
-		return adaptive.NewCompleter(context.Completers, options...)
+		return adaptive.NewStrategy(), nil
 
 	default:
-		return nil, errors.New("invalid router type: " + cfg.Type)
+		return nil, errors.New("invalid router type: " + t)
 	}
 }
 
-func routerOptions(cfg routerConfig, context routerContext) ([]router.Option, error) {
+func routerOptions(cfg routerConfig) ([]router.Option, error) {
 	var options []router.Option
 
-	if context.Fallback != nil {
-		options = append(options, router.WithFallback(context.Fallback))
-	}
-
 	if cfg.FirstTokenTimeout != "" {
 		timeout, err := parseTimeout("first_token_timeout", cfg.FirstTokenTimeout)
 
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/router"
+)
+
+func TestContextWindows(t *testing.T) {
+	cfg := &Config{}
+
+	cfg.RegisterModel("fast")
+	cfg.describeModel("fast", modelConfig{ID: "claude-haiku-4-5", MaxContext: 200000})
+
+	cfg.RegisterModel("nested")
+
+	got := cfg.contextWindows([]string{"fast", "nested"})
+
+	want := []router.ContextWindow{
+		{Model: "claude-haiku-4-5", Tokens: 200000},
+		{Model: "nested"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("window %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
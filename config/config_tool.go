@@ -1,25 +1,39 @@
 package config
 
 import (
+	"context"
 	"errors"
+	"io"
+	"iter"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/adrianliechti/wingman/pkg/tool"
 	"github.com/adrianliechti/wingman/pkg/tool/custom"
+	"github.com/adrianliechti/wingman/pkg/tool/delegate"
+	"github.com/adrianliechti/wingman/pkg/tool/editor"
 	"github.com/adrianliechti/wingman/pkg/tool/mcp"
+	"github.com/adrianliechti/wingman/pkg/tool/openapi"
+	"github.com/adrianliechti/wingman/pkg/tool/postprocess"
 	"github.com/adrianliechti/wingman/pkg/tool/research"
+	"github.com/adrianliechti/wingman/pkg/tool/sandbox"
 	"github.com/adrianliechti/wingman/pkg/tool/scrape"
 	"github.com/adrianliechti/wingman/pkg/tool/search"
 	"github.com/adrianliechti/wingman/pkg/tool/translate"
 
 	"github.com/adrianliechti/wingman/pkg/extractor"
+	"github.com/adrianliechti/wingman/pkg/policy"
 	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/researcher"
 	"github.com/adrianliechti/wingman/pkg/scraper"
 	"github.com/adrianliechti/wingman/pkg/searcher"
 	"github.com/adrianliechti/wingman/pkg/translator"
+	"github.com/adrianliechti/wingman/pkg/workspace"
 
 	"github.com/adrianliechti/wingman/pkg/otel"
+
+	"go.yaml.in/yaml/v4"
 )
 
 func (cfg *Config) RegisterTool(id string, p tool.Provider) {
@@ -57,6 +71,11 @@ type toolConfig struct {
 
 	URL string `yaml:"url"`
 
+	// Command runs a stdio MCP server instead of connecting to URL
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	Env     map[string]string `yaml:"env"`
+
 	Vars  map[string]string `yaml:"vars"`
 	Auth  *authConfig       `yaml:"auth"`
 	Proxy *proxyConfig      `yaml:"proxy"`
@@ -69,9 +88,79 @@ type toolConfig struct {
 	Scraper    string `yaml:"scraper"`
 	Searcher   string `yaml:"searcher"`
 	Researcher string `yaml:"researcher"`
+
+	// Agent is the agent an agent tool delegates to
+	Agent string `yaml:"agent"`
+
+	Description string `yaml:"description"`
+
+	// MaxDepth limits how deep agents may delegate through agent tools
+	MaxDepth int `yaml:"max_depth"`
+
+	// Server overrides the server URL of an OpenAPI document
+	Server string `yaml:"server"`
+
+	// Operations allowlists the operations of an OpenAPI document by
+	// operationId or tool name
+	Operations []string `yaml:"operations"`
+
+	// MaxResponseSize truncates OpenAPI responses beyond this many bytes
+	MaxResponseSize int `yaml:"max_response_size"`
+
+	Sandbox *toolSandboxConfig `yaml:"sandbox"`
+
+	// Workspace is the workspaces entry a text editor keeps its files in
+	Workspace string `yaml:"workspace"`
+
+	// Dialect offers a text editor as text_editor (default) or apply_patch
+	Dialect string `yaml:"dialect"`
+
+	Result *toolResultConfig `yaml:"result"`
+}
+
+type toolSandboxConfig struct {
+	// Workspace is the directory the session workspaces are kept in
+	Workspace string `yaml:"workspace"`
+
+	Timeout string `yaml:"timeout"`
+	CPUTime string `yaml:"cpu_time"`
+
+	// Memory limits the virtual memory of each process in MiB
+	Memory int `yaml:"memory"`
+
+	// Processes limits the processes a command runs at once
+	Processes int `yaml:"processes"`
+
+	// FileSize limits the size of each file a command writes in MiB
+	FileSize int `yaml:"file_size"`
+
+	Network bool `yaml:"network"`
+
+	MaxOutput int `yaml:"max_output"`
+
+	// IdleTimeout is how long unused workspaces are kept
+	IdleTimeout string `yaml:"idle_timeout"`
+
+	// CodeInterpreter serves the code_interpreter tool of the Responses API
+	CodeInterpreter bool `yaml:"code_interpreter"`
+}
+
+type toolResultConfig struct {
+	MaxTokens     int `yaml:"max_tokens"`
+	MaxCharacters int `yaml:"max_characters"`
+
+	// Summarizer condenses oversized results instead of truncating them
+	Summarizer string `yaml:"summarizer"`
+
+	// Cache reuses results of calls with the same arguments for this long
+	Cache string `yaml:"cache"`
 }
 
 type toolContext struct {
+	Client *http.Client
+
+	Agent provider.Completer
+
 	Extractor  extractor.Provider
 	Translator translator.Provider
 
@@ -81,6 +170,8 @@ type toolContext struct {
 	Scraper    scraper.Provider
 	Searcher   searcher.Provider
 	Researcher researcher.Provider
+
+	Workspace workspace.Provider
 }
 
 func (cfg *Config) registerTools(f *configFile) error {
@@ -90,6 +181,8 @@ func (cfg *Config) registerTools(f *configFile) error {
 		return err
 	}
 
+	cfg.prompters = make(map[string]prompter)
+
 	for _, node := range f.Tools.Content {
 		id := node.Value
 
@@ -101,6 +194,26 @@ func (cfg *Config) registerTools(f *configFile) error {
 
 		context := toolContext{}
 
+		if config.Proxy != nil {
+			client, err := config.Proxy.proxyClient()
+
+			if err != nil {
+				return err
+			}
+
+			context.Client = client
+		}
+
+		if config.Agent != "" {
+			if !hasKey(&f.Agents, config.Agent) {
+				return errors.New("agent not found: " + config.Agent)
+			}
+
+			// Agents are registered after the tools they use and may
+			// delegate to each other, so the agent is looked up on use
+			context.Agent = &agentRef{cfg: cfg, id: config.Agent}
+		}
+
 		if p, err := cfg.Extractor(config.Extractor); err == nil {
 			context.Extractor = p
 		}
@@ -129,22 +242,93 @@ func (cfg *Config) registerTools(f *configFile) error {
 			context.Researcher = p
 		}
 
+		if config.Workspace != "" {
+			p, err := cfg.Workspace(config.Workspace)
+
+			if err != nil {
+				return err
+			}
+
+			context.Workspace = p
+		}
+
 		tool, err := createTool(config, context)
 
 		if err != nil {
 			return err
 		}
 
+		if c, ok := tool.(io.Closer); ok {
+			cfg.closers = append(cfg.closers, c)
+		}
+
+		if p, ok := tool.(prompter); ok {
+			cfg.prompters[id] = p
+		}
+
 		if _, ok := tool.(otel.Tool); !ok {
 			tool = otel.NewTool(config.Type, tool)
 		}
 
+		if config.Result != nil {
+			options, err := cfg.toolResultOptions(config.Result)
+
+			if err != nil {
+				return err
+			}
+
+			tool = postprocess.New(tool, options...)
+		}
+
+		tool = policy.NewTool(cfg.Policy, id, tool)
+
+		if config.Sandbox != nil && config.Sandbox.CodeInterpreter {
+			if cfg.CodeInterpreter != nil {
+				return errors.New("code_interpreter is enabled on more than one sandbox tool")
+			}
+
+			cfg.CodeInterpreter = tool
+		}
+
 		cfg.RegisterTool(id, tool)
 	}
 
 	return nil
 }
 
+func (cfg *Config) toolResultOptions(c *toolResultConfig) ([]postprocess.Option, error) {
+	if c.MaxTokens < 0 || c.MaxCharacters < 0 {
+		return nil, errors.New("invalid result limits: must not be negative")
+	}
+
+	options := []postprocess.Option{
+		postprocess.WithMaxTokens(c.MaxTokens),
+		postprocess.WithMaxCharacters(c.MaxCharacters),
+	}
+
+	if c.Summarizer != "" {
+		p, err := cfg.Summarizer(c.Summarizer)
+
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, postprocess.WithSummarizer(p))
+	}
+
+	if c.Cache != "" {
+		ttl, err := parseTimeout("cache", c.Cache)
+
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, postprocess.WithCache(ttl))
+	}
+
+	return options, nil
+}
+
 func createTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
 	switch strings.ToLower(cfg.Type) {
 
@@ -163,9 +347,21 @@ func createTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
 	case "mcp":
 		return mcpTool(cfg, context)
 
+	case "openapi":
+		return openapiTool(cfg, context)
+
+	case "sandbox":
+		return sandboxTool(cfg, context)
+
+	case "text_editor", "editor":
+		return editorTool(cfg, context)
+
 	case "custom":
 		return customTool(cfg, context)
 
+	case "agent":
+		return agentTool(cfg, context)
+
 	default:
 		return nil, errors.New("invalid tool type: " + cfg.Type)
 	}
@@ -195,7 +391,29 @@ func translatorTool(cfg toolConfig, context toolContext) (tool.Provider, error)
 	return translate.New(context.Translator, options...)
 }
 
+func editorTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
+	if context.Workspace == nil {
+		return nil, errors.New("text_editor tool requires a workspace")
+	}
+
+	var options []editor.Option
+
+	if cfg.Dialect != "" {
+		options = append(options, editor.WithDialect(cfg.Dialect))
+	}
+
+	return editor.New(context.Workspace, options...)
+}
+
 func mcpTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
+	if cfg.Command != "" {
+		if cfg.URL != "" {
+			return nil, errors.New("mcp tool takes either a url or a command")
+		}
+
+		return mcp.NewCommand(cfg.Command, cfg.Args, cfg.Env)
+	}
+
 	exchanger, err := createClientAuth(cfg.Auth)
 
 	if err != nil {
@@ -205,8 +423,156 @@ func mcpTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
 	return mcp.New(cfg.URL, cfg.Vars, exchanger)
 }
 
+func openapiTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("openapi tool requires a url")
+	}
+
+	if cfg.MaxResponseSize < 0 {
+		return nil, errors.New("invalid max_response_size: must not be negative")
+	}
+
+	exchanger, err := createClientAuth(cfg.Auth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	options := []openapi.Option{
+		openapi.WithHeaders(cfg.Vars),
+		openapi.WithTokenExchanger(exchanger),
+	}
+
+	if context.Client != nil {
+		options = append(options, openapi.WithClient(context.Client))
+	}
+
+	if cfg.Server != "" {
+		options = append(options, openapi.WithServer(cfg.Server))
+	}
+
+	if len(cfg.Operations) > 0 {
+		options = append(options, openapi.WithOperations(cfg.Operations...))
+	}
+
+	if cfg.MaxResponseSize > 0 {
+		options = append(options, openapi.WithMaxResponseSize(cfg.MaxResponseSize))
+	}
+
+	return openapi.New(cfg.URL, options...)
+}
+
+func sandboxTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
+	c := cfg.Sandbox
+
+	if c == nil {
+		c = &toolSandboxConfig{}
+	}
+
+	if c.Memory < 0 || c.Processes < 0 || c.FileSize < 0 || c.MaxOutput < 0 {
+		return nil, errors.New("invalid sandbox limits: must not be negative")
+	}
+
+	var options []sandbox.Option
+
+	for _, d := range []struct {
+		name  string
+		value string
+
+		option func(time.Duration) sandbox.Option
+	}{
+		{"timeout", c.Timeout, sandbox.WithTimeout},
+		{"cpu_time", c.CPUTime, sandbox.WithCPUTime},
+		{"idle_timeout", c.IdleTimeout, sandbox.WithIdleTimeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+
+		value, err := parseTimeout(d.name, d.value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, d.option(value))
+	}
+
+	if c.Memory > 0 {
+		options = append(options, sandbox.WithMemory(int64(c.Memory)<<20))
+	}
+
+	if c.Processes > 0 {
+		options = append(options, sandbox.WithProcesses(c.Processes))
+	}
+
+	if c.FileSize > 0 {
+		options = append(options, sandbox.WithFileSize(int64(c.FileSize)<<20))
+	}
+
+	if c.Network {
+		options = append(options, sandbox.WithNetwork())
+	}
+
+	if c.MaxOutput > 0 {
+		options = append(options, sandbox.WithMaxOutput(c.MaxOutput))
+	}
+
+	return sandbox.New(c.Workspace, options...)
+}
+
 func customTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
 	var options []custom.Option
 
 	return custom.New(cfg.URL, options...)
 }
+
+func agentTool(cfg toolConfig, context toolContext) (tool.Provider, error) {
+	if context.Agent == nil {
+		return nil, errors.New("agent tool requires an agent")
+	}
+
+	if cfg.MaxDepth < 0 {
+		return nil, errors.New("invalid max_depth: must not be negative")
+	}
+
+	var options []delegate.Option
+
+	if cfg.Description != "" {
+		options = append(options, delegate.WithDescription(cfg.Description))
+	}
+
+	if cfg.MaxDepth > 0 {
+		options = append(options, delegate.WithMaxDepth(cfg.MaxDepth))
+	}
+
+	return delegate.New(cfg.Agent, context.Agent, options...)
+}
+
+// agentRef resolves a configured agent when it is first called
+type agentRef struct {
+	cfg *Config
+	id  string
+}
+
+func (r *agentRef) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	p, ok := r.cfg.agents[r.id]
+
+	if !ok {
+		return func(yield func(*provider.Completion, error) bool) {
+			yield(nil, errors.New("agent not found: "+r.id))
+		}
+	}
+
+	return p.Complete(ctx, messages, options)
+}
+
+func hasKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+
+	return false
+}
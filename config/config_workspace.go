@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/workspace"
+	"github.com/adrianliechti/wingman/pkg/workspace/dir"
+	"github.com/adrianliechti/wingman/pkg/workspace/mem"
+)
+
+func (cfg *Config) RegisterWorkspace(id string, p workspace.Provider) {
+	if cfg.workspace == nil {
+		cfg.workspace = make(map[string]workspace.Provider)
+	}
+
+	cfg.workspace[id] = p
+}
+
+func (cfg *Config) Workspace(id string) (workspace.Provider, error) {
+	if cfg.workspace != nil {
+		if p, ok := cfg.workspace[id]; ok {
+			return p, nil
+		}
+	}
+
+	return nil, errors.New("workspace not found: " + id)
+}
+
+// Workspaces returns the ids of the configured workspace stores
+func (cfg *Config) Workspaces() []string {
+	var ids []string
+
+	for id := range cfg.workspace {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
+type workspaceConfig struct {
+	Type string `yaml:"type"`
+
+	Path string `yaml:"path"`
+
+	// IdleTimeout is how long unused workspaces are kept
+	IdleTimeout string `yaml:"idle_timeout"`
+
+	// MaxSize limits the files of a workspace in MiB
+	MaxSize int `yaml:"max_size"`
+}
+
+func (cfg *Config) registerWorkspaces(f *configFile) error {
+	var configs map[string]workspaceConfig
+
+	if err := decodeStrict(&f.Workspaces, &configs); err != nil {
+		return err
+	}
+
+	for _, node := range f.Workspaces.Content {
+		id := node.Value
+
+		config, ok := configs[node.Value]
+
+		if !ok {
+			continue
+		}
+
+		workspace, err := createWorkspace(config)
+
+		if err != nil {
+			return err
+		}
+
+		cfg.RegisterWorkspace(id, workspace)
+	}
+
+	return nil
+}
+
+func createWorkspace(cfg workspaceConfig) (workspace.Provider, error) {
+	if cfg.MaxSize < 0 {
+		return nil, errors.New("invalid max_size: must not be negative")
+	}
+
+	var idleTimeout time.Duration
+
+	if cfg.IdleTimeout != "" {
+		timeout, err := parseTimeout("idle_timeout", cfg.IdleTimeout)
+
+		if err != nil {
+			return nil, err
+		}
+
+		idleTimeout = timeout
+	}
+
+	maxSize := int64(cfg.MaxSize) << 20
+
+	switch strings.ToLower(cfg.Type) {
+	case "memory", "":
+		return mem.New(mem.WithIdleTimeout(idleTimeout), mem.WithMaxSize(maxSize)), nil
+
+	case "directory", "dir":
+		return dir.New(cfg.Path, dir.WithIdleTimeout(idleTimeout), dir.WithMaxSize(maxSize))
+
+	default:
+		return nil, errors.New("invalid workspace type: " + cfg.Type)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/adrianliechti/wingman/config"
+	"github.com/adrianliechti/wingman/pkg/eval"
+)
+
+// runEval runs evaluation suites against the agents and models of the
+// configuration and writes a report. It returns 1 when a case failed and 2
+// when the suites could not run.
+func runEval(args []string) int {
+	flags := flag.NewFlagSet("eval", flag.ExitOnError)
+
+	configFlag := flags.String("config", "config.yaml", "configuration path")
+	modelFlag := flags.String("model", "", "agent or model id, overrides the suites' model")
+	judgeFlag := flags.String("judge", "", "model id grading rubrics, overrides the suites' judge")
+	formatFlag := flags.String("format", "json", "report format: json or junit")
+	outputFlag := flags.String("output", "", "report path (default stdout)")
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: wingman eval [flags] suite.yaml...")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if *formatFlag != "json" && *formatFlag != "junit" {
+		fmt.Fprintln(os.Stderr, "invalid format: "+*formatFlag)
+		return 2
+	}
+
+	var suites []*eval.Suite
+
+	for _, path := range flags.Args() {
+		suite, err := eval.Load(path)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		suites = append(suites, suite)
+	}
+
+	cfg, err := config.Parse(*configFlag)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	defer cfg.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	runner := eval.New(cfg.Completer, eval.WithModel(*modelFlag), eval.WithJudge(*judgeFlag))
+
+	var reports []*eval.Report
+
+	failed := false
+
+	for _, suite := range suites {
+		report, err := runner.Run(ctx, suite)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		fmt.Fprintf(os.Stderr, "%s (%s): %d/%d passed (%.0f%%)\n", report.Suite, report.Model, report.Passed, len(report.Cases), report.PassRate*100)
+
+		for _, c := range report.Cases {
+			if c.Passed {
+				continue
+			}
+
+			fmt.Fprintf(os.Stderr, "  FAIL %s\n", c.Name)
+
+			if c.Error != "" {
+				fmt.Fprintf(os.Stderr, "    error: %s\n", c.Error)
+			}
+
+			for _, f := range c.Failures {
+				fmt.Fprintf(os.Stderr, "    %s\n", f)
+			}
+		}
+
+		failed = failed || report.Failed > 0
+		reports = append(reports, report)
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *outputFlag != "" {
+		f, err := os.Create(*outputFlag)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		defer f.Close()
+
+		w = f
+	}
+
+	write := eval.WriteJSON
+
+	if *formatFlag == "junit" {
+		write = eval.WriteJUnit
+	}
+
+	if err := write(w, reports); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if failed {
+		return 1
+	}
+
+	return 0
+}
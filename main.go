@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/adrianliechti/wingman/config"
 	"github.com/adrianliechti/wingman/server"
@@ -11,6 +15,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		os.Exit(runEval(os.Args[2:]))
+	}
+
 	portFlag := flag.Int("port", 8080, "server port")
 	addressFlag := flag.String("address", "", "server address")
 	configFlag := flag.String("config", "config.yaml", "configuration path")
@@ -35,7 +43,17 @@ func main() {
 		panic(err)
 	}
 
-	if err := s.ListenAndServe(); err != nil {
-		panic(err)
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := s.ListenAndServe(); err != nil {
+			panic(err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	// stop child processes such as stdio MCP servers
+	cfg.Close()
 }
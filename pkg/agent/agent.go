@@ -1,9 +1,98 @@
 package agent
 
 import (
+	"context"
+
 	"github.com/adrianliechti/wingman/pkg/provider"
 )
 
 type Agent interface {
 	provider.Completer
 }
+
+type depthKey struct{}
+
+// WithDepth marks the context as belonging to an agent delegated to depth
+// levels below the agent the caller talks to
+func WithDepth(ctx context.Context, depth int) context.Context {
+	return context.WithValue(ctx, depthKey{}, depth)
+}
+
+// Depth returns the delegation depth of the context; 0 outside any
+// delegated agent
+func Depth(ctx context.Context) int {
+	depth, _ := ctx.Value(depthKey{}).(int)
+	return depth
+}
+
+type sessionKey struct{}
+
+// WithSession scopes state that tools keep across calls, such as a sandbox
+// workspace, to the session id
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+// Session returns the session id of the context, or ""
+func Session(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+type ToolStatus string
+
+const (
+	ToolStatusInProgress ToolStatus = "in_progress"
+	ToolStatusCompleted  ToolStatus = "completed"
+	ToolStatusFailed     ToolStatus = "failed"
+)
+
+// ToolProgress is the state of a tool call an agent runs on its own, reported
+// so API clients can follow the agent's work while it happens
+type ToolProgress struct {
+	ID   string
+	Name string
+
+	// Depth is 0 for calls of the agent the client talks to and n for calls
+	// of agents it delegated to, n levels down
+	Depth int
+
+	Status ToolStatus
+
+	Arguments string
+
+	Output string
+	Error  string
+}
+
+// ProgressReporter receives tool progress. It may be called concurrently.
+type ProgressReporter func(ctx context.Context, progress ToolProgress)
+
+type progressKey struct{}
+
+// WithProgressReporter lets agents that stream tool progress report it for
+// the request of ctx
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressKey{}, reporter)
+}
+
+// Progress returns the reporter of the request, or nil
+func Progress(ctx context.Context) ProgressReporter {
+	reporter, _ := ctx.Value(progressKey{}).(ProgressReporter)
+	return reporter
+}
+
+type traceKey struct{}
+
+// WithToolTrace receives every tool call of the agents serving the request
+// of ctx, whether or not they stream progress. It is meant for evaluating
+// agents rather than for clients.
+func WithToolTrace(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, traceKey{}, reporter)
+}
+
+// ToolTrace returns the trace reporter of the request, or nil
+func ToolTrace(ctx context.Context) ProgressReporter {
+	reporter, _ := ctx.Value(traceKey{}).(ProgressReporter)
+	return reporter
+}
@@ -0,0 +1,192 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// stepFailedMarker starts the reply to a step that could not be completed
+const stepFailedMarker = "STEP FAILED:"
+
+var planSchema = &provider.Schema{
+	Name:        "plan",
+	Description: "The steps to carry out, in order",
+
+	Properties: map[string]any{
+		"type": "object",
+
+		"properties": map[string]any{
+			"steps": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":        "string",
+					"description": "a self-contained instruction for one step",
+				},
+			},
+		},
+
+		"required":             []string{"steps"},
+		"additionalProperties": false,
+	},
+}
+
+// plan asks the model for the list of steps. Given the results of earlier
+// steps, the plan is revised: the returned steps replace the remaining ones.
+func (r *run) plan(ctx context.Context, messages []provider.Message, results []stepResult, remaining []string) ([]string, error) {
+	input := slices.Concat(messages, []provider.Message{
+		provider.UserMessage(planPrompt(r.tools, r.maxSteps-len(results), results, remaining)),
+	})
+
+	options := &provider.CompleteOptions{
+		Temperature: r.temperature,
+
+		Schema: planSchema,
+	}
+
+	if r.effort != "" {
+		options.ReasoningOptions = &provider.ReasoningOptions{Effort: r.effort}
+	}
+
+	acc := provider.CompletionAccumulator{}
+
+	for completion, err := range r.completer.Complete(ctx, input, options) {
+		if err != nil {
+			return nil, err
+		}
+
+		acc.Add(*completion)
+	}
+
+	completion := acc.Result()
+
+	addUsage(r.usage, completion.Usage)
+
+	if completion.Message == nil {
+		return nil, errors.New("agent: empty plan")
+	}
+
+	var data struct {
+		Steps []string `json:"steps"`
+	}
+
+	if err := json.Unmarshal([]byte(completion.Message.Text()), &data); err != nil {
+		return nil, fmt.Errorf("agent: invalid plan: %w", err)
+	}
+
+	var steps []string
+
+	for _, s := range data.Steps {
+		if s = strings.TrimSpace(s); s != "" {
+			steps = append(steps, s)
+		}
+	}
+
+	return steps, nil
+}
+
+func planPrompt(tools []provider.Tool, limit int, results []stepResult, remaining []string) string {
+	var b strings.Builder
+
+	if len(tools) > 0 {
+		b.WriteString("<tools>\n")
+
+		for _, t := range tools {
+			fmt.Fprintf(&b, "- %s: %s\n", t.Name, t.Description)
+		}
+
+		b.WriteString("</tools>\n\n")
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(&b, "Break the handling of the conversation above down into at most %d steps. Each step is carried out on its own, with the tools listed, and only sees the results of the steps before it. Do not add a step for writing the final answer; it is written from the results afterwards. Return no steps if the conversation can be answered directly.", limit)
+
+		return b.String()
+	}
+
+	writeResults(&b, results)
+
+	if len(remaining) > 0 {
+		b.WriteString("<remaining>\n")
+		writeSteps(&b, remaining)
+		b.WriteString("</remaining>\n\n")
+	}
+
+	fmt.Fprintf(&b, "The last step failed. Revise the plan: return at most %d steps that are still needed to handle the conversation above, taking the results so far into account. They replace the remaining steps. Do not repeat an approach that already failed. Return no steps if it cannot be handled further or the results suffice for an answer.", limit)
+
+	return b.String()
+}
+
+func stepPrompt(results []stepResult, steps []string) string {
+	var b strings.Builder
+
+	writeResults(&b, results)
+
+	if len(steps) > 1 {
+		b.WriteString("<remaining>\n")
+		writeSteps(&b, steps[1:])
+		b.WriteString("</remaining>\n\n")
+	}
+
+	fmt.Fprintf(&b, "Carry out only this step of the plan to handle the conversation above, using the tools as needed:\n\n%s\n\nReply with the outcome of the step, including the facts, figures and identifiers found; later steps only see this reply. If the step cannot be completed, start the reply with %q followed by the reason.", steps[0], stepFailedMarker)
+
+	return b.String()
+}
+
+func synthesisPrompt(results []stepResult) string {
+	var b strings.Builder
+
+	writeResults(&b, results)
+
+	b.WriteString("Using the results above, write the reply to the last message of the conversation. Do not mention the plan or its steps. If a part could not be handled, say so.")
+
+	return b.String()
+}
+
+func writeResults(b *strings.Builder, results []stepResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	b.WriteString("<results>\n")
+
+	for i, r := range results {
+		status := "completed"
+
+		if r.Failed {
+			status = "failed"
+		}
+
+		fmt.Fprintf(b, "Step %d (%s): %s\n%s\n\n", i+1, status, r.Step, r.Output)
+	}
+
+	b.WriteString("</results>\n\n")
+}
+
+func writeSteps(b *strings.Builder, steps []string) {
+	for i, s := range steps {
+		fmt.Fprintf(b, "%d. %s\n", i+1, s)
+	}
+}
+
+// formatPlan renders steps for the reasoning summary
+func formatPlan(title string, steps []string) string {
+	var b strings.Builder
+
+	b.WriteString(title)
+	b.WriteString(":\n")
+
+	if len(steps) == 0 {
+		b.WriteString("No steps needed\n")
+	}
+
+	writeSteps(&b, steps)
+	b.WriteString("\n")
+
+	return b.String()
+}
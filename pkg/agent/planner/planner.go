@@ -0,0 +1,483 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/agent/react"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/template"
+	"github.com/adrianliechti/wingman/pkg/tool"
+
+	"github.com/google/uuid"
+)
+
+var _ agent.Agent = &Agent{}
+
+// Agent plans before it acts: the model first writes an explicit list of
+// steps, each step is then carried out with the agent's tools, the plan is
+// revised when a step fails, and the answer is written from the results.
+// The plan and the step status are streamed as reasoning summary.
+type Agent struct {
+	model string
+
+	completer provider.Completer
+
+	tools    []tool.Provider
+	messages []provider.Message
+
+	effort    provider.Effort
+	verbosity provider.Verbosity
+
+	temperature *float32
+
+	// maxSteps bounds the number of steps run per request, revised plans
+	// included
+	maxSteps int
+
+	// maxRevisions bounds how often the plan is revised after failed steps
+	maxRevisions int
+
+	// stepOptions apply to the react agent running the steps
+	stepOptions []react.Option
+
+	executor *react.Agent
+}
+
+const (
+	defaultMaxSteps     = 10
+	defaultMaxRevisions = 2
+)
+
+type Option func(*Agent)
+
+func New(model string, options ...Option) (*Agent, error) {
+	a := &Agent{
+		model: model,
+
+		maxSteps:     defaultMaxSteps,
+		maxRevisions: defaultMaxRevisions,
+	}
+
+	for _, option := range options {
+		option(a)
+	}
+
+	if a.completer == nil {
+		return nil, errors.New("missing completer provider")
+	}
+
+	if a.maxSteps <= 0 {
+		return nil, errors.New("invalid max steps: must be positive")
+	}
+
+	if a.maxRevisions < 0 {
+		return nil, errors.New("invalid max revisions: must not be negative")
+	}
+
+	executor := []react.Option{
+		react.WithCompleter(meteredCompleter{a.completer}),
+		react.WithTools(a.tools...),
+	}
+
+	if a.effort != "" {
+		executor = append(executor, react.WithEffort(a.effort))
+	}
+
+	if a.temperature != nil {
+		executor = append(executor, react.WithTemperature(*a.temperature))
+	}
+
+	e, err := react.New(model, slices.Concat(executor, a.stepOptions)...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.executor = e
+
+	return a, nil
+}
+
+func WithCompleter(completer provider.Completer) Option {
+	return func(a *Agent) {
+		a.completer = completer
+	}
+}
+
+func WithMessages(messages ...provider.Message) Option {
+	return func(a *Agent) {
+		a.messages = messages
+	}
+}
+
+func WithTools(tool ...tool.Provider) Option {
+	return func(a *Agent) {
+		a.tools = tool
+	}
+}
+
+func WithEffort(effort provider.Effort) Option {
+	return func(a *Agent) {
+		a.effort = effort
+	}
+}
+
+func WithVerbosity(verbosity provider.Verbosity) Option {
+	return func(a *Agent) {
+		a.verbosity = verbosity
+	}
+}
+
+func WithTemperature(temperature float32) Option {
+	return func(a *Agent) {
+		a.temperature = &temperature
+	}
+}
+
+func WithMaxSteps(steps int) Option {
+	return func(a *Agent) {
+		a.maxSteps = steps
+	}
+}
+
+func WithMaxRevisions(revisions int) Option {
+	return func(a *Agent) {
+		a.maxRevisions = revisions
+	}
+}
+
+// WithStepOptions configures the react agent that carries out each step,
+// e.g. its limits or parallel tool calls
+func WithStepOptions(options ...react.Option) Option {
+	return func(a *Agent) {
+		a.stepOptions = append(a.stepOptions, options...)
+	}
+}
+
+func (a *Agent) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		var opts provider.CompleteOptions
+		if options != nil {
+			opts = *options
+		}
+
+		if opts.OutputOptions == nil && a.verbosity != "" {
+			opts.OutputOptions = &provider.OutputOptions{Verbosity: a.verbosity}
+		}
+
+		if opts.ReasoningOptions == nil && a.effort != "" {
+			opts.ReasoningOptions = &provider.ReasoningOptions{Effort: a.effort}
+		}
+
+		if opts.Temperature == nil {
+			opts.Temperature = a.temperature
+		}
+
+		if len(a.messages) > 0 {
+			values, err := template.Messages(a.messages, nil)
+
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			messages = slices.Concat(values, messages)
+		}
+
+		tools, err := a.listTools(ctx)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		r := &run{
+			Agent: a,
+
+			id:    uuid.New().String(),
+			usage: &provider.Usage{},
+
+			tools: tools,
+
+			yield: yield,
+		}
+
+		r.complete(ctx, messages, &opts)
+	}
+}
+
+func (a *Agent) listTools(ctx context.Context) ([]provider.Tool, error) {
+	var result []provider.Tool
+
+	for _, p := range a.tools {
+		tools, err := p.Tools(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, tools...)
+	}
+
+	return result, nil
+}
+
+// run holds the state of one request
+type run struct {
+	*Agent
+
+	id    string
+	usage *provider.Usage
+
+	tools []provider.Tool
+
+	yield func(*provider.Completion, error) bool
+}
+
+type stepResult struct {
+	Step   string
+	Output string
+
+	Failed bool
+}
+
+func (r *run) complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) {
+	steps, err := r.plan(ctx, messages, nil, nil)
+
+	if err != nil {
+		r.yield(nil, err)
+		return
+	}
+
+	if !r.status(formatPlan("Plan", steps)) {
+		return
+	}
+
+	var results []stepResult
+
+	revisions := 0
+
+	for len(steps) > 0 {
+		if len(results) >= r.maxSteps {
+			if !r.status(fmt.Sprintf("Stopped after %d steps\n\n", len(results))) {
+				return
+			}
+
+			break
+		}
+
+		n := len(results) + 1
+
+		if !r.status(fmt.Sprintf("Step %d: %s\n", n, steps[0])) {
+			return
+		}
+
+		result, err := r.step(ctx, messages, results, steps)
+
+		if err != nil {
+			r.yield(nil, err)
+			return
+		}
+
+		results = append(results, result)
+		steps = steps[1:]
+
+		if !result.Failed {
+			if !r.status(fmt.Sprintf("Step %d completed\n\n", n)) {
+				return
+			}
+
+			continue
+		}
+
+		if !r.status(fmt.Sprintf("Step %d failed: %s\n\n", n, result.Output)) {
+			return
+		}
+
+		if revisions >= r.maxRevisions || len(results) >= r.maxSteps {
+			break
+		}
+
+		revisions++
+
+		if steps, err = r.plan(ctx, messages, results, steps); err != nil {
+			r.yield(nil, err)
+			return
+		}
+
+		if !r.status(formatPlan("Revised plan", steps)) {
+			return
+		}
+	}
+
+	r.synthesize(ctx, messages, results, options)
+}
+
+// step carries out the first of steps with the executor. A step the model
+// reports as impossible, or one that ends at the executor's limits, fails.
+func (r *run) step(ctx context.Context, messages []provider.Message, results []stepResult, steps []string) (stepResult, error) {
+	result := stepResult{
+		Step: steps[0],
+	}
+
+	input := slices.Concat(messages, []provider.Message{
+		provider.UserMessage(stepPrompt(results, steps)),
+	})
+
+	// The step's model calls are summed up; the executor's stream only
+	// reports the usage of each call on its own
+	usage := &provider.Usage{}
+	defer addUsage(r.usage, usage)
+
+	ctx = context.WithValue(ctx, usageKey{}, usage)
+
+	acc := provider.CompletionAccumulator{}
+
+	for completion, err := range r.executor.Complete(ctx, input, nil) {
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, err
+			}
+
+			result.Output = err.Error()
+			result.Failed = true
+
+			return result, nil
+		}
+
+		acc.Add(*completion)
+	}
+
+	completion := acc.Result()
+
+	if completion.Message != nil {
+		result.Output = strings.TrimSpace(completion.Message.Text())
+	}
+
+	if reason, ok := strings.CutPrefix(result.Output, stepFailedMarker); ok {
+		result.Output = strings.TrimSpace(reason)
+		result.Failed = true
+	}
+
+	if completion.Status == provider.CompletionStatusIncomplete {
+		result.Failed = true
+	}
+
+	if result.Failed && result.Output == "" {
+		result.Output = "no result"
+	}
+
+	return result, nil
+}
+
+// synthesize streams the final answer written from the step results
+func (r *run) synthesize(ctx context.Context, messages []provider.Message, results []stepResult, options *provider.CompleteOptions) {
+	input := slices.Concat(messages, []provider.Message{
+		provider.UserMessage(synthesisPrompt(results)),
+	})
+
+	final := &provider.CompleteOptions{
+		Stop: options.Stop,
+
+		OutputOptions:    options.OutputOptions,
+		ReasoningOptions: options.ReasoningOptions,
+
+		MaxTokens:   options.MaxTokens,
+		Temperature: options.Temperature,
+
+		Schema: options.Schema,
+	}
+
+	for completion, err := range r.completer.Complete(ctx, input, final) {
+		if err != nil {
+			r.yield(nil, err)
+			return
+		}
+
+		delta := *completion
+
+		delta.ID = r.id
+		delta.Model = r.model
+
+		// The final usage covers the whole run, planning and steps included
+		if completion.Usage != nil {
+			usage := *r.usage
+
+			addUsage(&usage, completion.Usage)
+
+			delta.Usage = &usage
+		}
+
+		if !r.yield(&delta, nil) {
+			return
+		}
+	}
+}
+
+// status streams text as reasoning summary
+func (r *run) status(text string) bool {
+	return r.yield(&provider.Completion{
+		ID:    r.id,
+		Model: r.model,
+
+		Message: &provider.Message{
+			Role: provider.MessageRoleAssistant,
+
+			Content: []provider.Content{
+				provider.ReasoningContent(provider.Reasoning{
+					Summary: text,
+				}),
+			},
+		},
+	}, nil)
+}
+
+// usageKey carries the usage of the step a model call belongs to
+type usageKey struct{}
+
+// meteredCompleter adds the usage of each model call of the executor to the
+// usage of its step. Streams repeat the running usage of a call, so a call's
+// usage is accumulated before it is added.
+type meteredCompleter struct {
+	provider.Completer
+}
+
+func (c meteredCompleter) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		acc := provider.CompletionAccumulator{}
+
+		if usage, ok := ctx.Value(usageKey{}).(*provider.Usage); ok {
+			defer func() {
+				addUsage(usage, acc.Result().Usage)
+			}()
+		}
+
+		for completion, err := range c.Completer.Complete(ctx, messages, options) {
+			if completion != nil {
+				acc.Add(*completion)
+			}
+
+			if !yield(completion, err) {
+				return
+			}
+		}
+	}
+}
+
+func addUsage(total, usage *provider.Usage) {
+	if usage == nil {
+		return
+	}
+
+	total.InputTokens += usage.InputTokens
+	total.OutputTokens += usage.OutputTokens
+	total.ReasoningTokens += usage.ReasoningTokens
+	total.CacheReadInputTokens += usage.CacheReadInputTokens
+	total.CacheCreationInputTokens += usage.CacheCreationInputTokens
+}
@@ -0,0 +1,168 @@
+package planner
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mockCompleter returns one queued response per Complete call
+type mockCompleter struct {
+	responses []string
+
+	capturedMessages [][]provider.Message
+	capturedOptions  []*provider.CompleteOptions
+}
+
+func (m *mockCompleter) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		m.capturedMessages = append(m.capturedMessages, messages)
+		m.capturedOptions = append(m.capturedOptions, options)
+
+		if len(m.capturedMessages) > len(m.responses) {
+			return
+		}
+
+		response := m.responses[len(m.capturedMessages)-1]
+
+		message := &provider.Message{
+			Role: provider.MessageRoleAssistant,
+		}
+
+		if name, args, ok := strings.Cut(response, "|"); ok {
+			message.Content = append(message.Content, provider.ToolCallContent(provider.ToolCall{ID: "call-" + name, Name: name, Arguments: args}))
+		} else {
+			message.Content = append(message.Content, provider.TextContent(response))
+		}
+
+		yield(&provider.Completion{
+			ID:      "upstream",
+			Model:   "upstream-model",
+			Message: message,
+			Usage:   &provider.Usage{InputTokens: 10, OutputTokens: 1},
+		}, nil)
+	}
+}
+
+type toolFunc struct {
+	tools   []provider.Tool
+	execute func(ctx context.Context, name string, params map[string]any) (any, error)
+}
+
+func (f toolFunc) Tools(ctx context.Context) ([]provider.Tool, error) {
+	return f.tools, nil
+}
+
+func (f toolFunc) Execute(ctx context.Context, name string, params map[string]any) (any, error) {
+	return f.execute(ctx, name, params)
+}
+
+func TestComplete(t *testing.T) {
+	completer := &mockCompleter{
+		responses: []string{
+			`{"steps":["Look up the order","Check the stock"]}`,
+			`lookup|{"id":"42"}`,
+			"Order 42 contains 3 chairs",
+			"STEP FAILED: stock service unavailable",
+			`{"steps":["Estimate the delivery date"]}`,
+			"Delivery in 5 days",
+			"Your 3 chairs arrive in 5 days.",
+		},
+	}
+
+	var lookups []string
+
+	agent, err := New("planner",
+		WithCompleter(completer),
+		WithTools(toolFunc{
+			tools: []provider.Tool{{Name: "lookup", Description: "Looks up an order"}},
+			execute: func(ctx context.Context, name string, params map[string]any) (any, error) {
+				lookups = append(lookups, params["id"].(string))
+				return "3 chairs", nil
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	acc := provider.CompletionAccumulator{}
+
+	for completion, err := range agent.Complete(context.Background(), []provider.Message{provider.UserMessage("When does order 42 arrive?")}, nil) {
+		require.NoError(t, err)
+		require.Equal(t, "planner", completion.Model)
+
+		acc.Add(*completion)
+	}
+
+	result := acc.Result()
+
+	require.Equal(t, "Your 3 chairs arrive in 5 days.", result.Message.Text())
+	require.Equal(t, []string{"42"}, lookups)
+	// every model call counts, the two of the first step included
+	require.Equal(t, 70, result.Usage.InputTokens)
+
+	require.Len(t, completer.capturedOptions, 7)
+
+	// plans are structured output, listing the available tools
+	require.Equal(t, planSchema, completer.capturedOptions[0].Schema)
+	require.Equal(t, planSchema, completer.capturedOptions[4].Schema)
+	require.Contains(t, completer.capturedMessages[0][1].Text(), "- lookup: Looks up an order")
+
+	// the revision sees the failure, later steps and the answer the results
+	require.Contains(t, completer.capturedMessages[4][1].Text(), "Step 2 (failed): Check the stock\nstock service unavailable")
+	require.Contains(t, completer.capturedMessages[5][1].Text(), "Estimate the delivery date")
+	require.Contains(t, completer.capturedMessages[6][1].Text(), "Order 42 contains 3 chairs")
+	require.Nil(t, completer.capturedOptions[6].Tools)
+
+	var summary string
+
+	for _, c := range result.Message.Content {
+		if c.Reasoning != nil {
+			summary += c.Reasoning.Summary
+		}
+	}
+
+	require.Equal(t, "Plan:\n1. Look up the order\n2. Check the stock\n\n"+
+		"Step 1: Look up the order\nStep 1 completed\n\n"+
+		"Step 2: Check the stock\nStep 2 failed: stock service unavailable\n\n"+
+		"Revised plan:\n1. Estimate the delivery date\n\n"+
+		"Step 3: Estimate the delivery date\nStep 3 completed\n\n", summary)
+}
+
+func TestComplete_MaxRevisions(t *testing.T) {
+	completer := &mockCompleter{
+		responses: []string{
+			`{"steps":["Try it","Use it"]}`,
+			"STEP FAILED: broken",
+			"Sorry, that did not work.",
+		},
+	}
+
+	agent, err := New("planner", WithCompleter(completer), WithMaxRevisions(0))
+	require.NoError(t, err)
+
+	acc := provider.CompletionAccumulator{}
+
+	for completion, err := range agent.Complete(context.Background(), []provider.Message{provider.UserMessage("Do it")}, nil) {
+		require.NoError(t, err)
+		acc.Add(*completion)
+	}
+
+	require.Equal(t, "Sorry, that did not work.", acc.Result().Message.Text())
+
+	// the failed step ends the run; the remaining step is not attempted
+	require.Len(t, completer.capturedMessages, 3)
+	require.Contains(t, completer.capturedMessages[2][1].Text(), "Step 1 (failed): Try it\nbroken")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("planner")
+	require.ErrorContains(t, err, "missing completer")
+
+	_, err = New("planner", WithCompleter(&mockCompleter{}), WithMaxSteps(0))
+	require.Error(t, err)
+}
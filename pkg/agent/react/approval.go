@@ -0,0 +1,273 @@
+package react
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+const (
+	// approvalTimeout is how long a surfaced call awaits the caller's decision
+	approvalTimeout = 24 * time.Hour
+
+	// maxPendingApprovals bounds the calls awaiting a decision
+	maxPendingApprovals = 4096
+)
+
+// WithApprovalTools adds tools whose calls need the caller's approval. Such a
+// call pauses the run: it is surfaced to the caller as a tool call of kind
+// provider.ToolKindApproval, and the run resumes once the caller sends the
+// decision back as the call's result.
+func WithApprovalTools(tools ...tool.Provider) Option {
+	return func(c *Agent) {
+		c.approvalTools = tools
+	}
+}
+
+// approvals keeps the calls surfaced for approval. The history a caller
+// sends back is not trusted: only a call surfaced to the same user, with
+// the same arguments, runs once approved, and only once.
+type approvals struct {
+	mu      sync.Mutex
+	pending map[string]pendingApproval
+}
+
+type pendingApproval struct {
+	user string
+	name string
+	args string
+
+	expires time.Time
+}
+
+func newApprovals() *approvals {
+	return &approvals{
+		pending: make(map[string]pendingApproval),
+	}
+}
+
+// add records a call surfaced to the caller of ctx
+func (a *approvals) add(ctx context.Context, call provider.ToolCall) {
+	user, _ := ctx.Value(auth.UserContextKey).(string)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+
+	for id, p := range a.pending {
+		if now.After(p.expires) {
+			delete(a.pending, id)
+		}
+	}
+
+	// Still full of live requests: drop an arbitrary one
+	if len(a.pending) >= maxPendingApprovals {
+		for id := range a.pending {
+			delete(a.pending, id)
+			break
+		}
+	}
+
+	a.pending[call.ID] = pendingApproval{
+		user: user,
+		name: call.Name,
+		args: canonicalArguments(call.Arguments),
+
+		expires: now.Add(approvalTimeout),
+	}
+}
+
+// take removes the pending request of call and reports whether call is the
+// one surfaced to the caller of ctx
+func (a *approvals) take(ctx context.Context, call provider.ToolCall) bool {
+	user, _ := ctx.Value(auth.UserContextKey).(string)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	p, ok := a.pending[call.ID]
+
+	if !ok || p.user != user {
+		return false
+	}
+
+	delete(a.pending, call.ID)
+
+	return time.Now().Before(p.expires) && p.name == call.Name && p.args == canonicalArguments(call.Arguments)
+}
+
+// canonicalArguments normalizes JSON arguments, so callers re-encoding
+// them with other spacing or key order still match
+func canonicalArguments(args string) string {
+	var v any
+
+	if err := json.Unmarshal([]byte(args), &v); err != nil {
+		return args
+	}
+
+	data, _ := json.Marshal(v)
+
+	return string(data)
+}
+
+// approvalWords are the answers a caller without dedicated approval items
+// (e.g. a plain tool result on Chat Completions) approves a call with
+var approvalWords = []string{"approve", "approved", "allow", "yes", "y", "ok", "true"}
+
+// resolveApprovals rewrites the caller's approval exchanges into regular tool
+// calls and results for the model. Decisions answering the last assistant
+// turn are pending: approved calls run now, denied ones become tool errors.
+// Earlier decisions were resolved by a previous run and are only summarized.
+func (c *Agent) resolveApprovals(ctx context.Context, messages []provider.Message, agentTools map[string]tool.Provider, approval map[string]bool) ([]provider.Message, error) {
+	if len(approval) == 0 {
+		return messages, nil
+	}
+
+	last := -1
+
+	for i, m := range messages {
+		if m.Role == provider.MessageRoleAssistant {
+			last = i
+		}
+	}
+
+	calls := make(map[string]provider.ToolCall)
+	pending := make(map[string]bool)
+
+	result := make([]provider.Message, len(messages))
+
+	for i, m := range messages {
+		m.Content = slices.Clone(m.Content)
+
+		for j, cnt := range m.Content {
+			if call := cnt.ToolCall; call != nil && m.Role == provider.MessageRoleAssistant && (call.Kind == provider.ToolKindApproval || approval[call.Name]) {
+				resolved := *call
+				resolved.Kind = provider.ToolKindFunction
+
+				m.Content[j].ToolCall = &resolved
+
+				calls[resolved.ID] = resolved
+				pending[resolved.ID] = i == last
+			}
+
+			if r := cnt.ToolResult; r != nil {
+				call, ok := calls[r.ID]
+
+				if !ok {
+					continue
+				}
+
+				approved, reason := approvalDecision(*r)
+
+				var resolved provider.ToolResult
+
+				if i > last && pending[r.ID] {
+					pending[r.ID] = false
+
+					// Calls not surfaced by this agent, or altered since, never run
+					if !c.approvals.take(ctx, call) && approved {
+						resolved = provider.ToolResult{
+							ID:    r.ID,
+							Parts: []provider.Part{{Text: "Error: this call was not requested for approval; request it again"}},
+						}
+					} else {
+						var err error
+
+						if resolved, err = c.decide(ctx, call, approved, reason, agentTools); err != nil {
+							return nil, err
+						}
+					}
+				} else {
+					text := deniedText(reason)
+
+					if approved {
+						text = "The user approved this call; its result is no longer available."
+					}
+
+					resolved = provider.ToolResult{
+						ID:    r.ID,
+						Parts: []provider.Part{{Text: text}},
+					}
+				}
+
+				m.Content[j].ToolResult = &resolved
+			}
+		}
+
+		result[i] = m
+	}
+
+	return result, nil
+}
+
+// decide runs an approved call, or answers a denied one with a tool error
+func (c *Agent) decide(ctx context.Context, call provider.ToolCall, approved bool, reason string, agentTools map[string]tool.Provider) (provider.ToolResult, error) {
+	if !approved {
+		return provider.ToolResult{
+			ID:    call.ID,
+			Parts: []provider.Part{{Text: deniedText(reason)}},
+		}, nil
+	}
+
+	p, ok := agentTools[call.Name]
+
+	if !ok {
+		return provider.ToolResult{
+			ID:    call.ID,
+			Parts: []provider.Part{{Text: "Error: the tool is no longer available"}},
+		}, nil
+	}
+
+	var params map[string]any
+
+	if err := json.Unmarshal([]byte(call.Arguments), &params); err != nil {
+		return provider.ToolResult{}, err
+	}
+
+	return c.call(ctx, toolCall{
+		ToolCall: call,
+		provider: p,
+		params:   params,
+	})
+}
+
+// approvalDecision reads the caller's answer to an approval request
+func approvalDecision(r provider.ToolResult) (bool, string) {
+	var texts []string
+
+	for _, p := range r.Parts {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+
+	text := strings.TrimSpace(strings.Join(texts, "\n"))
+
+	if r.Kind == provider.ToolKindApproval {
+		return !r.IsError, text
+	}
+
+	if r.IsError {
+		return false, text
+	}
+
+	return slices.Contains(approvalWords, strings.ToLower(text)), text
+}
+
+func deniedText(reason string) string {
+	text := "Error: the user denied this tool call"
+
+	if reason != "" {
+		text += ": " + reason
+	}
+
+	return text
+}
@@ -0,0 +1,143 @@
+package react
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+// Limits bounds a single run of the agent loop. Zero values disable the
+// respective limit. When a limit is hit, the model is asked for a final
+// answer without tools and the completion is reported as incomplete.
+type Limits struct {
+	// MaxTurns is the number of model turns before the final answer
+	MaxTurns int
+
+	// MaxTokens is the cumulative input and output token usage over all turns
+	MaxTokens int
+
+	// MaxDuration is the wall-clock time of the run. It is checked between
+	// turns, so a turn in flight is never cut short.
+	MaxDuration time.Duration
+
+	// MaxRepeatedCalls is how often a tool may be called with identical
+	// arguments. A further identical call is not executed and ends the loop.
+	MaxRepeatedCalls int
+}
+
+func WithLimits(limits Limits) Option {
+	return func(c *Agent) {
+		c.limits = limits
+	}
+}
+
+// budget tracks the consumption of the limits over one run
+type budget struct {
+	limits Limits
+
+	start time.Time
+
+	turns  int
+	tokens int
+
+	calls  map[string]int
+	looped bool
+}
+
+func newBudget(limits Limits) *budget {
+	return &budget{
+		limits: limits,
+
+		start: time.Now(),
+
+		calls: make(map[string]int),
+	}
+}
+
+// add records a completed turn
+func (b *budget) add(usage *provider.Usage) {
+	b.turns++
+
+	if usage != nil {
+		b.tokens += usage.InputTokens + usage.OutputTokens
+	}
+}
+
+// repeated records a tool call and reports whether it exceeds the allowed
+// number of identical calls
+func (b *budget) repeated(name string, params map[string]any) bool {
+	if b.limits.MaxRepeatedCalls <= 0 {
+		return false
+	}
+
+	// encoding/json sorts map keys, so equal arguments yield equal keys
+	data, _ := json.Marshal(params)
+	key := name + "\x00" + string(data)
+
+	b.calls[key]++
+
+	if b.calls[key] <= b.limits.MaxRepeatedCalls {
+		return false
+	}
+
+	// looped stops the run after this turn; other calls still execute
+	b.looped = true
+
+	return true
+}
+
+// exceeded describes the first limit hit, or returns nil
+func (b *budget) exceeded() *provider.StopDetails {
+	details := func(category, explanation string) *provider.StopDetails {
+		return &provider.StopDetails{
+			Type: "agent_limit",
+
+			Category:    category,
+			Explanation: explanation,
+		}
+	}
+
+	switch {
+	case b.looped:
+		return details("tool_loop", fmt.Sprintf("the same tool call was repeated more than %d times", b.limits.MaxRepeatedCalls))
+
+	case b.limits.MaxTurns > 0 && b.turns >= b.limits.MaxTurns:
+		return details("max_turns", fmt.Sprintf("the agent reached its limit of %d turns", b.limits.MaxTurns))
+
+	case b.limits.MaxTokens > 0 && b.tokens >= b.limits.MaxTokens:
+		return details("max_tokens", fmt.Sprintf("the agent used %d of its %d tokens", b.tokens, b.limits.MaxTokens))
+
+	case b.limits.MaxDuration > 0 && time.Since(b.start) >= b.limits.MaxDuration:
+		return details("timeout", fmt.Sprintf("the agent reached its time limit of %s", b.limits.MaxDuration))
+	}
+
+	return nil
+}
+
+// finish ends a run that hit a limit: the model gives a final answer from the
+// work so far with tools disabled, and the completion is marked incomplete.
+func (c *Agent) finish(ctx context.Context, accID string, input []provider.Message, options *provider.CompleteOptions, agentTools map[string]tool.Provider, approval map[string]bool, details *provider.StopDetails, yield func(*provider.Completion, error) bool) {
+	final := *options
+
+	final.ToolOptions = &provider.ToolOptions{
+		Choice: provider.ToolChoiceNone,
+	}
+
+	if _, ok := c.turn(ctx, accID, input, &final, agentTools, approval, yield); !ok {
+		return
+	}
+
+	yield(&provider.Completion{
+		ID:    accID,
+		Model: c.model,
+
+		Status: provider.CompletionStatusIncomplete,
+
+		StopReason:  provider.StopReasonAgentLimit,
+		StopDetails: details,
+	}, nil)
+}
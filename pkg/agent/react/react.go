@@ -7,11 +7,15 @@ import (
 	"iter"
 	"maps"
 	"slices"
+	"strings"
+	"sync"
 
 	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/memory"
 	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/template"
 	"github.com/adrianliechti/wingman/pkg/tool"
+	"github.com/adrianliechti/wingman/pkg/tool/remember"
 
 	"github.com/google/uuid"
 )
@@ -24,22 +28,35 @@ const (
 	ToolPhaseStart ToolPhase = iota + 1
 	ToolPhaseResult
 	ToolPhaseError
+
+	// ToolPhaseProgress carries a status message of a running tool
+	ToolPhaseProgress
 )
 
 type ToolEvent struct {
 	Phase ToolPhase
 
+	// Depth is 0 for the agent's own tool calls and n for calls of an agent
+	// it delegated to, n levels down
+	Depth int
+
 	CallID string
 	Name   string
 
 	Input map[string]any
 
+	Progress string
+
 	Result *provider.ToolResult
 	Error  error
 }
 
 type ToolObserver func(ctx context.Context, event ToolEvent)
 
+// observerKey carries the observer of the agent whose tool call is running,
+// so agents delegated to from that call report their events up the chain
+type observerKey struct{}
+
 type Agent struct {
 	model string
 
@@ -48,12 +65,28 @@ type Agent struct {
 	tools    []tool.Provider
 	messages []provider.Message
 
+	approvalTools []tool.Provider
+	approvals     *approvals
+
 	effort    provider.Effort
 	verbosity provider.Verbosity
 
 	temperature *float32
 
+	limits Limits
+
+	// parallelism is the number of agent-owned tool calls of a turn run
+	// concurrently. Up to 1, the model is asked for one call per turn.
+	parallelism int
+
 	observer ToolObserver
+
+	memory memory.Provider
+
+	compaction Compaction
+
+	// progress reports tool calls to the request's agent.ProgressReporter
+	progress bool
 }
 
 type Option func(*Agent)
@@ -61,6 +94,8 @@ type Option func(*Agent)
 func New(model string, options ...Option) (*Agent, error) {
 	c := &Agent{
 		model: model,
+
+		approvals: newApprovals(),
 	}
 
 	for _, option := range options {
@@ -71,6 +106,16 @@ func New(model string, options ...Option) (*Agent, error) {
 		return nil, errors.New("missing completer provider")
 	}
 
+	if c.memory != nil {
+		t, err := remember.New(c.memory)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.tools = slices.Concat(c.tools, []tool.Provider{t})
+	}
+
 	return c, nil
 }
 
@@ -110,6 +155,32 @@ func WithTemperature(temperature float32) Option {
 	}
 }
 
+// WithParallelToolCalls lets the model request several agent-owned tool
+// calls per turn and runs up to limit of them concurrently. Tool providers
+// and the ToolObserver must then be safe for concurrent use.
+func WithParallelToolCalls(limit int) Option {
+	return func(c *Agent) {
+		c.parallelism = limit
+	}
+}
+
+// WithMemory gives the agent the remember, recall and forget tools over the
+// memories of the requesting user. The memories relevant to the latest user
+// message are available to the agent's message templates as .memories.
+func WithMemory(memory memory.Provider) Option {
+	return func(c *Agent) {
+		c.memory = memory
+	}
+}
+
+// WithToolProgress reports the agent's tool calls, including those of agents
+// it delegates to, to the agent.ProgressReporter of the request
+func WithToolProgress() Option {
+	return func(c *Agent) {
+		c.progress = true
+	}
+}
+
 func WithToolObserver(observer ToolObserver) Option {
 	return func(c *Agent) {
 		c.observer = observer
@@ -141,7 +212,7 @@ func (c *Agent) Complete(ctx context.Context, messages []provider.Message, optio
 		}
 
 		if len(c.messages) > 0 {
-			values, err := template.Messages(c.messages, nil)
+			values, err := template.Messages(c.messages, c.templateData(ctx, messages))
 
 			if err != nil {
 				yield(nil, err)
@@ -153,10 +224,19 @@ func (c *Agent) Complete(ctx context.Context, messages []provider.Message, optio
 
 		input := slices.Clone(messages)
 
+		// The tool calls of a run, including those of agents it delegates
+		// to, share a session unless the caller brings one
+		if agent.Session(ctx) == "" {
+			ctx = agent.WithSession(ctx, uuid.NewString())
+		}
+
 		agentTools := make(map[string]tool.Provider)
 		inputTools := make(map[string]provider.Tool)
 
-		for _, p := range c.tools {
+		// approval holds the names of agent tools that need the caller's consent
+		approval := make(map[string]bool)
+
+		for i, p := range slices.Concat(c.tools, c.approvalTools) {
 			tools, err := p.Tools(ctx)
 
 			if err != nil {
@@ -167,6 +247,10 @@ func (c *Agent) Complete(ctx context.Context, messages []provider.Message, optio
 			for _, tool := range tools {
 				agentTools[tool.Name] = p
 				inputTools[tool.Name] = tool
+
+				if i >= len(c.tools) {
+					approval[tool.Name] = true
+				}
 			}
 		}
 
@@ -174,7 +258,11 @@ func (c *Agent) Complete(ctx context.Context, messages []provider.Message, optio
 			inputTools[t.Name] = t
 		}
 
-		inputToolOptions := mergeToolOptions(opts.ToolOptions, slices.Collect(maps.Keys(agentTools)))
+		// Parallel calls stay off while the caller brings its own tools, which
+		// keeps the model from mixing agent and caller calls in one turn
+		parallel := c.parallelism > 1 && len(opts.Tools) == 0
+
+		inputToolOptions := mergeToolOptions(opts.ToolOptions, slices.Collect(maps.Keys(agentTools)), parallel)
 
 		inputOptions := &provider.CompleteOptions{
 			Stop:        opts.Stop,
@@ -190,94 +278,64 @@ func (c *Agent) Complete(ctx context.Context, messages []provider.Message, optio
 			Schema: opts.Schema,
 		}
 
-		accID := uuid.New().String()
-
-		for {
-			acc := provider.CompletionAccumulator{}
-
-			toolNamesByID := map[string]string{}
-			var lastToolCallID string
-
-			for completion, err := range c.completer.Complete(ctx, input, inputOptions) {
-				if err != nil {
-					yield(nil, err)
-					return
-				}
+		input, err := c.resolveApprovals(ctx, input, agentTools, approval)
 
-				acc.Add(*completion)
-
-				delta := &provider.Completion{
-					ID:    accID,
-					Model: c.model,
+		if err != nil {
+			yield(nil, err)
+			return
+		}
 
-					Usage: completion.Usage,
-				}
+		accID := uuid.New().String()
 
-				if completion.Message != nil {
-					message := &provider.Message{
-						Role: completion.Message.Role,
-					}
+		b := newBudget(c.limits)
 
-					for _, cnt := range completion.Message.Content {
-						if cnt.ToolCall != nil {
-							id := cnt.ToolCall.ID
-							name := cnt.ToolCall.Name
-
-							// Streaming providers vary in what they put on argument-delta
-							// chunks: some include the ID, some include nothing. Track the
-							// last seen ID so deltas can be attributed to the right call.
-							if id != "" {
-								lastToolCallID = id
-								if name != "" {
-									toolNamesByID[id] = name
-								}
-							} else if name == "" {
-								id = lastToolCallID
-							}
-
-							if name == "" {
-								name = toolNamesByID[id]
-							}
-
-							if _, found := agentTools[name]; found {
-								continue
-							}
-						}
+		for {
+			if limit := b.exceeded(); limit != nil {
+				c.finish(ctx, accID, input, inputOptions, agentTools, approval, limit, yield)
+				return
+			}
 
-						message.Content = append(message.Content, cnt)
-					}
+			if input, err = c.compact(ctx, input, inputOptions.Tools); err != nil {
+				yield(nil, err)
+				return
+			}
 
-					delta.Message = message
-				}
+			completion, ok := c.turn(ctx, accID, input, inputOptions, agentTools, approval, yield)
 
-				if !yield(delta, nil) {
-					return
-				}
+			if !ok {
+				return
 			}
 
-			completion := acc.Result()
-
-			completion.ID = accID
-			completion.Model = c.model
+			b.add(completion.Usage)
 
 			if completion.Message == nil {
 				return
 			}
 
-			var hasAgentCall, hasCallerCall bool
+			var hasAgentCall, hasCallerCall, hasApprovalCall bool
 
 			for _, cnt := range completion.Message.Content {
 				if cnt.ToolCall == nil {
 					continue
 				}
 
-				if _, isAgent := agentTools[cnt.ToolCall.Name]; isAgent {
+				if approval[cnt.ToolCall.Name] {
+					hasApprovalCall = true
+					c.approvals.add(ctx, *cnt.ToolCall)
+				} else if _, isAgent := agentTools[cnt.ToolCall.Name]; isAgent {
 					hasAgentCall = true
 				} else {
 					hasCallerCall = true
 				}
 			}
 
+			// Calls awaiting approval were surfaced to the caller; the run
+			// pauses until the decisions come back. Agent calls of the same
+			// turn are dropped and left for the model to repeat.
+			if hasApprovalCall {
+				return
+			}
+
 			// Agent tools are executed in-loop; caller tools are surfaced through
 			// the stream for the caller to handle. A single assistant turn cannot
 			// span both — the chain would either loop without the caller's result
@@ -293,77 +351,43 @@ func (c *Agent) Complete(ctx context.Context, messages []provider.Message, optio
 
 			input = append(input, *completion.Message)
 
+			var calls []toolCall
+
 			for _, cnt := range completion.Message.Content {
 				if cnt.ToolCall == nil {
 					continue
 				}
 
-				t := agentTools[cnt.ToolCall.Name]
-
-				var params map[string]any
+				call := toolCall{
+					ToolCall: *cnt.ToolCall,
+					provider: agentTools[cnt.ToolCall.Name],
+				}
 
-				if err := json.Unmarshal([]byte(cnt.ToolCall.Arguments), &params); err != nil {
+				if err := json.Unmarshal([]byte(cnt.ToolCall.Arguments), &call.params); err != nil {
 					yield(nil, err)
 					return
 				}
 
-				if c.observer != nil {
-					c.observer(ctx, ToolEvent{
-						Phase:  ToolPhaseStart,
-						CallID: cnt.ToolCall.ID,
-						Name:   cnt.ToolCall.Name,
-						Input:  params,
-					})
-				}
-
-				result, err := t.Execute(ctx, cnt.ToolCall.Name, params)
-
-				if err != nil {
-					if c.observer != nil {
-						c.observer(ctx, ToolEvent{
-							Phase:  ToolPhaseError,
-							CallID: cnt.ToolCall.ID,
-							Name:   cnt.ToolCall.Name,
-							Input:  params,
-							Error:  err,
-						})
-					}
-
-					input = append(input, provider.Message{
-						Role: provider.MessageRoleUser,
-						Content: []provider.Content{
-							provider.ToolResultContent(provider.ToolResult{
-								ID:    cnt.ToolCall.ID,
-								Parts: []provider.Part{{Text: "Error: " + err.Error()}},
-							}),
-						},
-					})
-
-					continue
-				}
+				// A repeated identical call is answered without running the
+				// tool; the budget ends the loop before the next turn
+				call.repeated = b.repeated(call.Name, call.params)
 
-				toolResult, err := renderToolResult(t, cnt.ToolCall.ID, cnt.ToolCall.Name, result)
+				calls = append(calls, call)
+			}
 
-				if err != nil {
-					yield(nil, err)
-					return
-				}
+			results, err := c.execute(ctx, calls)
 
-				if c.observer != nil {
-					c.observer(ctx, ToolEvent{
-						Phase:  ToolPhaseResult,
-						CallID: cnt.ToolCall.ID,
-						Name:   cnt.ToolCall.Name,
-						Input:  params,
-						Result: &toolResult,
-					})
-				}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
 
+			for _, result := range results {
 				input = append(input, provider.Message{
 					Role: provider.MessageRoleUser,
 
 					Content: []provider.Content{
-						provider.ToolResultContent(toolResult),
+						provider.ToolResultContent(result),
 					},
 				})
 			}
@@ -371,20 +395,318 @@ func (c *Agent) Complete(ctx context.Context, messages []provider.Message, optio
 	}
 }
 
+// templateData returns the values available to the agent's message
+// templates. Memories are recalled for the latest user message; a failing
+// recall leaves them empty rather than failing the request.
+func (c *Agent) templateData(ctx context.Context, messages []provider.Message) map[string]any {
+	data := map[string]any{}
+
+	if c.memory == nil {
+		return data
+	}
+
+	memories := []memory.Memory{}
+
+	if user, ok := memory.User(ctx); ok {
+		var query string
+
+		for _, m := range slices.Backward(messages) {
+			if m.Role == provider.MessageRoleUser && m.Text() != "" {
+				query = m.Text()
+				break
+			}
+		}
+
+		if result, err := c.memory.Recall(ctx, user, query, nil); err == nil {
+			memories = result
+		}
+	}
+
+	data["memories"] = memories
+
+	return data
+}
+
+// toolCall is an agent-owned tool call with its parsed arguments
+type toolCall struct {
+	provider.ToolCall
+
+	provider tool.Provider
+	params   map[string]any
+
+	repeated bool
+}
+
+// execute runs the agent-owned tool calls of a turn, up to the configured
+// fan-out at a time, and returns their results in call order. A failing tool
+// yields an error result for the model; only an unrenderable result fails the
+// run.
+func (c *Agent) execute(ctx context.Context, calls []toolCall) ([]provider.ToolResult, error) {
+	results := make([]provider.ToolResult, len(calls))
+	errs := make([]error, len(calls))
+
+	limit := max(c.parallelism, 1)
+
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+
+	for i, call := range calls {
+		if call.repeated {
+			results[i] = provider.ToolResult{
+				ID:    call.ID,
+				Parts: []provider.Part{{Text: "Error: this exact tool call was already made repeatedly; answer with the information at hand"}},
+			}
+
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			results[i], errs[i] = c.call(ctx, call)
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// call runs a single tool call, reporting its phases to the observer
+func (c *Agent) call(ctx context.Context, call toolCall) (provider.ToolResult, error) {
+	c.notify(ctx, ToolEvent{
+		Phase:  ToolPhaseStart,
+		CallID: call.ID,
+		Name:   call.Name,
+		Input:  call.params,
+	})
+
+	// Tool events of agents delegated to from this call are reported one
+	// level deeper
+	scope := context.WithValue(ctx, observerKey{}, ToolObserver(func(_ context.Context, event ToolEvent) {
+		event.Depth++
+		c.notify(ctx, event)
+	}))
+
+	scope = tool.WithProgress(scope, func(message string) {
+		c.notify(ctx, ToolEvent{
+			Phase:    ToolPhaseProgress,
+			CallID:   call.ID,
+			Name:     call.Name,
+			Input:    call.params,
+			Progress: message,
+		})
+	})
+
+	result, err := call.provider.Execute(scope, call.Name, call.params)
+
+	if err != nil {
+		c.notify(ctx, ToolEvent{
+			Phase:  ToolPhaseError,
+			CallID: call.ID,
+			Name:   call.Name,
+			Input:  call.params,
+			Error:  err,
+		})
+
+		return provider.ToolResult{
+			ID:    call.ID,
+			Parts: []provider.Part{{Text: "Error: " + err.Error()}},
+		}, nil
+	}
+
+	toolResult, err := renderToolResult(call.provider, call.ID, call.Name, result)
+
+	if err != nil {
+		return provider.ToolResult{}, err
+	}
+
+	c.notify(ctx, ToolEvent{
+		Phase:  ToolPhaseResult,
+		CallID: call.ID,
+		Name:   call.Name,
+		Input:  call.params,
+		Result: &toolResult,
+	})
+
+	return toolResult, nil
+}
+
+// notify reports a tool event to the agent's observer and to the agent that
+// delegated to this one, if any
+func (c *Agent) notify(ctx context.Context, event ToolEvent) {
+	if c.observer != nil {
+		c.observer(ctx, event)
+	}
+
+	parent, nested := ctx.Value(observerKey{}).(ToolObserver)
+
+	if nested {
+		parent(ctx, event)
+		return
+	}
+
+	// Only the outermost agent reports progress; events of delegated agents
+	// reach it through the observer chain
+	if report := agent.Progress(ctx); c.progress && report != nil {
+		report(ctx, toolProgress(event))
+	}
+
+	if trace := agent.ToolTrace(ctx); trace != nil {
+		trace(ctx, toolProgress(event))
+	}
+}
+
+func toolProgress(event ToolEvent) agent.ToolProgress {
+	progress := agent.ToolProgress{
+		ID:    event.CallID,
+		Name:  event.Name,
+		Depth: event.Depth,
+
+		Status: agent.ToolStatusInProgress,
+	}
+
+	if data, err := json.Marshal(event.Input); err == nil && event.Input != nil {
+		progress.Arguments = string(data)
+	}
+
+	switch event.Phase {
+	case ToolPhaseProgress:
+		progress.Output = event.Progress
+
+	case ToolPhaseResult:
+		progress.Status = agent.ToolStatusCompleted
+
+		if event.Result != nil {
+			var texts []string
+
+			for _, p := range event.Result.Parts {
+				if p.Text != "" {
+					texts = append(texts, p.Text)
+				}
+			}
+
+			progress.Output = strings.Join(texts, "\n")
+		}
+
+	case ToolPhaseError:
+		progress.Status = agent.ToolStatusFailed
+
+		if event.Error != nil {
+			progress.Error = event.Error.Error()
+		}
+	}
+
+	return progress
+}
+
+// turn streams one completion of the loop to the caller, hiding the calls to
+// agent tools except those awaiting approval, and returns the accumulated
+// result
+func (c *Agent) turn(ctx context.Context, accID string, input []provider.Message, options *provider.CompleteOptions, agentTools map[string]tool.Provider, approval map[string]bool, yield func(*provider.Completion, error) bool) (*provider.Completion, bool) {
+	acc := provider.CompletionAccumulator{}
+
+	toolNamesByID := map[string]string{}
+	var lastToolCallID string
+
+	for completion, err := range c.completer.Complete(ctx, input, options) {
+		if err != nil {
+			yield(nil, err)
+			return nil, false
+		}
+
+		acc.Add(*completion)
+
+		delta := &provider.Completion{
+			ID:    accID,
+			Model: c.model,
+
+			Usage: completion.Usage,
+		}
+
+		if completion.Message != nil {
+			message := &provider.Message{
+				Role: completion.Message.Role,
+			}
+
+			for _, cnt := range completion.Message.Content {
+				if cnt.ToolCall != nil {
+					id := cnt.ToolCall.ID
+					name := cnt.ToolCall.Name
+
+					// Streaming providers vary in what they put on argument-delta
+					// chunks: some include the ID, some include nothing. Track the
+					// last seen ID so deltas can be attributed to the right call.
+					if id != "" {
+						lastToolCallID = id
+						if name != "" {
+							toolNamesByID[id] = name
+						}
+					} else if name == "" {
+						id = lastToolCallID
+					}
+
+					if name == "" {
+						name = toolNamesByID[id]
+					}
+
+					if _, found := agentTools[name]; found {
+						if !approval[name] {
+							continue
+						}
+
+						// Calls awaiting approval are surfaced to the caller
+						call := *cnt.ToolCall
+						call.Kind = provider.ToolKindApproval
+
+						cnt.ToolCall = &call
+					}
+				}
+
+				message.Content = append(message.Content, cnt)
+			}
+
+			delta.Message = message
+		}
+
+		if !yield(delta, nil) {
+			return nil, false
+		}
+	}
+
+	completion := acc.Result()
+
+	completion.ID = accID
+	completion.Model = c.model
+
+	return completion, true
+}
+
 // mergeToolOptions combines user-specified ToolOptions with the agent's internal tool names.
 //
 // Agent tools are transparent to the user — they are executed by the chain loop and
 // never surfaced in the streamed output. Therefore:
 //
 //   - If no agent tools are registered, the user's options pass through unchanged.
-//   - DisableParallelToolCalls is forced on. Agent and caller tools cannot share a
-//     single assistant turn (see chain loop); serializing tool calls prevents the
-//     model from producing a turn the chain can't reconcile.
+//   - DisableParallelToolCalls is forced on unless parallel is set. Agent and caller
+//     tools cannot share a single assistant turn (see chain loop); serializing tool
+//     calls prevents the model from producing a turn the chain can't reconcile.
 //   - If the user specified ToolChoiceNone, we switch to Auto so agent tools can still
 //     fire, but restrict Allowed to only agent tool names so user tools remain uncallable.
 //   - If the user restricted the allowed list, we union it with agent tool names so the
 //     model can still invoke agent tools while respecting the user's restrictions.
-func mergeToolOptions(opts *provider.ToolOptions, agentToolNames []string) *provider.ToolOptions {
+func mergeToolOptions(opts *provider.ToolOptions, agentToolNames []string, parallel bool) *provider.ToolOptions {
 	if len(agentToolNames) == 0 {
 		return opts
 	}
@@ -394,7 +716,7 @@ func mergeToolOptions(opts *provider.ToolOptions, agentToolNames []string) *prov
 		merged = *opts
 	}
 
-	merged.DisableParallelToolCalls = true
+	merged.DisableParallelToolCalls = merged.DisableParallelToolCalls || !parallel
 
 	switch merged.Choice {
 	case provider.ToolChoiceNone:
@@ -3,11 +3,21 @@ package react
 import (
 	"context"
 	"errors"
+	"fmt"
 	"iter"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/memory/file"
 	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/summarizer"
+	"github.com/adrianliechti/wingman/pkg/tool"
 
 	"github.com/stretchr/testify/require"
 )
@@ -1352,6 +1362,74 @@ func TestComplete_ToolObserver(t *testing.T) {
 	require.Equal(t, "tc-obs", events[1].Result.ID)
 }
 
+func TestComplete_NestedToolObserver(t *testing.T) {
+	assistant := func(content provider.Content) []provider.Completion {
+		return []provider.Completion{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{content}}}}
+	}
+
+	child, err := New("child-model",
+		WithCompleter(&mockCompleter{
+			responses: [][]provider.Completion{
+				assistant(provider.Content{ToolCall: &provider.ToolCall{ID: "tc-search", Name: "search", Arguments: `{}`}}),
+				assistant(provider.Content{Text: "found it"}),
+			},
+		}),
+		WithTools(toolFunc{
+			tools: []provider.Tool{{Name: "search"}},
+			execute: func(ctx context.Context, name string, params map[string]any) (any, error) {
+				return "result", nil
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	// delegate runs the child agent like an agent tool would
+	delegate := toolFunc{
+		tools: []provider.Tool{{Name: "delegate"}},
+		execute: func(ctx context.Context, name string, params map[string]any) (any, error) {
+			return accumulateCompletion(child.Complete(ctx, []provider.Message{provider.UserMessage("find it")}, nil))
+		},
+	}
+
+	var events []ToolEvent
+
+	parent, err := New("parent-model",
+		WithCompleter(&mockCompleter{
+			responses: [][]provider.Completion{
+				assistant(provider.Content{ToolCall: &provider.ToolCall{ID: "tc-delegate", Name: "delegate", Arguments: `{}`}}),
+				assistant(provider.Content{Text: "done"}),
+			},
+		}),
+		WithTools(delegate),
+		WithToolObserver(func(ctx context.Context, e ToolEvent) {
+			events = append(events, e)
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = accumulateCompletion(parent.Complete(context.Background(), nil, nil))
+	require.NoError(t, err)
+
+	type seen struct {
+		Phase ToolPhase
+		Name  string
+		Depth int
+	}
+
+	var got []seen
+
+	for _, e := range events {
+		got = append(got, seen{e.Phase, e.Name, e.Depth})
+	}
+
+	require.Equal(t, []seen{
+		{ToolPhaseStart, "delegate", 0},
+		{ToolPhaseStart, "search", 1},
+		{ToolPhaseResult, "search", 1},
+		{ToolPhaseResult, "delegate", 0},
+	}, got)
+}
+
 type resulterToolProvider struct {
 	mockToolProvider
 }
@@ -1406,3 +1484,723 @@ func TestComplete_Resulter(t *testing.T) {
 	}
 	require.True(t, sawRendered, "expected Resulter output to be fed back to the model")
 }
+
+// =============================================================================
+// TestComplete_Limits - Turn, token and loop limits end the run gracefully
+// =============================================================================
+
+func TestComplete_Limits(t *testing.T) {
+	toolTurn := func(id string, usage *provider.Usage) []provider.Completion {
+		return []provider.Completion{
+			{
+				Message: &provider.Message{
+					Role: provider.MessageRoleAssistant,
+					Content: []provider.Content{
+						{ToolCall: &provider.ToolCall{ID: id, Name: "search", Arguments: `{"query": "same"}`}},
+					},
+				},
+				Usage: usage,
+			},
+		}
+	}
+
+	finalTurn := []provider.Completion{
+		{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{{Text: "Best effort answer"}},
+			},
+		},
+	}
+
+	tools := func() *mockToolProvider {
+		return &mockToolProvider{
+			tools: []provider.Tool{{Name: "search", Description: "Search"}},
+		}
+	}
+
+	t.Run("max turns forces a final answer without tools", func(t *testing.T) {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{
+				toolTurn("call-1", nil),
+				toolTurn("call-2", nil),
+				finalTurn,
+			},
+		}
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithTools(tools()),
+			WithLimits(Limits{MaxTurns: 2}),
+		)
+		require.NoError(t, err)
+
+		result, err := accumulateCompletion(chain.Complete(context.Background(), nil, nil))
+		require.NoError(t, err)
+
+		require.Equal(t, 3, completer.callCount)
+		require.Equal(t, provider.ToolChoiceNone, completer.capturedOptions[2].ToolOptions.Choice)
+
+		require.Equal(t, "Best effort answer", result.Message.Text())
+		require.Equal(t, provider.CompletionStatusIncomplete, result.Status)
+		require.Equal(t, provider.StopReasonAgentLimit, result.StopReason)
+		require.Equal(t, "max_turns", result.StopDetails.Category)
+	})
+
+	t.Run("max tokens counts usage over all turns", func(t *testing.T) {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{
+				toolTurn("call-1", &provider.Usage{InputTokens: 600, OutputTokens: 50}),
+				toolTurn("call-2", &provider.Usage{InputTokens: 700, OutputTokens: 50}),
+				finalTurn,
+			},
+		}
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithTools(tools()),
+			WithLimits(Limits{MaxTokens: 1000}),
+		)
+		require.NoError(t, err)
+
+		result, err := accumulateCompletion(chain.Complete(context.Background(), nil, nil))
+		require.NoError(t, err)
+
+		require.Equal(t, 3, completer.callCount)
+		require.Equal(t, "max_tokens", result.StopDetails.Category)
+	})
+
+	t.Run("repeated identical calls are not executed", func(t *testing.T) {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{
+				toolTurn("call-1", nil),
+				toolTurn("call-2", nil),
+				finalTurn,
+			},
+		}
+
+		toolProvider := tools()
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithTools(toolProvider),
+			WithLimits(Limits{MaxRepeatedCalls: 1}),
+		)
+		require.NoError(t, err)
+
+		result, err := accumulateCompletion(chain.Complete(context.Background(), nil, nil))
+		require.NoError(t, err)
+
+		require.Len(t, toolProvider.executeCalls, 1)
+		require.Equal(t, "tool_loop", result.StopDetails.Category)
+		require.Equal(t, provider.CompletionStatusIncomplete, result.Status)
+	})
+
+	t.Run("distinct calls still run after a repeated call", func(t *testing.T) {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{
+				toolTurn("call-1", nil),
+				{
+					{
+						Message: &provider.Message{
+							Role: provider.MessageRoleAssistant,
+							Content: []provider.Content{
+								{ToolCall: &provider.ToolCall{ID: "call-2", Name: "search", Arguments: `{"query": "same"}`}},
+								{ToolCall: &provider.ToolCall{ID: "call-3", Name: "search", Arguments: `{"query": "other"}`}},
+							},
+						},
+					},
+				},
+				finalTurn,
+			},
+		}
+
+		toolProvider := tools()
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithTools(toolProvider),
+			WithLimits(Limits{MaxRepeatedCalls: 1}),
+		)
+		require.NoError(t, err)
+
+		result, err := accumulateCompletion(chain.Complete(context.Background(), nil, nil))
+		require.NoError(t, err)
+
+		require.Len(t, toolProvider.executeCalls, 2)
+		require.Equal(t, "other", toolProvider.executeCalls[1].Params["query"])
+		require.Equal(t, "tool_loop", result.StopDetails.Category)
+	})
+
+	t.Run("runs within limits complete normally", func(t *testing.T) {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{
+				toolTurn("call-1", nil),
+				finalTurn,
+			},
+		}
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithTools(tools()),
+			WithLimits(Limits{MaxTurns: 5, MaxRepeatedCalls: 2}),
+		)
+		require.NoError(t, err)
+
+		result, err := accumulateCompletion(chain.Complete(context.Background(), nil, nil))
+		require.NoError(t, err)
+
+		require.NotEqual(t, provider.CompletionStatusIncomplete, result.Status)
+		require.Empty(t, result.StopReason)
+	})
+}
+
+// =============================================================================
+// TestComplete_ParallelToolCalls - Agent tool calls of a turn run concurrently
+// =============================================================================
+
+func TestComplete_ParallelToolCalls(t *testing.T) {
+	turn := []provider.Completion{
+		{
+			Message: &provider.Message{
+				Role: provider.MessageRoleAssistant,
+				Content: []provider.Content{
+					{ToolCall: &provider.ToolCall{ID: "call-1", Name: "search", Arguments: `{"query": "a"}`}},
+					{ToolCall: &provider.ToolCall{ID: "call-2", Name: "search", Arguments: `{"query": "b"}`}},
+					{ToolCall: &provider.ToolCall{ID: "call-3", Name: "search", Arguments: `{"query": "c"}`}},
+				},
+			},
+		},
+	}
+
+	final := []provider.Completion{
+		{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{{Text: "Done"}},
+			},
+		},
+	}
+
+	t.Run("runs calls concurrently up to the limit and keeps call order", func(t *testing.T) {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{turn, final},
+		}
+
+		var running, peak atomic.Int32
+
+		// The first call finishes last, so completion order differs from call order
+		tools := toolFunc{
+			tools: []provider.Tool{{Name: "search", Description: "Search"}},
+			execute: func(ctx context.Context, name string, params map[string]any) (any, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					p := peak.Load()
+
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+
+				if params["query"] == "a" {
+					time.Sleep(50 * time.Millisecond)
+				} else {
+					time.Sleep(10 * time.Millisecond)
+				}
+
+				return map[string]any{"query": params["query"]}, nil
+			},
+		}
+
+		var mu sync.Mutex
+		var events []ToolEvent
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithTools(tools),
+			WithParallelToolCalls(2),
+			WithToolObserver(func(ctx context.Context, event ToolEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				events = append(events, event)
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = collectCompletions(chain.Complete(context.Background(), nil, nil))
+		require.NoError(t, err)
+
+		require.False(t, completer.capturedOptions[0].ToolOptions.DisableParallelToolCalls)
+		require.Equal(t, int32(2), peak.Load())
+
+		// assistant turn followed by the results in call order
+		second := completer.capturedMessages[1]
+		require.Len(t, second, 4)
+
+		for i, id := range []string{"call-1", "call-2", "call-3"} {
+			require.Equal(t, id, second[i+1].Content[0].ToolResult.ID)
+		}
+
+		require.Len(t, events, 6)
+	})
+
+	t.Run("stays serial when the caller brings tools", func(t *testing.T) {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{final},
+		}
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithTools(&mockToolProvider{tools: []provider.Tool{{Name: "search"}}}),
+			WithParallelToolCalls(4),
+		)
+		require.NoError(t, err)
+
+		options := &provider.CompleteOptions{
+			Tools: []provider.Tool{{Name: "caller_tool"}},
+		}
+
+		_, err = collectCompletions(chain.Complete(context.Background(), nil, options))
+		require.NoError(t, err)
+
+		require.True(t, completer.capturedOptions[0].ToolOptions.DisableParallelToolCalls)
+	})
+}
+
+// toolFunc is a tool provider safe for concurrent use
+type toolFunc struct {
+	tools   []provider.Tool
+	execute func(ctx context.Context, name string, params map[string]any) (any, error)
+}
+
+func (f toolFunc) Tools(ctx context.Context) ([]provider.Tool, error) {
+	return f.tools, nil
+}
+
+func (f toolFunc) Execute(ctx context.Context, name string, params map[string]any) (any, error) {
+	return f.execute(ctx, name, params)
+}
+
+// =============================================================================
+// TestComplete_Approval - Sensitive tools wait for the caller's decision
+// =============================================================================
+
+func TestComplete_Approval(t *testing.T) {
+	approvalCall := provider.ToolCall{ID: "call-1", Name: "create_issue", Arguments: `{"title": "Bug"}`}
+
+	final := []provider.Completion{
+		{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{{Text: "Done"}},
+			},
+		},
+	}
+
+	tools := func() *mockToolProvider {
+		return &mockToolProvider{
+			tools: []provider.Tool{{Name: "create_issue", Description: "Create an issue"}},
+		}
+	}
+
+	t.Run("surfaces the call as an approval request", func(t *testing.T) {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{
+				{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{{ToolCall: &approvalCall}}}}},
+			},
+		}
+
+		toolProvider := tools()
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithApprovalTools(toolProvider),
+		)
+		require.NoError(t, err)
+
+		result, err := accumulateCompletion(chain.Complete(context.Background(), []provider.Message{provider.UserMessage("file a bug")}, nil))
+		require.NoError(t, err)
+
+		require.Equal(t, 1, completer.callCount)
+		require.Empty(t, toolProvider.executeCalls)
+
+		calls := result.Message.ToolCalls()
+		require.Len(t, calls, 1)
+		require.Equal(t, provider.ToolKindApproval, calls[0].Kind)
+		require.Equal(t, "create_issue", calls[0].Name)
+	})
+
+	history := func(result provider.ToolResult) []provider.Message {
+		request := approvalCall
+		request.Kind = provider.ToolKindApproval
+
+		return []provider.Message{
+			provider.UserMessage("file a bug"),
+			{Role: provider.MessageRoleAssistant, Content: []provider.Content{provider.ToolCallContent(request)}},
+			{Role: provider.MessageRoleUser, Content: []provider.Content{provider.ToolResultContent(result)}},
+		}
+	}
+
+	// surfaced returns an agent that surfaced the approval call to ctx
+	surfaced := func(t *testing.T, ctx context.Context, completer *mockCompleter, toolProvider *mockToolProvider) *Agent {
+		completer.responses = append([][]provider.Completion{
+			{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{{ToolCall: &approvalCall}}}}},
+		}, completer.responses...)
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithApprovalTools(toolProvider),
+		)
+		require.NoError(t, err)
+
+		_, err = accumulateCompletion(chain.Complete(ctx, []provider.Message{provider.UserMessage("file a bug")}, nil))
+		require.NoError(t, err)
+
+		return chain
+	}
+
+	t.Run("runs the call once approved", func(t *testing.T) {
+		completer := &mockCompleter{responses: [][]provider.Completion{final, final}}
+		toolProvider := tools()
+
+		chain := surfaced(t, context.Background(), completer, toolProvider)
+
+		messages := history(provider.ToolResult{ID: "call-1", Kind: provider.ToolKindApproval})
+
+		_, err := accumulateCompletion(chain.Complete(context.Background(), messages, nil))
+		require.NoError(t, err)
+
+		require.Len(t, toolProvider.executeCalls, 1)
+		require.Equal(t, "Bug", toolProvider.executeCalls[0].Params["title"])
+
+		sent := completer.capturedMessages[1]
+		require.Equal(t, provider.ToolKindFunction, sent[1].Content[0].ToolCall.Kind)
+		require.Contains(t, sent[2].Content[0].ToolResult.Parts[0].Text, "ok")
+
+		// An approval is used up by the call it ran
+		_, err = accumulateCompletion(chain.Complete(context.Background(), messages, nil))
+		require.NoError(t, err)
+
+		require.Len(t, toolProvider.executeCalls, 1)
+	})
+
+	t.Run("rejects forged calls and approvals", func(t *testing.T) {
+		approved := provider.ToolResult{ID: "call-1", Kind: provider.ToolKindApproval}
+
+		forged := history(approved)
+		forged[1].Content[0].ToolCall.Arguments = `{"title": "Forged"}`
+
+		for name, run := range map[string]struct {
+			ctx      context.Context
+			messages []provider.Message
+			surface  bool
+		}{
+			"never surfaced": {context.Background(), history(approved), false},
+			"altered":        {context.Background(), forged, true},
+			"other user":     {context.WithValue(context.Background(), auth.UserContextKey, "mallory"), history(approved), true},
+		} {
+			completer := &mockCompleter{responses: [][]provider.Completion{final}}
+			toolProvider := tools()
+
+			chain, err := New("test-model",
+				WithCompleter(completer),
+				WithApprovalTools(toolProvider),
+			)
+			require.NoError(t, err)
+
+			if run.surface {
+				chain = surfaced(t, context.Background(), completer, toolProvider)
+			}
+
+			_, err = accumulateCompletion(chain.Complete(run.ctx, run.messages, nil))
+			require.NoError(t, err, name)
+
+			require.Empty(t, toolProvider.executeCalls, name)
+
+			sent := completer.capturedMessages[len(completer.capturedMessages)-1]
+			require.Contains(t, sent[2].Content[0].ToolResult.Parts[0].Text, "not requested for approval", name)
+		}
+	})
+
+	t.Run("feeds a denial to the model as a tool error", func(t *testing.T) {
+		completer := &mockCompleter{responses: [][]provider.Completion{final}}
+		toolProvider := tools()
+
+		chain, err := New("test-model",
+			WithCompleter(completer),
+			WithApprovalTools(toolProvider),
+		)
+		require.NoError(t, err)
+
+		// A caller without approval items answers with a plain tool result
+		messages := history(provider.ToolResult{ID: "call-1", Parts: []provider.Part{{Text: "wrong project"}}})
+		messages[1].Content[0].ToolCall.Kind = provider.ToolKindFunction
+
+		_, err = accumulateCompletion(chain.Complete(context.Background(), messages, nil))
+		require.NoError(t, err)
+
+		require.Empty(t, toolProvider.executeCalls)
+
+		text := completer.capturedMessages[0][2].Content[0].ToolResult.Parts[0].Text
+		require.Equal(t, "Error: the user denied this tool call: wrong project", text)
+	})
+}
+
+func TestComplete_Memory(t *testing.T) {
+	store, err := file.New(filepath.Join(t.TempDir(), "memories.json"))
+	require.NoError(t, err)
+
+	_, err = store.Remember(context.Background(), "alice", "Prefers answers in German")
+	require.NoError(t, err)
+
+	completer := &mockCompleter{
+		responses: [][]provider.Completion{
+			{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{{Text: "Hallo"}}}}},
+		},
+	}
+
+	chain, err := New("test-model",
+		WithCompleter(completer),
+		WithMessages(provider.SystemMessage("Known about the user:{{ range .memories }}\n- {{ .Content }}{{ end }}")),
+		WithMemory(store),
+	)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), auth.UserContextKey, "alice")
+
+	_, err = accumulateCompletion(chain.Complete(ctx, []provider.Message{provider.UserMessage("Which answers does she prefer?")}, nil))
+	require.NoError(t, err)
+
+	require.Equal(t, "Known about the user:\n- Prefers answers in German", completer.capturedMessages[0][0].Text())
+
+	var names []string
+
+	for _, tool := range completer.capturedOptions[0].Tools {
+		names = append(names, tool.Name)
+	}
+
+	require.ElementsMatch(t, []string{"remember", "recall", "forget"}, names)
+}
+
+type summarizerFunc func(ctx context.Context, text string, options *summarizer.SummarizeOptions) (*summarizer.Summary, error)
+
+func (f summarizerFunc) Summarize(ctx context.Context, text string, options *summarizer.SummarizeOptions) (*summarizer.Summary, error) {
+	return f(ctx, text, options)
+}
+
+func TestComplete_Compaction(t *testing.T) {
+	call := func(id string) []provider.Completion {
+		return []provider.Completion{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{
+			{ToolCall: &provider.ToolCall{ID: id, Name: "lookup", Arguments: `{"id":"` + id + `"}`}},
+		}}}}
+	}
+
+	completer := &mockCompleter{
+		responses: [][]provider.Completion{
+			call("call-1"),
+			call("call-2"),
+			call("call-3"),
+			{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{{Text: "Done"}}}}},
+		},
+	}
+
+	var transcripts []string
+
+	chain, err := New("test-model",
+		WithCompleter(completer),
+		WithMessages(provider.SystemMessage("You are a helper.")),
+		WithTools(toolFunc{
+			tools: []provider.Tool{{Name: "lookup"}},
+			execute: func(ctx context.Context, name string, params map[string]any) (any, error) {
+				return "record " + params["id"].(string), nil
+			},
+		}),
+		WithCompaction(Compaction{
+			Threshold: 1,
+			KeepTurns: 1,
+
+			Summarizer: summarizerFunc(func(ctx context.Context, text string, options *summarizer.SummarizeOptions) (*summarizer.Summary, error) {
+				transcripts = append(transcripts, text)
+				return &summarizer.Summary{Text: fmt.Sprintf("summary %d", len(transcripts))}, nil
+			}),
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = accumulateCompletion(chain.Complete(context.Background(), []provider.Message{provider.UserMessage("Look up all records")}, nil))
+	require.NoError(t, err)
+
+	require.Len(t, completer.capturedMessages, 4)
+
+	// nothing older than the only turn to summarize yet
+	require.Len(t, completer.capturedMessages[1], 4)
+
+	for _, messages := range completer.capturedMessages[2:] {
+		require.Len(t, messages, 4)
+
+		require.Equal(t, provider.MessageRoleSystem, messages[0].Role)
+		require.Contains(t, messages[1].Text(), "<summary>")
+
+		// the kept turn starts with the call its result answers
+		calls := messages[2].ToolCalls()
+		require.Len(t, calls, 1)
+
+		result, ok := messages[3].ToolResult()
+		require.True(t, ok)
+		require.Equal(t, calls[0].ID, result.ID)
+	}
+
+	require.Len(t, transcripts, 2)
+	require.Contains(t, transcripts[0], "Look up all records")
+	require.Contains(t, transcripts[0], "record call-1")
+	require.NotContains(t, transcripts[0], "You are a helper.")
+
+	// the second compaction folds the first summary into the new one
+	require.Contains(t, transcripts[1], "summary 1")
+	require.Contains(t, transcripts[1], "record call-2")
+	require.Contains(t, completer.capturedMessages[3][1].Text(), "summary 2")
+}
+
+func TestComplete_ToolProgress(t *testing.T) {
+	newAgent := func(options ...Option) *Agent {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{
+				{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{
+					{ToolCall: &provider.ToolCall{ID: "tc-1", Name: "lookup", Arguments: `{"id":"42"}`}},
+				}}}},
+				{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{{Text: "done"}}}}},
+			},
+		}
+
+		chain, err := New("test-model", append([]Option{
+			WithCompleter(completer),
+			WithTools(toolFunc{
+				tools: []provider.Tool{{Name: "lookup"}},
+				execute: func(ctx context.Context, name string, params map[string]any) (any, error) {
+					return nil, errors.New("not found")
+				},
+			}),
+		}, options...)...)
+		require.NoError(t, err)
+
+		return chain
+	}
+
+	run := func(chain *Agent) []agent.ToolProgress {
+		var reported []agent.ToolProgress
+
+		ctx := agent.WithProgressReporter(context.Background(), func(ctx context.Context, p agent.ToolProgress) {
+			reported = append(reported, p)
+		})
+
+		_, err := accumulateCompletion(chain.Complete(ctx, nil, nil))
+		require.NoError(t, err)
+
+		return reported
+	}
+
+	t.Run("reports calls when enabled", func(t *testing.T) {
+		reported := run(newAgent(WithToolProgress()))
+
+		require.Equal(t, []agent.ToolProgress{
+			{ID: "tc-1", Name: "lookup", Status: agent.ToolStatusInProgress, Arguments: `{"id":"42"}`},
+			{ID: "tc-1", Name: "lookup", Status: agent.ToolStatusFailed, Arguments: `{"id":"42"}`, Error: "not found"},
+		}, reported)
+	})
+
+	t.Run("stays silent by default", func(t *testing.T) {
+		require.Empty(t, run(newAgent()))
+	})
+
+	t.Run("traces calls regardless", func(t *testing.T) {
+		var traced []agent.ToolProgress
+
+		ctx := agent.WithToolTrace(context.Background(), func(ctx context.Context, p agent.ToolProgress) {
+			traced = append(traced, p)
+		})
+
+		_, err := accumulateCompletion(newAgent().Complete(ctx, nil, nil))
+		require.NoError(t, err)
+
+		require.Len(t, traced, 2)
+		require.Equal(t, agent.ToolStatusFailed, traced[1].Status)
+	})
+}
+
+func TestComplete_ToolProgressMessages(t *testing.T) {
+	completer := &mockCompleter{
+		responses: [][]provider.Completion{
+			{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{
+				{ToolCall: &provider.ToolCall{ID: "tc-1", Name: "build", Arguments: `{}`}},
+			}}}},
+			{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{{Text: "done"}}}}},
+		},
+	}
+
+	chain, err := New("test-model",
+		WithCompleter(completer),
+		WithToolProgress(),
+		WithTools(toolFunc{
+			tools: []provider.Tool{{Name: "build"}},
+			execute: func(ctx context.Context, name string, params map[string]any) (any, error) {
+				tool.ReportProgress(ctx, "compiling")
+				return "ok", nil
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	var reported []agent.ToolProgress
+
+	ctx := agent.WithProgressReporter(context.Background(), func(ctx context.Context, p agent.ToolProgress) {
+		reported = append(reported, p)
+	})
+
+	_, err = accumulateCompletion(chain.Complete(ctx, nil, nil))
+	require.NoError(t, err)
+
+	require.Len(t, reported, 3)
+	require.Equal(t, agent.ToolStatusInProgress, reported[1].Status)
+	require.Equal(t, "compiling", reported[1].Output)
+	require.Equal(t, agent.ToolStatusCompleted, reported[2].Status)
+}
+
+func TestComplete_Session(t *testing.T) {
+	run := func(ctx context.Context) []string {
+		completer := &mockCompleter{
+			responses: [][]provider.Completion{
+				{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{
+					{ToolCall: &provider.ToolCall{ID: "tc-1", Name: "lookup", Arguments: `{}`}},
+					{ToolCall: &provider.ToolCall{ID: "tc-2", Name: "lookup", Arguments: `{"id":"2"}`}},
+				}}}},
+				{{Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{{Text: "done"}}}}},
+			},
+		}
+
+		var sessions []string
+
+		chain, err := New("test-model", WithCompleter(completer), WithTools(toolFunc{
+			tools: []provider.Tool{{Name: "lookup"}},
+			execute: func(ctx context.Context, name string, params map[string]any) (any, error) {
+				sessions = append(sessions, agent.Session(ctx))
+				return "ok", nil
+			},
+		}))
+		require.NoError(t, err)
+
+		_, err = accumulateCompletion(chain.Complete(ctx, nil, nil))
+		require.NoError(t, err)
+
+		return sessions
+	}
+
+	sessions := run(context.Background())
+	require.Len(t, sessions, 2)
+	require.NotEmpty(t, sessions[0])
+	require.Equal(t, sessions[0], sessions[1])
+
+	require.Equal(t, []string{"s1", "s1"}, run(agent.WithSession(context.Background(), "s1")))
+}
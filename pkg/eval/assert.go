@@ -0,0 +1,173 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// assert checks the output and tool calls of a case against its
+// expectations and returns the failures; rubrics are graded separately
+func assert(expect Expect, output string, calls []ToolCall) []string {
+	var failures []string
+
+	for _, e := range expect.ToolCalls {
+		if !called(e, calls) {
+			failures = append(failures, fmt.Sprintf("expected tool call %s%s", e.Name, formatArguments(e.Arguments)))
+		}
+	}
+
+	for _, s := range expect.Contains {
+		if !strings.Contains(output, s) {
+			failures = append(failures, fmt.Sprintf("output does not contain %q", s))
+		}
+	}
+
+	for _, s := range expect.NotContains {
+		if strings.Contains(output, s) {
+			failures = append(failures, fmt.Sprintf("output contains %q", s))
+		}
+	}
+
+	for _, p := range expect.Regex {
+		if !regexp.MustCompile(p).MatchString(output) {
+			failures = append(failures, fmt.Sprintf("output does not match /%s/", p))
+		}
+	}
+
+	if len(expect.JSON) > 0 {
+		var document any
+
+		if err := json.Unmarshal([]byte(output), &document); err != nil {
+			return append(failures, "output is not JSON: "+err.Error())
+		}
+
+		for _, e := range expect.JSON {
+			if failure := assertJSON(e, document); failure != "" {
+				failures = append(failures, failure)
+			}
+		}
+	}
+
+	return failures
+}
+
+func assertJSON(e JSONExpect, document any) string {
+	path, _ := parsePath(e.Path)
+	value, found := lookup(document, path)
+
+	if e.Exists != nil && !*e.Exists {
+		if found {
+			return fmt.Sprintf("%s exists", e.Path)
+		}
+
+		return ""
+	}
+
+	if !found {
+		return fmt.Sprintf("%s does not exist", e.Path)
+	}
+
+	if e.Equals != nil && !reflect.DeepEqual(normalize(e.Equals), value) {
+		return fmt.Sprintf("%s is %s, expected %s", e.Path, formatValue(value), formatValue(e.Equals))
+	}
+
+	if e.Matches != "" {
+		text, ok := value.(string)
+
+		if !ok {
+			text = formatValue(value)
+		}
+
+		if !regexp.MustCompile(e.Matches).MatchString(text) {
+			return fmt.Sprintf("%s is %s, does not match /%s/", e.Path, formatValue(value), e.Matches)
+		}
+	}
+
+	return ""
+}
+
+func called(e ToolCallExpect, calls []ToolCall) bool {
+	for _, c := range calls {
+		if c.Name != e.Name {
+			continue
+		}
+
+		var arguments map[string]any
+
+		if c.Arguments != "" {
+			json.Unmarshal([]byte(c.Arguments), &arguments)
+		}
+
+		if contains(arguments, normalize(e.Arguments)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contains reports whether expected is a subset of actual; nested objects
+// are compared as subsets too
+func contains(actual, expected any) bool {
+	switch expected := expected.(type) {
+	case map[string]any:
+		actual, ok := actual.(map[string]any)
+
+		if !ok {
+			return len(expected) == 0
+		}
+
+		for k, v := range expected {
+			if !contains(actual[k], v) {
+				return false
+			}
+		}
+
+		return true
+
+	case nil:
+		return true
+
+	default:
+		return reflect.DeepEqual(actual, expected)
+	}
+}
+
+// normalize converts a YAML value to its decoded JSON form, so numbers and
+// nested objects compare equal to values parsed from JSON
+func normalize(value any) any {
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return value
+	}
+
+	var result any
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return value
+	}
+
+	return result
+}
+
+func formatValue(value any) string {
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+func formatArguments(arguments map[string]any) string {
+	if len(arguments) == 0 {
+		return ""
+	}
+
+	return " with " + formatValue(arguments)
+}
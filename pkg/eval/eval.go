@@ -0,0 +1,228 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// Resolver returns the completer of an agent or model id
+type Resolver func(id string) (provider.Completer, error)
+
+// Runner runs suites in process against the completers of a loaded config
+type Runner struct {
+	resolve Resolver
+
+	model string
+	judge string
+}
+
+type Option func(*Runner)
+
+func New(resolve Resolver, options ...Option) *Runner {
+	r := &Runner{
+		resolve: resolve,
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// WithModel runs all suites against the given agent or model id instead of
+// their own
+func WithModel(model string) Option {
+	return func(r *Runner) {
+		r.model = model
+	}
+}
+
+// WithJudge grades rubrics with the given model id instead of the suites'
+// judge
+func WithJudge(judge string) Option {
+	return func(r *Runner) {
+		r.judge = judge
+	}
+}
+
+// ToolCall is a tool call made while running a case
+type ToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"`
+
+	// Depth is greater than 0 for calls of agents delegated to
+	Depth int `json:"depth,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Run runs the cases of the suite one after another. Failing cases are
+// recorded in the report; an error is only returned when the suite cannot
+// run at all.
+func (r *Runner) Run(ctx context.Context, suite *Suite) (*Report, error) {
+	model := suite.Model
+
+	if r.model != "" {
+		model = r.model
+	}
+
+	if model == "" {
+		return nil, errors.New(suite.Name + ": missing model")
+	}
+
+	completer, err := r.resolve(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var judge provider.Completer
+
+	if id := r.judgeModel(suite); id != "" {
+		if judge, err = r.resolve(id); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &Report{
+		Suite: suite.Name,
+		Model: model,
+	}
+
+	started := time.Now()
+
+	for _, c := range suite.Cases {
+		result := r.runCase(ctx, suite, c, completer, judge)
+		report.add(result)
+	}
+
+	report.Duration = Duration(time.Since(started))
+
+	return report, nil
+}
+
+func (r *Runner) judgeModel(suite *Suite) string {
+	if r.judge != "" {
+		return r.judge
+	}
+
+	return suite.Judge
+}
+
+func (r *Runner) runCase(ctx context.Context, suite *Suite, c Case, completer, judgeCompleter provider.Completer) CaseResult {
+	result := CaseResult{
+		Name: c.Name,
+	}
+
+	if suite.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, suite.timeout)
+		defer cancel()
+	}
+
+	messages := caseMessages(c)
+
+	var mu sync.Mutex
+
+	ctx = agent.WithToolTrace(ctx, func(ctx context.Context, p agent.ToolProgress) {
+		if p.Status == agent.ToolStatusInProgress {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			Name:      p.Name,
+			Arguments: p.Arguments,
+			Depth:     p.Depth,
+			Error:     p.Error,
+		})
+	})
+
+	started := time.Now()
+
+	acc := provider.CompletionAccumulator{}
+
+	for completion, err := range completer.Complete(ctx, messages, nil) {
+		if err != nil {
+			result.Latency = Duration(time.Since(started))
+			result.Error = err.Error()
+
+			return result
+		}
+
+		acc.Add(*completion)
+	}
+
+	result.Latency = Duration(time.Since(started))
+
+	completion := acc.Result()
+
+	if completion.Usage != nil {
+		result.Usage = Usage{
+			InputTokens:  completion.Usage.InputTokens,
+			OutputTokens: completion.Usage.OutputTokens,
+		}
+	}
+
+	if completion.Message != nil {
+		result.Output = completion.Message.Text()
+
+		// Calls left to the caller are part of the behavior too
+		for _, call := range completion.Message.ToolCalls() {
+			result.ToolCalls = append(result.ToolCalls, ToolCall{
+				Name:      call.Name,
+				Arguments: call.Arguments,
+			})
+		}
+	}
+
+	result.Failures = assert(c.Expect, result.Output, result.ToolCalls)
+
+	if c.Expect.Rubric != "" {
+		v, err := judge(ctx, judgeCompleter, messages, result.Output, c.Expect.Rubric)
+
+		if err != nil {
+			result.Error = fmt.Sprintf("judge: %s", err)
+			return result
+		}
+
+		if !v.Pass {
+			result.Failures = append(result.Failures, "rubric: "+v.Reason)
+		}
+	}
+
+	result.Passed = len(result.Failures) == 0
+
+	return result
+}
+
+func caseMessages(c Case) []provider.Message {
+	if c.Input != "" {
+		return []provider.Message{provider.UserMessage(c.Input)}
+	}
+
+	var messages []provider.Message
+
+	for _, m := range c.Messages {
+		switch m.Role {
+		case "system":
+			messages = append(messages, provider.SystemMessage(m.Content))
+		case "assistant":
+			messages = append(messages, provider.AssistantMessage(m.Content))
+		default:
+			messages = append(messages, provider.UserMessage(m.Content))
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,271 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// completerFunc answers with the text returned by fn
+type completerFunc func(ctx context.Context, messages []provider.Message) (string, error)
+
+func (f completerFunc) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		text, err := f(ctx, messages)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		yield(&provider.Completion{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{provider.TextContent(text)},
+			},
+
+			Usage: &provider.Usage{InputTokens: 100, OutputTokens: 10},
+		}, nil)
+	}
+}
+
+func writeSuite(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "suite.yaml")
+
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestRun(t *testing.T) {
+	suite, err := Load(writeSuite(t, `
+name: orders
+model: support
+judge: grader
+
+cases:
+  - name: lookup
+    input: Where is order 42?
+    expect:
+      tool_calls:
+        - name: lookup_order
+          arguments:
+            id: 42
+      contains: ["shipped"]
+      regex: ["order\\s+42"]
+      rubric: Mentions the shipping state
+
+  - name: status json
+    messages:
+      - role: system
+        content: Reply in JSON
+      - role: user
+        content: Status of order 7?
+    expect:
+      json:
+        - path: $.order.id
+          equals: 7
+        - path: $.order.items[0]
+          matches: "^chair"
+        - path: $.error
+          exists: false
+
+  - name: wrong tool
+    input: Cancel order 9
+    expect:
+      tool_calls:
+        - name: cancel_order
+      not_contains: ["cannot"]
+
+  - name: broken
+    input: fail
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	support := completerFunc(func(ctx context.Context, messages []provider.Message) (string, error) {
+		trace := agent.ToolTrace(ctx)
+
+		switch messages[len(messages)-1].Text() {
+		case "Where is order 42?":
+			trace(ctx, agent.ToolProgress{Name: "lookup_order", Status: agent.ToolStatusInProgress, Arguments: `{"id":42}`})
+			trace(ctx, agent.ToolProgress{Name: "lookup_order", Status: agent.ToolStatusCompleted, Arguments: `{"id":42,"verbose":true}`})
+			return "Your order 42 has shipped.", nil
+
+		case "Status of order 7?":
+			return `{"order":{"id":7,"items":["chair","table"]}}`, nil
+
+		case "Cancel order 9":
+			trace(ctx, agent.ToolProgress{Name: "lookup_order", Status: agent.ToolStatusCompleted, Arguments: `{"id":9}`})
+			return "I cannot cancel order 9.", nil
+
+		default:
+			return "", errors.New("upstream unavailable")
+		}
+	})
+
+	var graded []string
+
+	grader := completerFunc(func(ctx context.Context, messages []provider.Message) (string, error) {
+		graded = append(graded, messages[1].Text())
+		return `{"pass":true,"reason":"mentions shipping"}`, nil
+	})
+
+	runner := New(func(id string) (provider.Completer, error) {
+		switch id {
+		case "support":
+			return support, nil
+		case "grader":
+			return grader, nil
+		}
+
+		return nil, errors.New("not found: " + id)
+	})
+
+	report, err := runner.Run(context.Background(), suite)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Passed != 2 || report.Failed != 2 || report.PassRate != 0.5 {
+		t.Fatalf("expected 2 of 4 passed, got %d passed %d failed (%v)", report.Passed, report.Failed, report.PassRate)
+	}
+
+	if report.Usage.InputTokens != 300 {
+		t.Fatalf("expected usage of 3 completed cases, got %d", report.Usage.InputTokens)
+	}
+
+	if len(report.Cases[0].ToolCalls) != 1 {
+		t.Fatalf("expected finished tool calls only, got %+v", report.Cases[0].ToolCalls)
+	}
+
+	if len(graded) != 1 || !strings.Contains(graded[0], "<reply>\nYour order 42 has shipped.\n</reply>") {
+		t.Fatalf("expected the reply to be graded once, got %q", graded)
+	}
+
+	wrong := report.Cases[2]
+
+	if wrong.Passed || len(wrong.Failures) != 2 || wrong.Failures[0] != "expected tool call cancel_order" {
+		t.Fatalf("unexpected failures: %q", wrong.Failures)
+	}
+
+	if broken := report.Cases[3]; broken.Passed || broken.Error != "upstream unavailable" {
+		t.Fatalf("expected an error, got %+v", broken)
+	}
+
+	var junit bytes.Buffer
+
+	if err := WriteJUnit(&junit, []*Report{report}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		`<testsuites tests="4" failures="1" errors="1"`,
+		`<testsuite name="orders" tests="4" failures="1" errors="1"`,
+		`<property name="pass_rate" value="0.5000"></property>`,
+		`<failure message="expected tool call cancel_order">`,
+		`<error message="upstream unavailable">`,
+	} {
+		if !strings.Contains(junit.String(), s) {
+			t.Fatalf("junit report misses %s:\n%s", s, junit.String())
+		}
+	}
+}
+
+func TestRubricFailure(t *testing.T) {
+	suite := &Suite{
+		Name:  "tone",
+		Model: "m",
+		Judge: "j",
+
+		Cases: []Case{{Name: "polite", Input: "hi", Expect: Expect{Rubric: "Is polite"}}},
+	}
+
+	runner := New(func(id string) (provider.Completer, error) {
+		if id == "j" {
+			return completerFunc(func(ctx context.Context, messages []provider.Message) (string, error) {
+				return `{"pass":false,"reason":"rude"}`, nil
+			}), nil
+		}
+
+		return completerFunc(func(ctx context.Context, messages []provider.Message) (string, error) {
+			return "go away", nil
+		}), nil
+	}, WithModel("m"))
+
+	report, err := runner.Run(context.Background(), suite)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := report.Cases[0]; c.Passed || len(c.Failures) != 1 || c.Failures[0] != "rubric: rude" {
+		t.Fatalf("expected rubric failure, got %+v", c)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no cases":       "name: x\nmodel: m\n",
+		"no input":       "cases:\n  - name: a\n",
+		"rubric":         "cases:\n  - input: hi\n    expect:\n      rubric: ok\n",
+		"regex":          "cases:\n  - input: hi\n    expect:\n      regex: ['(']\n",
+		"path":           "cases:\n  - input: hi\n    expect:\n      json:\n        - path: $.a[x]\n",
+		"unknown field":  "cases:\n  - input: hi\n    expected: {}\n",
+		"duplicate case": "cases:\n  - {name: a, input: hi}\n  - {name: a, input: ho}\n",
+	} {
+		if _, err := Load(writeSuite(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	document := map[string]any{
+		"a": map[string]any{
+			"b c": []any{"x", map[string]any{"d": 1.0}},
+		},
+	}
+
+	for path, expected := range map[string]any{
+		"$":              document,
+		"$.a['b c'][0]":  "x",
+		"a['b c'][1].d":  1.0,
+		"$.a['b c'][2]":  nil,
+		"$.a.missing[0]": nil,
+	} {
+		segments, err := parsePath(path)
+
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+
+		value, found := lookup(document, segments)
+
+		if expected == nil {
+			if found {
+				t.Errorf("%s: expected no value, got %v", path, value)
+			}
+
+			continue
+		}
+
+		if !found || formatValue(value) != formatValue(expected) {
+			t.Errorf("%s: expected %v, got %v", path, expected, value)
+		}
+	}
+}
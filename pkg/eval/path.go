@@ -0,0 +1,107 @@
+package eval
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// segment is an object key or, for index >= 0, an array element
+type segment struct {
+	key   string
+	index int
+}
+
+// parsePath parses the JSON path subset $.key.key[0]['key'], where the
+// leading $ is optional
+func parsePath(path string) ([]segment, error) {
+	invalid := errors.New("invalid json path: " + path)
+
+	p := strings.TrimPrefix(path, "$")
+
+	var segments []segment
+
+	for p != "" {
+		switch {
+		case strings.HasPrefix(p, "['"):
+			end := strings.Index(p, "']")
+
+			if end < 0 {
+				return nil, invalid
+			}
+
+			segments = append(segments, segment{key: p[2:end], index: -1})
+			p = p[end+2:]
+
+		case p[0] == '[':
+			end := strings.IndexByte(p, ']')
+
+			if end < 0 {
+				return nil, invalid
+			}
+
+			index, err := strconv.Atoi(p[1:end])
+
+			if err != nil || index < 0 {
+				return nil, invalid
+			}
+
+			segments = append(segments, segment{index: index})
+			p = p[end+1:]
+
+		case p[0] == '.':
+			p = p[1:]
+
+			end := strings.IndexAny(p, ".[")
+
+			if end < 0 {
+				end = len(p)
+			}
+
+			if end == 0 {
+				return nil, invalid
+			}
+
+			segments = append(segments, segment{key: p[:end], index: -1})
+			p = p[end:]
+
+		default:
+			if len(segments) > 0 || path != p {
+				return nil, invalid
+			}
+
+			// a bare first key without the leading dot
+			p = "." + p
+		}
+	}
+
+	return segments, nil
+}
+
+// lookup returns the value at path in a decoded JSON document
+func lookup(value any, path []segment) (any, bool) {
+	for _, s := range path {
+		if s.index >= 0 {
+			items, ok := value.([]any)
+
+			if !ok || s.index >= len(items) {
+				return nil, false
+			}
+
+			value = items[s.index]
+			continue
+		}
+
+		object, ok := value.(map[string]any)
+
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = object[s.key]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
@@ -0,0 +1,208 @@
+package eval
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Report struct {
+	Suite string `json:"suite"`
+	Model string `json:"model"`
+
+	Cases []CaseResult `json:"cases"`
+
+	Passed   int     `json:"passed"`
+	Failed   int     `json:"failed"`
+	PassRate float64 `json:"pass_rate"`
+
+	Usage Usage `json:"usage"`
+
+	Duration Duration `json:"duration_ms"`
+
+	AverageLatency Duration `json:"average_latency_ms"`
+	MaxLatency     Duration `json:"max_latency_ms"`
+}
+
+type CaseResult struct {
+	Name string `json:"name"`
+
+	Passed bool `json:"passed"`
+
+	// Failures are the expectations not met; Error is set when the case
+	// could not run to the end
+	Failures []string `json:"failures,omitempty"`
+	Error    string   `json:"error,omitempty"`
+
+	Output    string     `json:"output"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	Usage   Usage    `json:"usage"`
+	Latency Duration `json:"latency_ms"`
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Duration is encoded in JSON as milliseconds
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(time.Duration(d).Milliseconds(), 10)), nil
+}
+
+func (r *Report) add(result CaseResult) {
+	r.Cases = append(r.Cases, result)
+
+	if result.Passed {
+		r.Passed++
+	} else {
+		r.Failed++
+	}
+
+	r.PassRate = float64(r.Passed) / float64(len(r.Cases))
+
+	r.Usage.InputTokens += result.Usage.InputTokens
+	r.Usage.OutputTokens += result.Usage.OutputTokens
+
+	var total time.Duration
+
+	for _, c := range r.Cases {
+		total += time.Duration(c.Latency)
+	}
+
+	r.AverageLatency = Duration(total / time.Duration(len(r.Cases)))
+	r.MaxLatency = max(r.MaxLatency, result.Latency)
+}
+
+// WriteJSON writes the reports as a JSON array
+func WriteJSON(w io.Writer, reports []*Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(reports)
+}
+
+type junitSuites struct {
+	XMLName xml.Name `xml:"testsuites"`
+
+	Tests    int    `xml:"tests,attr"`
+	Failures int    `xml:"failures,attr"`
+	Errors   int    `xml:"errors,attr"`
+	Time     string `xml:"time,attr"`
+
+	Suites []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string `xml:"name,attr"`
+	Tests    int    `xml:"tests,attr"`
+	Failures int    `xml:"failures,attr"`
+	Errors   int    `xml:"errors,attr"`
+	Time     string `xml:"time,attr"`
+
+	Properties []junitProperty `xml:"properties>property"`
+
+	Cases []junitCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string `xml:"name,attr"`
+	Classname string `xml:"classname,attr"`
+	Time      string `xml:"time,attr"`
+
+	Failure *junitMessage `xml:"failure,omitempty"`
+	Error   *junitMessage `xml:"error,omitempty"`
+
+	SystemOut string `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the reports as JUnit XML, one test suite per report
+func WriteJUnit(w io.Writer, reports []*Report) error {
+	var result junitSuites
+
+	var total time.Duration
+
+	for _, r := range reports {
+		suite := junitSuite{
+			Name:  r.Suite,
+			Tests: len(r.Cases),
+			Time:  seconds(r.Duration),
+
+			Properties: []junitProperty{
+				{Name: "model", Value: r.Model},
+				{Name: "pass_rate", Value: strconv.FormatFloat(r.PassRate, 'f', 4, 64)},
+				{Name: "input_tokens", Value: strconv.Itoa(r.Usage.InputTokens)},
+				{Name: "output_tokens", Value: strconv.Itoa(r.Usage.OutputTokens)},
+				{Name: "average_latency_ms", Value: strconv.FormatInt(time.Duration(r.AverageLatency).Milliseconds(), 10)},
+				{Name: "max_latency_ms", Value: strconv.FormatInt(time.Duration(r.MaxLatency).Milliseconds(), 10)},
+			},
+		}
+
+		for _, c := range r.Cases {
+			tc := junitCase{
+				Name:      c.Name,
+				Classname: r.Suite,
+				Time:      seconds(c.Latency),
+
+				SystemOut: c.Output,
+			}
+
+			switch {
+			case c.Error != "":
+				suite.Errors++
+				tc.Error = &junitMessage{Message: c.Error, Text: c.Error}
+
+			case !c.Passed:
+				suite.Failures++
+				tc.Failure = &junitMessage{Message: c.Failures[0], Text: strings.Join(c.Failures, "\n")}
+			}
+
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		result.Tests += suite.Tests
+		result.Failures += suite.Failures
+		result.Errors += suite.Errors
+
+		total += time.Duration(r.Duration)
+
+		result.Suites = append(result.Suites, suite)
+	}
+
+	result.Time = seconds(Duration(total))
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(result); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func seconds(d Duration) string {
+	return strconv.FormatFloat(time.Duration(d).Seconds(), 'f', 3, 64)
+}
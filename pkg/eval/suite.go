@@ -0,0 +1,183 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// Suite is a set of cases run against one agent or model
+type Suite struct {
+	Name string `yaml:"name"`
+
+	// Model is the agent or model id the cases run against
+	Model string `yaml:"model"`
+
+	// Judge is the model id that grades rubrics
+	Judge string `yaml:"judge"`
+
+	// Timeout bounds each case, e.g. "2m"
+	Timeout string `yaml:"timeout"`
+
+	Cases []Case `yaml:"cases"`
+
+	timeout time.Duration
+}
+
+type Case struct {
+	Name string `yaml:"name"`
+
+	// Input is a single user message; Messages a whole conversation
+	Input    string    `yaml:"input"`
+	Messages []Message `yaml:"messages"`
+
+	Expect Expect `yaml:"expect"`
+}
+
+type Message struct {
+	Role    string `yaml:"role"`
+	Content string `yaml:"content"`
+}
+
+type Expect struct {
+	// ToolCalls must each be made at least once, by the agent or returned
+	// to the caller
+	ToolCalls []ToolCallExpect `yaml:"tool_calls"`
+
+	// Contains and NotContains are substrings of the output
+	Contains    []string `yaml:"contains"`
+	NotContains []string `yaml:"not_contains"`
+
+	// Regex are patterns the output must match
+	Regex []string `yaml:"regex"`
+
+	// JSON are assertions on the output parsed as JSON
+	JSON []JSONExpect `yaml:"json"`
+
+	// Rubric is graded by the suite's judge
+	Rubric string `yaml:"rubric"`
+}
+
+type ToolCallExpect struct {
+	Name string `yaml:"name"`
+
+	// Arguments must be contained in the call's arguments
+	Arguments map[string]any `yaml:"arguments"`
+}
+
+type JSONExpect struct {
+	// Path selects a value, e.g. $.items[0].name
+	Path string `yaml:"path"`
+
+	Equals  any    `yaml:"equals"`
+	Matches string `yaml:"matches"`
+
+	// Exists checks for the presence (default) or absence of the value
+	Exists *bool `yaml:"exists"`
+}
+
+// Load reads and checks a suite file
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var suite Suite
+
+	if err := yaml.Load(data, &suite, yaml.WithKnownFields()); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if suite.Name == "" {
+		suite.Name = path
+	}
+
+	if err := suite.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &suite, nil
+}
+
+func (s *Suite) validate() error {
+	if len(s.Cases) == 0 {
+		return errors.New("no cases")
+	}
+
+	if s.Timeout != "" {
+		timeout, err := time.ParseDuration(s.Timeout)
+
+		if err != nil || timeout <= 0 {
+			return errors.New("invalid timeout: " + s.Timeout)
+		}
+
+		s.timeout = timeout
+	}
+
+	names := map[string]bool{}
+
+	for i, c := range s.Cases {
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("case %d", i+1)
+			s.Cases[i].Name = c.Name
+		}
+
+		if names[c.Name] {
+			return errors.New("duplicate case: " + c.Name)
+		}
+
+		names[c.Name] = true
+
+		if c.Input == "" && len(c.Messages) == 0 {
+			return errors.New(c.Name + ": missing input or messages")
+		}
+
+		if c.Input != "" && len(c.Messages) > 0 {
+			return errors.New(c.Name + ": input and messages are exclusive")
+		}
+
+		for _, m := range c.Messages {
+			switch m.Role {
+			case "system", "user", "assistant":
+			default:
+				return errors.New(c.Name + ": invalid message role: " + m.Role)
+			}
+		}
+
+		for _, p := range c.Expect.Regex {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("%s: invalid regex: %w", c.Name, err)
+			}
+		}
+
+		for _, e := range c.Expect.JSON {
+			if _, err := parsePath(e.Path); err != nil {
+				return fmt.Errorf("%s: %w", c.Name, err)
+			}
+
+			if e.Matches != "" {
+				if _, err := regexp.Compile(e.Matches); err != nil {
+					return fmt.Errorf("%s: invalid regex: %w", c.Name, err)
+				}
+			}
+		}
+
+		for _, t := range c.Expect.ToolCalls {
+			if t.Name == "" {
+				return errors.New(c.Name + ": tool call without name")
+			}
+		}
+
+		if c.Expect.Rubric != "" && s.Judge == "" {
+			return errors.New(c.Name + ": rubric requires a judge")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package limiter
+
+import (
+	"context"
+	"iter"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Completer = (*Completer)(nil)
+
+// Completer holds a concurrency slot of the limiter for the whole stream of
+// each request
+type Completer struct {
+	limiter   *Limiter
+	completer provider.Completer
+}
+
+func NewCompleter(l *Limiter, completer provider.Completer) *Completer {
+	return &Completer{
+		limiter:   l,
+		completer: completer,
+	}
+}
+
+func (c *Completer) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		release, err := c.limiter.Acquire(ctx)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		defer release()
+
+		for completion, err := range c.completer.Complete(ctx, messages, options) {
+			if !yield(completion, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package limiter caps the number of concurrent requests to a backend. Excess
+// requests wait in a bounded queue, where interactive requests are always
+// admitted before batch requests.
+package limiter
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/otel"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// Priority is the queueing class of a request. Lower values are admitted first.
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota
+	PriorityBatch
+)
+
+var priorities = []Priority{PriorityInteractive, PriorityBatch}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityBatch:
+		return "batch"
+	default:
+		return "interactive"
+	}
+}
+
+// ParsePriority resolves a priority class by name ("interactive", "batch")
+func ParsePriority(s string) (Priority, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "interactive":
+		return PriorityInteractive, true
+
+	case "batch":
+		return PriorityBatch, true
+	}
+
+	return PriorityInteractive, false
+}
+
+type contextKey struct{}
+
+// WithPriority returns a context carrying the request's priority class
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PriorityFromContext returns the request's priority class, interactive when
+// none was set
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(contextKey{}).(Priority); ok {
+		return p
+	}
+
+	return PriorityInteractive
+}
+
+var (
+	ErrQueueFull    = errors.New("concurrency limit reached and queue is full")
+	ErrQueueTimeout = errors.New("timed out waiting for a concurrency slot")
+)
+
+// IsLimitError reports whether the error is a request turned away by a
+// limiter. It says nothing about the backend's health.
+func IsLimitError(err error) bool {
+	return errors.Is(err, ErrQueueFull) || errors.Is(err, ErrQueueTimeout)
+}
+
+// Limiter admits up to a fixed number of concurrent requests. Further
+// requests wait in a queue bounded in length and wait time.
+type Limiter struct {
+	mu sync.Mutex
+
+	limit  int
+	active int
+
+	maxQueue int
+	timeout  time.Duration
+
+	queues map[Priority]*list.List
+
+	metrics *otel.QueueMetrics
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// New creates a limiter admitting limit concurrent requests, with up to
+// maxQueue waiting requests. A request gives up after waiting for timeout;
+// zero waits until the caller goes away.
+func New(name string, limit, maxQueue int, timeout time.Duration) (*Limiter, error) {
+	if limit <= 0 {
+		return nil, errors.New("concurrency limit must be positive")
+	}
+
+	if maxQueue < 0 {
+		return nil, errors.New("queue size must not be negative")
+	}
+
+	l := &Limiter{
+		limit: limit,
+
+		maxQueue: maxQueue,
+		timeout:  timeout,
+
+		queues: make(map[Priority]*list.List, len(priorities)),
+
+		metrics: otel.NewQueueMetrics(name),
+	}
+
+	for _, p := range priorities {
+		l.queues[p] = list.New()
+	}
+
+	return l, nil
+}
+
+// Acquire claims a concurrency slot, waiting in the queue if none is free.
+// The returned release function must be called exactly once when the request
+// is done. Waiting ends early when ctx is canceled.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	priority := PriorityFromContext(ctx)
+
+	l.mu.Lock()
+
+	if l.active < l.limit && l.queued() == 0 {
+		l.active++
+		l.mu.Unlock()
+
+		return l.releaser(), nil
+	}
+
+	if l.queued() >= l.maxQueue {
+		l.mu.Unlock()
+
+		l.metrics.Dequeued(context.WithoutCancel(ctx), priority.String(), false, 0, "rejected")
+
+		return nil, &provider.ProviderError{
+			Code:       http.StatusTooManyRequests,
+			Type:       "queue_full",
+			Message:    ErrQueueFull.Error(),
+			RetryAfter: time.Second,
+			Err:        ErrQueueFull,
+		}
+	}
+
+	w := &waiter{
+		ready: make(chan struct{}),
+	}
+
+	queue := l.queues[priority]
+	element := queue.PushBack(w)
+
+	l.mu.Unlock()
+
+	start := time.Now()
+	l.metrics.Enqueued(ctx, priority.String())
+
+	var timeout <-chan time.Time
+
+	if l.timeout > 0 {
+		timer := time.NewTimer(l.timeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	var err error
+	var outcome string
+
+	select {
+	case <-w.ready:
+		l.metrics.Dequeued(ctx, priority.String(), true, time.Since(start), "admitted")
+		return l.releaser(), nil
+
+	case <-ctx.Done():
+		err = ctx.Err()
+		outcome = "canceled"
+
+	case <-timeout:
+		err = &provider.ProviderError{
+			Code:       http.StatusTooManyRequests,
+			Type:       "queue_timeout",
+			Message:    ErrQueueTimeout.Error(),
+			RetryAfter: time.Second,
+			Err:        ErrQueueTimeout,
+		}
+
+		outcome = "timeout"
+	}
+
+	l.mu.Lock()
+
+	if w.granted {
+		// The slot was handed over while giving up: pass it on
+		l.active--
+		l.grant()
+	} else {
+		queue.Remove(element)
+	}
+
+	l.mu.Unlock()
+
+	l.metrics.Dequeued(context.WithoutCancel(ctx), priority.String(), true, time.Since(start), outcome)
+
+	return nil, err
+}
+
+// releaser returns an idempotent function freeing one slot
+func (l *Limiter) releaser() func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			l.active--
+			l.grant()
+		})
+	}
+}
+
+// grant admits waiting requests in priority order while slots are free. Must
+// be called with the mutex held.
+func (l *Limiter) grant() {
+	for l.active < l.limit {
+		w := l.next()
+
+		if w == nil {
+			return
+		}
+
+		w.granted = true
+		close(w.ready)
+
+		l.active++
+	}
+}
+
+// next pops the oldest waiter of the highest non-empty priority. Must be
+// called with the mutex held.
+func (l *Limiter) next() *waiter {
+	for _, p := range priorities {
+		queue := l.queues[p]
+
+		if front := queue.Front(); front != nil {
+			queue.Remove(front)
+			return front.Value.(*waiter)
+		}
+	}
+
+	return nil
+}
+
+// queued returns the number of waiting requests. Must be called with the
+// mutex held.
+func (l *Limiter) queued() int {
+	var n int
+
+	for _, queue := range l.queues {
+		n += queue.Len()
+	}
+
+	return n
+}
@@ -0,0 +1,151 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	t.Run("admits up to the limit", func(t *testing.T) {
+		l, _ := New("test", 2, 0, 0)
+
+		r1, err := l.Acquire(context.Background())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r2, err := l.Acquire(context.Background())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := l.Acquire(context.Background()); !errors.Is(err, ErrQueueFull) {
+			t.Fatalf("expected ErrQueueFull without a queue, got %v", err)
+		}
+
+		r1()
+		r1() // releasing twice must not free a second slot
+
+		r3, err := l.Acquire(context.Background())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := l.Acquire(context.Background()); !errors.Is(err, ErrQueueFull) {
+			t.Fatalf("expected ErrQueueFull after double release, got %v", err)
+		}
+
+		r2()
+		r3()
+	})
+
+	t.Run("interactive is admitted before batch", func(t *testing.T) {
+		l, _ := New("test", 1, 2, 0)
+
+		release, _ := l.Acquire(context.Background())
+
+		order := make(chan Priority, 2)
+
+		enqueue := func(p Priority) {
+			r, err := l.Acquire(WithPriority(context.Background(), p))
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			order <- p
+			r()
+		}
+
+		go enqueue(PriorityBatch)
+		waitQueued(t, l, 1)
+
+		go enqueue(PriorityInteractive)
+		waitQueued(t, l, 2)
+
+		release()
+
+		if first := <-order; first != PriorityInteractive {
+			t.Errorf("expected interactive first, got %s", first)
+		}
+
+		if second := <-order; second != PriorityBatch {
+			t.Errorf("expected batch second, got %s", second)
+		}
+	})
+
+	t.Run("queue timeout", func(t *testing.T) {
+		l, _ := New("test", 1, 1, 20*time.Millisecond)
+
+		release, _ := l.Acquire(context.Background())
+		defer release()
+
+		if _, err := l.Acquire(context.Background()); !errors.Is(err, ErrQueueTimeout) {
+			t.Fatalf("expected ErrQueueTimeout, got %v", err)
+		}
+
+		if n := queued(l); n != 0 {
+			t.Errorf("expected timed out request to leave the queue, got %d", n)
+		}
+	})
+
+	t.Run("canceled while queued", func(t *testing.T) {
+		l, _ := New("test", 1, 1, 0)
+
+		release, _ := l.Acquire(context.Background())
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan error)
+
+		go func() {
+			_, err := l.Acquire(ctx)
+			done <- err
+		}()
+
+		waitQueued(t, l, 1)
+		cancel()
+
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+
+		release()
+
+		// The canceled request must not hold the slot
+		r, err := l.Acquire(context.Background())
+
+		if err != nil {
+			t.Fatalf("expected a free slot, got %v", err)
+		}
+
+		r()
+	})
+}
+
+func queued(l *Limiter) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.queued()
+}
+
+func waitQueued(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for queued(l) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d queued requests", n)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
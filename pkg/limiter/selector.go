@@ -0,0 +1,42 @@
+package limiter
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+)
+
+// DefaultPriorityHeader is the request header selecting the priority class
+const DefaultPriorityHeader = "X-Priority"
+
+// Selector derives the priority class of a request. Callers in one of the
+// batch groups are always queued as batch; anyone else may opt into batch
+// with the priority header. The header can only lower the priority, so batch
+// callers cannot jump the queue.
+type Selector struct {
+	Header      string
+	BatchGroups []string
+}
+
+func (s *Selector) Select(r *http.Request) Priority {
+	if groups, ok := r.Context().Value(auth.GroupsContextKey).([]string); ok {
+		for _, g := range groups {
+			if slices.Contains(s.BatchGroups, g) {
+				return PriorityBatch
+			}
+		}
+	}
+
+	header := s.Header
+
+	if header == "" {
+		header = DefaultPriorityHeader
+	}
+
+	if p, ok := ParsePriority(r.Header.Get(header)); ok {
+		return p
+	}
+
+	return PriorityInteractive
+}
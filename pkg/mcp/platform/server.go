@@ -0,0 +1,165 @@
+// Package platform serves an MCP server publishing every configured
+// capability, such as extractors, translators and agents, as tools. Each
+// caller only sees the capabilities the policy grants them access to.
+package platform
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/extractor"
+	mcppkg "github.com/adrianliechti/wingman/pkg/mcp"
+	"github.com/adrianliechti/wingman/pkg/policy"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/researcher"
+	"github.com/adrianliechti/wingman/pkg/scraper"
+	"github.com/adrianliechti/wingman/pkg/searcher"
+	"github.com/adrianliechti/wingman/pkg/translator"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var _ mcppkg.Provider = (*Server)(nil)
+
+// Capabilities are the providers the server publishes, by id
+type Capabilities struct {
+	Extractors  map[string]extractor.Provider
+	Translators map[string]translator.Provider
+
+	Searchers   map[string]searcher.Provider
+	Scrapers    map[string]scraper.Provider
+	Researchers map[string]researcher.Provider
+
+	Renderers    map[string]provider.Renderer
+	Transcribers map[string]provider.Transcriber
+
+	Agents map[string]provider.Completer
+}
+
+type Server struct {
+	http.Handler
+
+	name         string
+	instructions string
+
+	capabilities Capabilities
+
+	policy policy.Provider
+}
+
+func New(name, instructions string, capabilities Capabilities, policy policy.Provider) (*Server, error) {
+	s := &Server{
+		name:         name,
+		instructions: instructions,
+
+		capabilities: capabilities,
+
+		policy: policy,
+	}
+
+	// The tools depend on the caller, so each request gets a server of its
+	// own
+	s.Handler = mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		return s.server(r.Context())
+	}, &mcp.StreamableHTTPOptions{
+		Stateless: true,
+	})
+
+	return s, nil
+}
+
+func (s *Server) Icon() (string, []byte) {
+	return "", nil
+}
+
+// server returns an MCP server with the tools of the capabilities the caller
+// may use
+func (s *Server) server(ctx context.Context) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{
+		Name:    s.name,
+		Version: "1.0.0",
+	}, &mcp.ServerOptions{
+		Instructions: s.instructions,
+
+		KeepAlive: time.Second * 30,
+	})
+
+	c := s.capabilities
+
+	for _, id := range allowed(ctx, s.policy, c.Extractors) {
+		addExtractor(server, s.policy, id, c.Extractors[id])
+	}
+
+	for _, id := range allowed(ctx, s.policy, c.Translators) {
+		addTranslator(server, s.policy, id, c.Translators[id])
+	}
+
+	for _, id := range allowed(ctx, s.policy, c.Searchers) {
+		addSearcher(server, s.policy, id, c.Searchers[id])
+	}
+
+	for _, id := range allowed(ctx, s.policy, c.Scrapers) {
+		addScraper(server, s.policy, id, c.Scrapers[id])
+	}
+
+	for _, id := range allowed(ctx, s.policy, c.Researchers) {
+		addResearcher(server, s.policy, id, c.Researchers[id])
+	}
+
+	for _, id := range allowed(ctx, s.policy, c.Renderers) {
+		addRenderer(server, s.policy, id, c.Renderers[id])
+	}
+
+	for _, id := range allowed(ctx, s.policy, c.Transcribers) {
+		addTranscriber(server, s.policy, id, c.Transcribers[id])
+	}
+
+	for _, id := range allowed(ctx, s.policy, c.Agents) {
+		addAgent(server, s.policy, id, c.Agents[id])
+	}
+
+	return server
+}
+
+// allowed returns the sorted ids the caller has access to. The empty id of
+// the default provider is left out, it is published under its own id.
+func allowed[T any](ctx context.Context, p policy.Provider, providers map[string]T) []string {
+	var ids []string
+
+	for id := range providers {
+		if id == "" {
+			continue
+		}
+
+		if p != nil && p.Verify(ctx, policy.ResourceModel, id, policy.ActionAccess) != nil {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
+// toolName returns the name of the tool of a capability, such as
+// translate_deepl; characters not valid in tool names are replaced
+func toolName(kind, id string) string {
+	var b strings.Builder
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			b.WriteRune(r)
+
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	return kind + "_" + b.String()
+}
@@ -0,0 +1,194 @@
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"iter"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/policy"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/translator"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type fakeTranslator struct{}
+
+func (fakeTranslator) Translate(ctx context.Context, input translator.Input, options *translator.TranslateOptions) (*translator.File, error) {
+	if input.File != nil {
+		return &translator.File{Name: input.File.Name, Content: []byte(strings.ToUpper(string(input.File.Content))), ContentType: "text/plain"}, nil
+	}
+
+	return &translator.File{Content: []byte(options.Language + ": " + input.Text), ContentType: "text/plain"}, nil
+}
+
+type fakeAgent struct{}
+
+func (fakeAgent) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		yield(&provider.Completion{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{provider.TextContent("re: " + messages[0].Text())},
+			},
+		}, nil)
+	}
+}
+
+// denyPolicy denies access to the listed ids
+type denyPolicy []string
+
+func (p denyPolicy) Verify(ctx context.Context, resource policy.Resource, id string, action policy.Action) error {
+	if slices.Contains(p, id) {
+		return policy.ErrAccessDenied
+	}
+
+	return nil
+}
+
+func connect(t *testing.T, s *Server) *mcp.ClientSession {
+	t.Helper()
+
+	httpServer := httptest.NewServer(s)
+	t.Cleanup(httpServer.Close)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: httpServer.Client(),
+	}, nil)
+
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	t.Cleanup(func() { session.Close() })
+
+	return session
+}
+
+func TestServer(t *testing.T) {
+	s, _ := New("platform", "", Capabilities{
+		Translators: map[string]translator.Provider{"": fakeTranslator{}, "deepl": fakeTranslator{}},
+
+		Agents: map[string]provider.Completer{"assistant": fakeAgent{}, "internal/agent": fakeAgent{}},
+	}, denyPolicy{"internal/agent"})
+
+	session := connect(t, s)
+
+	tools, err := session.ListTools(t.Context(), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+
+	for _, tl := range tools.Tools {
+		names = append(names, tl.Name)
+	}
+
+	if !slices.Equal(names, []string{"agent_assistant", "translate_deepl"}) {
+		t.Fatalf("tools = %v", names)
+	}
+
+	result, err := session.CallTool(t.Context(), &mcp.CallToolParams{
+		Name:      "translate_deepl",
+		Arguments: map[string]any{"language": "de", "text": "hello"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "de: hello" {
+		t.Fatalf("translation = %q", text)
+	}
+
+	result, err = session.CallTool(t.Context(), &mcp.CallToolParams{
+		Name: "translate_deepl",
+		Arguments: map[string]any{"language": "de", "file": map[string]any{
+			"uri":      "notes.txt",
+			"mimeType": "text/plain",
+			"blob":     base64.StdEncoding.EncodeToString([]byte("hello")),
+		}},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource, ok := result.Content[0].(*mcp.EmbeddedResource)
+
+	if !ok || resource.Resource.URI != "file:///notes.txt" || resource.Resource.Text != "HELLO" {
+		t.Fatalf("content = %+v", result.Content[0])
+	}
+
+	result, err = session.CallTool(t.Context(), &mcp.CallToolParams{
+		Name:      "agent_assistant",
+		Arguments: map[string]any{"input": "hi"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "re: hi" {
+		t.Fatalf("answer = %q", text)
+	}
+}
+
+// callPolicy denies calls of the tools of the listed ids, but not access
+type callPolicy []string
+
+func (p callPolicy) Verify(ctx context.Context, resource policy.Resource, id string, action policy.Action) error {
+	if resource == policy.ResourceTool && action == policy.ActionExecute && slices.Contains(p, id) {
+		return policy.ErrAccessDenied
+	}
+
+	return nil
+}
+
+func TestServerVerifiesCalls(t *testing.T) {
+	s, _ := New("platform", "", Capabilities{
+		Translators: map[string]translator.Provider{"deepl": fakeTranslator{}},
+
+		Agents: map[string]provider.Completer{"assistant": fakeAgent{}},
+	}, callPolicy{"deepl"})
+
+	session := connect(t, s)
+
+	result, err := session.CallTool(t.Context(), &mcp.CallToolParams{
+		Name:      "translate_deepl",
+		Arguments: map[string]any{"language": "de", "text": "hello"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "access denied") {
+		t.Fatalf("expected a denied tool error, got %+v", result.Content[0])
+	}
+
+	result, err = session.CallTool(t.Context(), &mcp.CallToolParams{
+		Name:      "agent_assistant",
+		Arguments: map[string]any{"input": "hi"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.IsError {
+		t.Fatalf("expected allowed calls to run, got %+v", result.Content[0])
+	}
+}
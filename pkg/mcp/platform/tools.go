@@ -0,0 +1,356 @@
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/extractor"
+	"github.com/adrianliechti/wingman/pkg/policy"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/researcher"
+	"github.com/adrianliechti/wingman/pkg/scraper"
+	"github.com/adrianliechti/wingman/pkg/searcher"
+	"github.com/adrianliechti/wingman/pkg/translator"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// File is a file passed to a tool, shaped like the contents of an MCP
+// embedded resource
+type File struct {
+	URI      string `json:"uri,omitempty" jsonschema:"name or URI of the file"`
+	MIMEType string `json:"mimeType,omitempty" jsonschema:"content type of the file"`
+
+	Blob string `json:"blob,omitempty" jsonschema:"base64 encoded content"`
+	Text string `json:"text,omitempty" jsonschema:"text content, instead of blob"`
+}
+
+func (f *File) file() (*provider.File, error) {
+	if f == nil || (f.Blob == "" && f.Text == "") {
+		return nil, errors.New("missing file content")
+	}
+
+	data := []byte(f.Text)
+
+	if f.Blob != "" {
+		blob, err := base64.StdEncoding.DecodeString(f.Blob)
+
+		if err != nil {
+			return nil, errors.New("invalid file blob: " + err.Error())
+		}
+
+		data = blob
+	}
+
+	return &provider.File{
+		Name: path.Base(f.URI),
+
+		Content:     data,
+		ContentType: f.MIMEType,
+	}, nil
+}
+
+// fileResult returns a file as an embedded resource, or as an image the
+// client can show
+func fileResult(name string, file *provider.File) *mcp.CallToolResult {
+	if strings.HasPrefix(file.ContentType, "image/") {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.ImageContent{Data: file.Content, MIMEType: file.ContentType}},
+		}
+	}
+
+	resource := &mcp.ResourceContents{
+		URI:      "file:///" + name,
+		MIMEType: file.ContentType,
+	}
+
+	if strings.HasPrefix(file.ContentType, "text/") {
+		resource.Text = string(file.Content)
+	} else {
+		resource.Blob = file.Content
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.EmbeddedResource{Resource: resource}},
+	}
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+}
+
+// addTool adds a tool whose calls the policy verifies before they run; a
+// denied call fails as a tool error
+func addTool[In any](s *mcp.Server, access policy.Provider, id string, t *mcp.Tool, h mcp.ToolHandlerFor[In, any]) {
+	mcp.AddTool(s, t, func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, any, error) {
+		if access != nil {
+			var args map[string]any
+
+			if req.Params != nil {
+				json.Unmarshal(req.Params.Arguments, &args)
+			}
+
+			if err := policy.VerifyToolCall(ctx, access, id, t.Name, args); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return h(ctx, req, in)
+	})
+}
+
+type extractInput struct {
+	File *File `json:"file" jsonschema:"the document to extract"`
+}
+
+func addExtractor(s *mcp.Server, access policy.Provider, id string, p extractor.Provider) {
+	addTool(s, access, id, &mcp.Tool{
+		Name:        toolName("extract", id),
+		Description: "Extract the text of a document (PDF, office documents, images) with " + id + ".",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in extractInput) (*mcp.CallToolResult, any, error) {
+		file, err := in.File.file()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		doc, err := p.Extract(ctx, *file, &extractor.ExtractOptions{})
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return textResult(doc.Text), nil, nil
+	})
+}
+
+type translateInput struct {
+	Language string `json:"language" jsonschema:"target language, such as en or de"`
+
+	Text string `json:"text,omitempty" jsonschema:"the text to translate"`
+	File *File  `json:"file,omitempty" jsonschema:"the document to translate, instead of text"`
+}
+
+func addTranslator(s *mcp.Server, access policy.Provider, id string, p translator.Provider) {
+	addTool(s, access, id, &mcp.Tool{
+		Name:        toolName("translate", id),
+		Description: "Translate a text or document with " + id + ".",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in translateInput) (*mcp.CallToolResult, any, error) {
+		input := translator.Input{
+			Text: in.Text,
+		}
+
+		if in.File != nil {
+			file, err := in.File.file()
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			input.File = file
+		}
+
+		result, err := p.Translate(ctx, input, &translator.TranslateOptions{
+			Language: in.Language,
+		})
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if in.File == nil {
+			return textResult(string(result.Content)), nil, nil
+		}
+
+		name := result.Name
+
+		if name == "" {
+			name = path.Base(in.File.URI)
+		}
+
+		return fileResult(name, result), nil, nil
+	})
+}
+
+type searchInput struct {
+	Query string `json:"query" jsonschema:"the search query"`
+	Limit *int   `json:"limit,omitempty" jsonschema:"maximum number of results"`
+}
+
+func addSearcher(s *mcp.Server, access policy.Provider, id string, p searcher.Provider) {
+	addTool(s, access, id, &mcp.Tool{
+		Name:        toolName("search", id),
+		Description: "Search with " + id + " and return the matching results with their sources.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in searchInput) (*mcp.CallToolResult, any, error) {
+		results, err := p.Search(ctx, in.Query, &searcher.SearchOptions{
+			Limit: in.Limit,
+		})
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		type result struct {
+			Source string `json:"source,omitempty"`
+
+			Title   string `json:"title,omitempty"`
+			Content string `json:"content,omitempty"`
+		}
+
+		var list []result
+
+		for _, r := range results {
+			list = append(list, result{Source: r.Source, Title: r.Title, Content: r.Content})
+		}
+
+		data, _ := json.Marshal(list)
+
+		return textResult(string(data)), nil, nil
+	})
+}
+
+type scrapeInput struct {
+	URL string `json:"url" jsonschema:"the URL of the page"`
+}
+
+func addScraper(s *mcp.Server, access policy.Provider, id string, p scraper.Provider) {
+	addTool(s, access, id, &mcp.Tool{
+		Name:        toolName("scrape", id),
+		Description: "Fetch a web page with " + id + " and return its text.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in scrapeInput) (*mcp.CallToolResult, any, error) {
+		doc, err := p.Scrape(ctx, in.URL, &scraper.ScrapeOptions{})
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return textResult(doc.Text), nil, nil
+	})
+}
+
+type researchInput struct {
+	Instructions string `json:"instructions" jsonschema:"the research question or task"`
+}
+
+func addResearcher(s *mcp.Server, access policy.Provider, id string, p researcher.Provider) {
+	addTool(s, access, id, &mcp.Tool{
+		Name:        toolName("research", id),
+		Description: "Research a question in depth with " + id + " and return a report.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in researchInput) (*mcp.CallToolResult, any, error) {
+		result, err := p.Research(ctx, in.Instructions, &researcher.ResearchOptions{})
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return textResult(result.Content), nil, nil
+	})
+}
+
+type renderInput struct {
+	Prompt string `json:"prompt" jsonschema:"description of the image"`
+
+	Images []*File `json:"images,omitempty" jsonschema:"images to edit or use as reference"`
+}
+
+func addRenderer(s *mcp.Server, access policy.Provider, id string, p provider.Renderer) {
+	addTool(s, access, id, &mcp.Tool{
+		Name:        toolName("render", id),
+		Description: "Generate or edit an image with " + id + ".",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in renderInput) (*mcp.CallToolResult, any, error) {
+		options := &provider.RenderOptions{}
+
+		for _, image := range in.Images {
+			file, err := image.file()
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			options.Images = append(options.Images, *file)
+		}
+
+		rendering, err := p.Render(ctx, in.Prompt, options)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return fileResult(rendering.ID, &provider.File{
+			Content:     rendering.Content,
+			ContentType: rendering.ContentType,
+		}), nil, nil
+	})
+}
+
+type transcribeInput struct {
+	File *File `json:"file" jsonschema:"the audio file"`
+
+	Language string `json:"language,omitempty" jsonschema:"spoken language, if known"`
+}
+
+func addTranscriber(s *mcp.Server, access policy.Provider, id string, p provider.Transcriber) {
+	addTool(s, access, id, &mcp.Tool{
+		Name:        toolName("transcribe", id),
+		Description: "Transcribe an audio file with " + id + ".",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in transcribeInput) (*mcp.CallToolResult, any, error) {
+		file, err := in.File.file()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		options := &provider.TranscribeOptions{}
+
+		if in.Language != "" {
+			options.Languages = []string{in.Language}
+		}
+
+		var acc provider.TranscriptionAccumulator
+
+		for delta, err := range p.Transcribe(ctx, *file, options) {
+			if err != nil {
+				return nil, nil, err
+			}
+
+			acc.Add(*delta)
+		}
+
+		return textResult(acc.Result().Text), nil, nil
+	})
+}
+
+type agentInput struct {
+	Input string `json:"input" jsonschema:"the task or question for the agent"`
+}
+
+func addAgent(s *mcp.Server, access policy.Provider, id string, p provider.Completer) {
+	addTool(s, access, id, &mcp.Tool{
+		Name:        toolName("agent", id),
+		Description: "Ask the " + id + " agent and return its answer.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in agentInput) (*mcp.CallToolResult, any, error) {
+		var acc provider.CompletionAccumulator
+
+		for completion, err := range p.Complete(ctx, []provider.Message{provider.UserMessage(in.Input)}, nil) {
+			if err != nil {
+				return nil, nil, err
+			}
+
+			acc.Add(*completion)
+		}
+
+		var text string
+
+		if result := acc.Result(); result.Message != nil {
+			text = result.Message.Text()
+		}
+
+		return textResult(text), nil, nil
+	})
+}
@@ -0,0 +1,16 @@
+package proxy
+
+import (
+	"github.com/adrianliechti/wingman/pkg/policy"
+)
+
+type Option func(*Server)
+
+// WithPolicy verifies the tool calls passing the proxy as calls of the tool
+// id
+func WithPolicy(policy policy.Provider, id string) Option {
+	return func(s *Server) {
+		s.id = id
+		s.policy = policy
+	}
+}
@@ -0,0 +1,256 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/policy"
+)
+
+// maxMessageSize limits the JSON-RPC messages read to verify tool calls
+const maxMessageSize = 32 << 20
+
+type message struct {
+	ID     json.RawMessage
+	Method string
+
+	// Name and Arguments are those of a tools/call request
+	Name      string
+	Arguments map[string]any
+}
+
+// authorize verifies the tool calls of a request. A denied call is answered
+// with a tool error, so the model sees why it failed, and the request is not
+// forwarded. Bodies that do not strictly decode are rejected, so the policy
+// never verifies a different call than the upstream server runs.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) bool {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessageSize))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return false
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	messages, err := parseMessages(data)
+
+	if err != nil {
+		http.Error(w, "invalid JSON-RPC message: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	for _, m := range messages {
+		if m.Method != "tools/call" {
+			continue
+		}
+
+		err := policy.VerifyToolCall(r.Context(), s.policy, s.id, m.Name, m.Arguments)
+
+		if err == nil {
+			continue
+		}
+
+		// batches are answered as a whole
+		if len(messages) > 1 {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return false
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      m.ID,
+
+			"result": map[string]any{
+				"content": []map[string]any{
+					{"type": "text", "text": err.Error()},
+				},
+
+				"isError": true,
+			},
+		})
+
+		return false
+	}
+
+	return true
+}
+
+// protocolKeys are the members of messages and tool call params the policy
+// reads. Keys differing from them only in case are rejected, as upstream
+// decoders may match them case-insensitively.
+var protocolKeys = []string{"jsonrpc", "id", "method", "params", "result", "error", "name", "arguments"}
+
+// parseMessages decodes a message or a batch of messages. Duplicate keys,
+// case variants of protocol keys and mistyped members are errors.
+func parseMessages(data []byte) ([]message, error) {
+	if err := checkDuplicateKeys(data); err != nil {
+		return nil, err
+	}
+
+	raws := []json.RawMessage{data}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return nil, err
+		}
+
+		if len(raws) == 0 {
+			return nil, errors.New("empty batch")
+		}
+	}
+
+	messages := make([]message, 0, len(raws))
+
+	for _, raw := range raws {
+		m, err := parseMessage(raw)
+
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, m)
+	}
+
+	return messages, nil
+}
+
+func parseMessage(data []byte) (message, error) {
+	fields, err := parseObject(data)
+
+	if err != nil {
+		return message{}, err
+	}
+
+	m := message{
+		ID: fields["id"],
+	}
+
+	if raw, ok := fields["method"]; ok {
+		if err := json.Unmarshal(raw, &m.Method); err != nil {
+			return message{}, fmt.Errorf("invalid method: %w", err)
+		}
+	}
+
+	if m.Method != "tools/call" {
+		return m, nil
+	}
+
+	params, err := parseObject(fields["params"])
+
+	if err != nil {
+		return message{}, fmt.Errorf("invalid params: %w", err)
+	}
+
+	if err := json.Unmarshal(params["name"], &m.Name); err != nil || m.Name == "" {
+		return message{}, errors.New("invalid tool name")
+	}
+
+	if raw, ok := params["arguments"]; ok {
+		if err := json.Unmarshal(raw, &m.Arguments); err != nil {
+			return message{}, fmt.Errorf("invalid tool arguments: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// parseObject decodes a JSON object by its exact keys
+func parseObject(data []byte) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	if fields == nil {
+		return nil, errors.New("expected an object")
+	}
+
+	for key := range fields {
+		for _, k := range protocolKeys {
+			if key != k && strings.EqualFold(key, k) {
+				return nil, fmt.Errorf("ambiguous key %q", key)
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// checkDuplicateKeys rejects objects repeating a key anywhere in data
+func checkDuplicateKeys(data []byte) error {
+	type frame struct {
+		object bool
+		keys   map[string]bool
+
+		// expectKey is set when the next token of an object is a key
+		expectKey bool
+	}
+
+	var stack []*frame
+
+	// valueDone makes an enclosing object expect its next key
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].expectKey = true
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	for {
+		tok, err := dec.Token()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].expectKey {
+			if tok == json.Delim('}') {
+				stack = stack[:n-1]
+				valueDone()
+
+				continue
+			}
+
+			key, _ := tok.(string)
+
+			if stack[n-1].keys[key] {
+				return fmt.Errorf("duplicate key %q", key)
+			}
+
+			stack[n-1].keys[key] = true
+			stack[n-1].expectKey = false
+
+			continue
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{object: true, keys: map[string]bool{}, expectKey: true})
+
+		case json.Delim('['):
+			stack = append(stack, &frame{})
+
+		case json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+
+		default:
+			valueDone()
+		}
+	}
+}
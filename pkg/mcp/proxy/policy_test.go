@@ -0,0 +1,113 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/policy"
+)
+
+type denyTool struct {
+	name string
+}
+
+func (p denyTool) Verify(ctx context.Context, resource policy.Resource, id string, action policy.Action) error {
+	call, _ := policy.ToolCallFromContext(ctx)
+
+	if resource == policy.ResourceTool && id == "github" && call.Name == p.name {
+		return policy.ErrAccessDenied
+	}
+
+	return nil
+}
+
+func TestProxyVerifiesToolCalls(t *testing.T) {
+	var forwarded []string
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m struct {
+			Method string `json:"method"`
+		}
+
+		json.NewDecoder(r.Body).Decode(&m)
+		forwarded = append(forwarded, m.Method)
+	}))
+
+	defer upstream.Close()
+
+	s, err := New(upstream.URL, nil, nil, WithPolicy(denyTool{name: "delete_repository"}, "github"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return rec
+	}
+
+	post(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	post(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"list_issues","arguments":{}}}`)
+
+	rec := post(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"delete_repository","arguments":{"repo":"wingman"}}}`)
+
+	if strings.Join(forwarded, ",") != "tools/list,tools/call" {
+		t.Fatalf("unexpected forwarded requests: %v", forwarded)
+	}
+
+	var resp struct {
+		ID     int `json:"id"`
+		Result struct {
+			IsError bool `json:"isError"`
+		} `json:"result"`
+	}
+
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.ID != 3 || !resp.Result.IsError {
+		t.Fatalf("expected a tool error, got %+v", resp)
+	}
+}
+
+func TestProxyRejectsAmbiguousToolCalls(t *testing.T) {
+	var forwarded int
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded++
+	}))
+
+	defer upstream.Close()
+
+	s, err := New(upstream.URL, nil, nil, WithPolicy(denyTool{name: "delete_repository"}, "github"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"delete_repository","arguments":"wingman"}}`,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_issues","NAME":"delete_repository"}}`,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_issues","name":"delete_repository"}}`,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list","METHOD":"tools/call","params":{"name":"delete_repository"}}`,
+		`[]`,
+		`not json`,
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected %s to be rejected, got status %d", body, rec.Code)
+		}
+	}
+
+	if forwarded != 0 {
+		t.Fatalf("expected no forwarded requests, got %d", forwarded)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/adrianliechti/wingman/pkg/auth"
 	"github.com/adrianliechti/wingman/pkg/mcp"
+	"github.com/adrianliechti/wingman/pkg/policy"
 )
 
 var _ mcp.Provider = (*Server)(nil)
@@ -18,6 +19,9 @@ var _ mcp.Provider = (*Server)(nil)
 type Server struct {
 	url *neturl.URL
 
+	id     string
+	policy policy.Provider
+
 	rt    http.RoundTripper
 	proxy *httputil.ReverseProxy
 
@@ -25,7 +29,7 @@ type Server struct {
 	icon   atomic.Pointer[iconCache]
 }
 
-func New(url string, headers map[string]string, exchanger auth.TokenExchanger) (*Server, error) {
+func New(url string, headers map[string]string, exchanger auth.TokenExchanger, options ...Option) (*Server, error) {
 	u, err := neturl.Parse(url)
 
 	if err != nil {
@@ -44,6 +48,10 @@ func New(url string, headers map[string]string, exchanger auth.TokenExchanger) (
 		rt: rt,
 	}
 
+	for _, option := range options {
+		option(s)
+	}
+
 	s.proxy = &httputil.ReverseProxy{
 		Transport: rt,
 
@@ -71,6 +79,10 @@ func New(url string, headers map[string]string, exchanger auth.TokenExchanger) (
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.policy != nil && r.Method == http.MethodPost && !s.authorize(w, r) {
+		return
+	}
+
 	s.proxy.ServeHTTP(w, r)
 }
 
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/workspace"
+)
+
+type Option func(*Server)
+
+// Prompt is a prompt the server offers, such as the system prompt of an
+// agent
+type Prompt struct {
+	Name        string
+	Description string
+
+	Messages []provider.Message
+}
+
+// WithPrompts offers the prompts to clients
+func WithPrompts(prompts ...Prompt) Option {
+	return func(s *Server) {
+		s.prompts = append(s.prompts, prompts...)
+	}
+}
+
+// WithWorkspace offers the files of the caller's session in the workspace as
+// resources
+func WithWorkspace(workspace workspace.Provider) Option {
+	return func(s *Server) {
+		s.workspace = workspace
+	}
+}
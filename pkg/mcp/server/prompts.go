@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// addPrompts registers the prompts. Prompt messages only have the user and
+// assistant roles, so system messages are offered as user messages.
+func (s *Server) addPrompts() {
+	for _, p := range s.prompts {
+		result := &mcp.GetPromptResult{
+			Description: p.Description,
+		}
+
+		for _, m := range p.Messages {
+			role := mcp.Role("user")
+
+			if m.Role == provider.MessageRoleAssistant {
+				role = "assistant"
+			}
+
+			for _, c := range m.Content {
+				if c.Text == "" {
+					continue
+				}
+
+				result.Messages = append(result.Messages, &mcp.PromptMessage{
+					Role:    role,
+					Content: &mcp.TextContent{Text: c.Text},
+				})
+			}
+		}
+
+		s.server.AddPrompt(&mcp.Prompt{
+			Name:        p.Name,
+			Description: p.Description,
+		}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return result, nil
+		})
+	}
+}
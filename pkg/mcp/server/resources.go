@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/workspace"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const workspaceScheme = "workspace:///"
+
+// addWorkspace offers the files of the workspace as resources. The session
+// of a request (X-Session-Id) selects the workspace; requests without one
+// see no files.
+func (s *Server) addWorkspace() {
+	if s.workspace == nil {
+		return
+	}
+
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "workspace",
+		Description: "Files in the workspace of the session",
+
+		URITemplate: workspaceScheme + "{+path}",
+	}, s.readWorkspace)
+
+	s.server.AddReceivingMiddleware(s.listWorkspace)
+}
+
+func (s *Server) readWorkspace(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	session := agent.Session(ctx)
+
+	name, ok := strings.CutPrefix(uri, workspaceScheme)
+
+	if !ok || session == "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	data, err := s.workspace.Read(ctx, workspace.Key(ctx, session), name)
+
+	if errors.Is(err, workspace.ErrNotFound) || errors.Is(err, workspace.ErrInvalidPath) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	content := &mcp.ResourceContents{
+		URI:      uri,
+		MIMEType: mime.TypeByExtension(path.Ext(name)),
+	}
+
+	if utf8.Valid(data) {
+		content.Text = string(data)
+	} else {
+		content.Blob = data
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{content},
+	}, nil
+}
+
+// listWorkspace adds the files of the session to the last page of the
+// resource list, which the server only fills with static resources
+func (s *Server) listWorkspace(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+
+		if err != nil || method != "resources/list" {
+			return result, err
+		}
+
+		list, ok := result.(*mcp.ListResourcesResult)
+
+		if !ok || list.NextCursor != "" {
+			return result, nil
+		}
+
+		session := agent.Session(ctx)
+
+		if session == "" {
+			return result, nil
+		}
+
+		files, err := s.workspace.List(ctx, workspace.Key(ctx, session))
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range files {
+			list.Resources = append(list.Resources, &mcp.Resource{
+				URI:  workspaceScheme + f.Path,
+				Name: f.Path,
+				Size: f.Size,
+
+				MIMEType: mime.TypeByExtension(path.Ext(f.Path)),
+			})
+		}
+
+		return list, nil
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/workspace"
+	"github.com/adrianliechti/wingman/pkg/workspace/mem"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectSession connects to the server in the session, as the
+// X-Session-Id middleware of the server would
+func connectSession(t *testing.T, s *Server, session string) *mcp.ClientSession {
+	t.Helper()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.ServeHTTP(w, r.WithContext(agent.WithSession(r.Context(), session)))
+	}))
+
+	t.Cleanup(httpServer.Close)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: httpServer.Client(),
+	}, nil)
+
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	t.Cleanup(func() { cs.Close() })
+
+	return cs
+}
+
+func TestPrompts(t *testing.T) {
+	s, err := New("wingman-test", "", nil, WithPrompts(Prompt{
+		Name:        "writer",
+		Description: "system prompt of the writer agent",
+
+		Messages: []provider.Message{
+			{Role: provider.MessageRoleSystem, Content: []provider.Content{provider.TextContent("You write docs.")}},
+		},
+	}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := connectTo(t, s)
+
+	list, err := session.ListPrompts(t.Context(), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list.Prompts) != 1 || list.Prompts[0].Name != "writer" {
+		t.Fatalf("prompts = %+v", list.Prompts)
+	}
+
+	result, err := session.GetPrompt(t.Context(), &mcp.GetPromptParams{Name: "writer"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text, ok := result.Messages[0].Content.(*mcp.TextContent); !ok || text.Text != "You write docs." || result.Messages[0].Role != "user" {
+		t.Fatalf("messages = %+v", result.Messages)
+	}
+}
+
+func TestWorkspaceResources(t *testing.T) {
+	store := mem.New()
+
+	ctx := agent.WithSession(context.Background(), "s1")
+	store.Write(ctx, workspace.Key(ctx, "s1"), "notes/a.md", []byte("# Notes"))
+
+	s, err := New("wingman-test", "", nil, WithWorkspace(store))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := connectSession(t, s, "s1")
+
+	list, err := session.ListResources(t.Context(), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list.Resources) != 1 || list.Resources[0].URI != "workspace:///notes/a.md" {
+		t.Fatalf("resources = %+v", list.Resources)
+	}
+
+	result, err := session.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: "workspace:///notes/a.md"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Contents[0].Text != "# Notes" {
+		t.Fatalf("contents = %+v", result.Contents[0])
+	}
+
+	other := connectSession(t, s, "s2")
+
+	if _, err := other.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: "workspace:///notes/a.md"}); err == nil {
+		t.Fatal("expected files of other sessions to be hidden")
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	mcppkg "github.com/adrianliechti/wingman/pkg/mcp"
+	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/tool"
+	"github.com/adrianliechti/wingman/pkg/workspace"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -21,6 +24,9 @@ type Server struct {
 
 	tools []tool.Provider
 
+	prompts   []Prompt
+	workspace workspace.Provider
+
 	server *mcp.Server
 
 	mu sync.Mutex
@@ -30,7 +36,7 @@ type Server struct {
 	registered []map[string]string
 }
 
-func New(name, instructions string, tools []tool.Provider) (*Server, error) {
+func New(name, instructions string, tools []tool.Provider, options ...Option) (*Server, error) {
 	serverImpl := &mcp.Implementation{
 		Name:    name,
 		Version: "1.0.0",
@@ -61,6 +67,13 @@ func New(name, instructions string, tools []tool.Provider) (*Server, error) {
 		registered: make([]map[string]string, len(tools)),
 	}
 
+	for _, option := range options {
+		option(s)
+	}
+
+	s.addPrompts()
+	s.addWorkspace()
+
 	go s.refresh()
 
 	return s, nil
@@ -139,6 +152,17 @@ func (s *Server) refreshTools() error {
 				case *mcp.CallToolResult:
 					return v, nil
 
+				case provider.ToolResult:
+					return callToolResult(v), nil
+				}
+
+				// Tools rendering their own results, e.g. with files, are
+				// passed on as rendered
+				if r, ok := p.(tool.Resulter); ok {
+					return callToolResult(r.Result(t.Name, result)), nil
+				}
+
+				switch v := result.(type) {
 				case string:
 					return &mcp.CallToolResult{
 						Content: []mcp.Content{
@@ -186,3 +210,41 @@ func (s *Server) refreshTools() error {
 
 	return resultErr
 }
+
+// callToolResult converts a rendered tool result, e.g. of a post-processed
+// tool, into MCP content
+func callToolResult(result provider.ToolResult) *mcp.CallToolResult {
+	var content []mcp.Content
+
+	for _, p := range result.Parts {
+		if p.File == nil {
+			content = append(content, &mcp.TextContent{
+				Text: p.Text,
+			})
+
+			continue
+		}
+
+		if strings.HasPrefix(p.File.ContentType, "image/") {
+			content = append(content, &mcp.ImageContent{
+				Data:     p.File.Content,
+				MIMEType: p.File.ContentType,
+			})
+
+			continue
+		}
+
+		content = append(content, &mcp.EmbeddedResource{
+			Resource: &mcp.ResourceContents{
+				URI:      p.File.Name,
+				MIMEType: p.File.ContentType,
+				Blob:     p.File.Content,
+			},
+		})
+	}
+
+	return &mcp.CallToolResult{
+		Content: content,
+		IsError: result.IsError,
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/tool"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -286,3 +287,72 @@ func TestListAndCallTool(t *testing.T) {
 		t.Errorf("content = %+v", result.Content)
 	}
 }
+
+type resultProvider struct {
+	fakeProvider
+}
+
+func (p *resultProvider) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	return provider.ToolResult{
+		Parts: []provider.Part{
+			{Text: "chart"},
+			{File: &provider.File{Name: "chart.png", Content: []byte("png"), ContentType: "image/png"}},
+		},
+	}, nil
+}
+
+func TestCallToolRendersToolResult(t *testing.T) {
+	session := connectTo(t, newServer(t, "", &resultProvider{fakeProvider{names: []string{"chart"}}}))
+
+	result, err := session.CallTool(t.Context(), &mcp.CallToolParams{
+		Name: "chart",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Content) != 2 {
+		t.Fatalf("content = %+v", result.Content)
+	}
+
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != "chart" {
+		t.Errorf("content[0] = %+v", result.Content[0])
+	}
+
+	if image, ok := result.Content[1].(*mcp.ImageContent); !ok || string(image.Data) != "png" || image.MIMEType != "image/png" {
+		t.Errorf("content[1] = %+v", result.Content[1])
+	}
+}
+
+type resulterProvider struct {
+	fakeProvider
+}
+
+func (p *resulterProvider) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	return []provider.Part{{File: &provider.File{Content: []byte("png"), ContentType: "image/png"}}}, nil
+}
+
+func (p *resulterProvider) Result(name string, value any) provider.ToolResult {
+	return provider.ToolResult{Parts: value.([]provider.Part)}
+}
+
+func TestCallToolRendersThroughResulter(t *testing.T) {
+	session := connectTo(t, newServer(t, "", &resulterProvider{fakeProvider{names: []string{"chart"}}}))
+
+	result, err := session.CallTool(t.Context(), &mcp.CallToolParams{
+		Name: "chart",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Content) != 1 {
+		t.Fatalf("content = %+v", result.Content)
+	}
+
+	if image, ok := result.Content[0].(*mcp.ImageContent); !ok || string(image.Data) != "png" {
+		t.Errorf("content[0] = %+v", result.Content[0])
+	}
+}
@@ -0,0 +1,363 @@
+// Package file stores memories in a local JSON file. With an embedder,
+// memories are recalled by semantic similarity; without one, by the share of
+// query words they contain.
+package file
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/adrianliechti/wingman/pkg/memory"
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"github.com/google/uuid"
+)
+
+var _ memory.Provider = (*Store)(nil)
+
+const (
+	defaultLimit = 10
+
+	defaultMaxMemories = 1000
+)
+
+type Store struct {
+	path string
+
+	embedder provider.Embedder
+
+	maxMemories int
+
+	mu    sync.Mutex
+	users map[string][]entry
+}
+
+type entry struct {
+	memory.Memory
+
+	Embedding []float32 `json:"embedding,omitempty"`
+}
+
+type Option func(*Store)
+
+// WithMaxMemories limits the memories kept per user (default 1000)
+func WithMaxMemories(count int) Option {
+	return func(s *Store) {
+		if count > 0 {
+			s.maxMemories = count
+		}
+	}
+}
+
+// WithEmbedder recalls memories by the similarity of their embeddings
+func WithEmbedder(embedder provider.Embedder) Option {
+	return func(s *Store) {
+		s.embedder = embedder
+	}
+}
+
+// New opens the store at path, creating it on the first write
+func New(path string, options ...Option) (*Store, error) {
+	if path == "" {
+		return nil, errors.New("memory: missing path")
+	}
+
+	s := &Store{
+		path: path,
+
+		maxMemories: defaultMaxMemories,
+
+		users: make(map[string][]entry),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.users); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Store) Remember(ctx context.Context, user, content string) (*memory.Memory, error) {
+	content = strings.TrimSpace(content)
+
+	if content == "" {
+		return nil, errors.New("memory: missing content")
+	}
+
+	e := entry{
+		Memory: memory.Memory{
+			ID: uuid.NewString(),
+
+			Content: content,
+			Created: time.Now().UTC(),
+		},
+	}
+
+	if s.embedder != nil {
+		embedding, err := s.embed(ctx, content)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e.Embedding = embedding
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.users[user]) >= s.maxMemories {
+		return nil, memory.ErrLimitReached
+	}
+
+	s.users[user] = append(s.users[user], e)
+
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+
+	return &e.Memory, nil
+}
+
+// Recall returns the memories most relevant to the query, or the most recent
+// ones for an empty query
+func (s *Store) Recall(ctx context.Context, user, query string, options *memory.RecallOptions) ([]memory.Memory, error) {
+	if options == nil {
+		options = &memory.RecallOptions{}
+	}
+
+	limit := options.Limit
+
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	query = strings.TrimSpace(query)
+
+	var embedding []float32
+
+	if query != "" && s.embedder != nil {
+		var err error
+
+		if embedding, err = s.embed(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	entries := slices.Clone(s.users[user])
+	s.mu.Unlock()
+
+	type match struct {
+		memory.Memory
+		score float64
+	}
+
+	var matches []match
+
+	// newest first, so ties keep preferring recent memories
+	for _, e := range slices.Backward(entries) {
+		m := match{Memory: e.Memory}
+
+		switch {
+		case query == "":
+			m.score = 1
+
+		case embedding != nil && len(e.Embedding) == len(embedding):
+			m.score = cosine(embedding, e.Embedding)
+
+		default:
+			m.score = overlap(query, e.Content)
+		}
+
+		if m.score > 0 {
+			matches = append(matches, m)
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	result := []memory.Memory{}
+
+	for _, m := range matches[:min(limit, len(matches))] {
+		result = append(result, m.Memory)
+	}
+
+	return result, nil
+}
+
+func (s *Store) List(ctx context.Context, user string) ([]memory.Memory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []memory.Memory{}
+
+	for _, e := range s.users[user] {
+		result = append(result, e.Memory)
+	}
+
+	return result, nil
+}
+
+func (s *Store) Forget(ctx context.Context, user, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.users[user]
+
+	i := slices.IndexFunc(entries, func(e entry) bool {
+		return e.ID == id
+	})
+
+	if i < 0 {
+		return memory.ErrNotFound
+	}
+
+	s.users[user] = slices.Delete(entries, i, i+1)
+
+	if len(s.users[user]) == 0 {
+		delete(s.users, user)
+	}
+
+	return s.save()
+}
+
+func (s *Store) Purge(ctx context.Context, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[user]; !ok {
+		return nil
+	}
+
+	delete(s.users, user)
+
+	return s.save()
+}
+
+func (s *Store) embed(ctx context.Context, text string) ([]float32, error) {
+	result, err := s.embedder.Embed(ctx, []string{text}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Embeddings) == 0 {
+		return nil, errors.New("memory: no embedding returned")
+	}
+
+	return result.Embeddings[0], nil
+}
+
+// save writes the store to a temporary file and renames it into place, so a
+// crash never leaves a truncated file behind. Callers hold the lock.
+func (s *Store) save() error {
+	data, err := json.Marshal(s.users)
+
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(s.path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+
+	if na == 0 || nb == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// overlap is the share of the query's words found in the text
+func overlap(query, text string) float64 {
+	terms := words(query)
+
+	if len(terms) == 0 {
+		return 0
+	}
+
+	found := make(map[string]bool)
+
+	for _, w := range words(text) {
+		found[w] = true
+	}
+
+	var hits int
+
+	for _, t := range terms {
+		if found[t] {
+			hits++
+		}
+	}
+
+	return float64(hits) / float64(len(terms))
+}
+
+// words splits text into lower-case words, skipping short ones like
+// articles that would match almost anything
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	return slices.DeleteFunc(fields, func(w string) bool {
+		return utf8.RuneCountInString(w) < 3
+	})
+}
@@ -0,0 +1,163 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/memory"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// fakeEmbedder maps texts to fixed topic vectors: pets, travel, other
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) Embed(ctx context.Context, texts []string, options *provider.EmbedOptions) (*provider.Embedding, error) {
+	var result provider.Embedding
+
+	for _, t := range texts {
+		switch t = strings.ToLower(t); {
+		case strings.Contains(t, "dog") || strings.Contains(t, "pet"):
+			result.Embeddings = append(result.Embeddings, []float32{1, 0, 0})
+		case strings.Contains(t, "japan") || strings.Contains(t, "trip"):
+			result.Embeddings = append(result.Embeddings, []float32{0, 1, 0})
+		default:
+			result.Embeddings = append(result.Embeddings, []float32{0, 0, 1})
+		}
+	}
+
+	return &result, nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memories.json")
+
+	s, err := New(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coffee, _ := s.Remember(ctx, "alice", "Prefers coffee without sugar")
+	s.Remember(ctx, "alice", "Works on the billing service")
+	s.Remember(ctx, "bob", "Prefers tea")
+
+	t.Run("recalls by keyword within the user", func(t *testing.T) {
+		result, err := s.Recall(ctx, "alice", "how does she take her coffee?", nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result) != 1 || result[0].ID != coffee.ID {
+			t.Fatalf("expected the coffee memory, got %+v", result)
+		}
+	})
+
+	t.Run("recalls the most recent without a query", func(t *testing.T) {
+		result, _ := s.Recall(ctx, "alice", "", &memory.RecallOptions{Limit: 1})
+
+		if len(result) != 1 || result[0].Content != "Works on the billing service" {
+			t.Fatalf("expected the latest memory, got %+v", result)
+		}
+	})
+
+	t.Run("persists across restarts", func(t *testing.T) {
+		reopened, err := New(path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, _ := reopened.List(ctx, "alice")
+
+		if len(result) != 2 {
+			t.Fatalf("expected 2 memories, got %+v", result)
+		}
+	})
+
+	t.Run("forgets a single memory", func(t *testing.T) {
+		if err := s.Forget(ctx, "alice", coffee.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.Forget(ctx, "bob", coffee.ID); !errors.Is(err, memory.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound for another user's memory, got %v", err)
+		}
+
+		result, _ := s.List(ctx, "alice")
+
+		if len(result) != 1 {
+			t.Fatalf("expected 1 memory left, got %+v", result)
+		}
+	})
+
+	t.Run("purges a user", func(t *testing.T) {
+		if err := s.Purge(ctx, "alice"); err != nil {
+			t.Fatal(err)
+		}
+
+		if result, _ := s.List(ctx, "alice"); len(result) != 0 {
+			t.Fatalf("expected no memories, got %+v", result)
+		}
+
+		if result, _ := s.List(ctx, "bob"); len(result) != 1 {
+			t.Fatalf("expected bob's memory to remain, got %+v", result)
+		}
+	})
+}
+
+func TestStore_Embeddings(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := New(filepath.Join(t.TempDir(), "memories.json"), WithEmbedder(fakeEmbedder{}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Remember(ctx, "alice", "Has a dog named Rex")
+	s.Remember(ctx, "alice", "Planning a trip to Japan in spring")
+
+	result, err := s.Recall(ctx, "alice", "what pet does she have", &memory.RecallOptions{Limit: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 1 || result[0].Content != "Has a dog named Rex" {
+		t.Fatalf("expected the dog memory, got %+v", result)
+	}
+}
+
+func TestStore_MaxMemories(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := New(filepath.Join(t.TempDir(), "memories.json"), WithMaxMemories(2))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := s.Remember(ctx, "alice", "Prefers coffee")
+	s.Remember(ctx, "alice", "Works on billing")
+
+	if _, err := s.Remember(ctx, "alice", "Lives in Zurich"); !errors.Is(err, memory.ErrLimitReached) {
+		t.Fatalf("expected the limit to be reached, got %v", err)
+	}
+
+	if _, err := s.Remember(ctx, "bob", "Prefers tea"); err != nil {
+		t.Fatalf("expected the limit to apply per user, got %v", err)
+	}
+
+	if err := s.Forget(ctx, "alice", first.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Remember(ctx, "alice", "Lives in Zurich"); err != nil {
+		t.Fatalf("expected room after forgetting, got %v", err)
+	}
+}
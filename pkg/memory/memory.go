@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+)
+
+var (
+	ErrNotFound = errors.New("memory not found")
+
+	// ErrLimitReached is returned when a user has as many memories as the
+	// store keeps; some must be forgotten first
+	ErrLimitReached = errors.New("memory limit reached, forget a memory first")
+)
+
+// Provider stores facts about users across sessions. All calls are scoped to
+// a single user.
+type Provider interface {
+	Remember(ctx context.Context, user, content string) (*Memory, error)
+	Recall(ctx context.Context, user, query string, options *RecallOptions) ([]Memory, error)
+
+	List(ctx context.Context, user string) ([]Memory, error)
+
+	Forget(ctx context.Context, user, id string) error
+	Purge(ctx context.Context, user string) error
+}
+
+type RecallOptions struct {
+	Limit int
+}
+
+type Memory struct {
+	ID string `json:"id"`
+
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
+}
+
+// User returns the authenticated user memories of the request belong to
+func User(ctx context.Context) (string, bool) {
+	user, _ := ctx.Value(auth.UserContextKey).(string)
+	return user, user != ""
+}
@@ -0,0 +1,77 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// QueueMetrics records the wait queue of a concurrency limiter: the current
+// depth per priority and how long requests waited until they were admitted,
+// timed out, were canceled or rejected.
+type QueueMetrics struct {
+	name string
+
+	depth metric.Int64UpDownCounter
+	wait  metric.Float64Histogram
+}
+
+func NewQueueMetrics(name string) *QueueMetrics {
+	meter := otel.Meter(instrumentationName)
+
+	depth, _ := meter.Int64UpDownCounter("wingman.queue.depth",
+		metric.WithDescription("Number of requests waiting for a concurrency slot"),
+		metric.WithUnit("{request}"),
+	)
+
+	wait, _ := meter.Float64Histogram("wingman.queue.wait.duration",
+		metric.WithDescription("Time requests spent waiting for a concurrency slot"),
+		metric.WithUnit("s"),
+	)
+
+	return &QueueMetrics{
+		name: name,
+
+		depth: depth,
+		wait:  wait,
+	}
+}
+
+// Enqueued records a request entering the queue
+func (m *QueueMetrics) Enqueued(ctx context.Context, priority string) {
+	if m.depth == nil {
+		return
+	}
+
+	m.depth.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("wingman.queue.name", m.name),
+		attribute.String("wingman.queue.priority", priority),
+	))
+}
+
+// Dequeued records a request leaving the queue with the given outcome
+// ("admitted", "timeout", "canceled"). Requests rejected without queueing are
+// recorded with queued set to false.
+func (m *QueueMetrics) Dequeued(ctx context.Context, priority string, queued bool, wait time.Duration, outcome string) {
+	attrs := metric.WithAttributes(
+		attribute.String("wingman.queue.name", m.name),
+		attribute.String("wingman.queue.priority", priority),
+	)
+
+	if queued && m.depth != nil {
+		m.depth.Add(ctx, -1, attrs)
+	}
+
+	if m.wait == nil {
+		return
+	}
+
+	m.wait.Record(ctx, wait.Seconds(), metric.WithAttributes(
+		attribute.String("wingman.queue.name", m.name),
+		attribute.String("wingman.queue.priority", priority),
+		attribute.String("wingman.queue.outcome", outcome),
+	))
+}
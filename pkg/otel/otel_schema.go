@@ -0,0 +1,54 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SchemaMetrics records how structured output of a model held up against
+// the requested JSON schema: valid at once, repaired, or still invalid after
+// all repair attempts.
+type SchemaMetrics struct {
+	model string
+
+	validations metric.Int64Counter
+}
+
+func NewSchemaMetrics(model string) *SchemaMetrics {
+	meter := otel.Meter(instrumentationName)
+
+	validations, _ := meter.Int64Counter("wingman.schema.validations",
+		metric.WithDescription("Number of structured outputs validated against their schema"),
+		metric.WithUnit("{completion}"),
+	)
+
+	return &SchemaMetrics{
+		model: model,
+
+		validations: validations,
+	}
+}
+
+// Validated records the outcome ("valid", "repaired", "invalid") and the
+// attempts it took, on the metric and the current span
+func (m *SchemaMetrics) Validated(ctx context.Context, outcome string, attempts int) {
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("wingman.schema.outcome", outcome),
+			attribute.Int("wingman.schema.attempts", attempts),
+		)
+	}
+
+	if m.validations == nil {
+		return
+	}
+
+	m.validations.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("gen_ai.request.model", m.model),
+		attribute.String("wingman.schema.outcome", outcome),
+	))
+}
@@ -3,6 +3,7 @@ package otel
 import (
 	"context"
 
+	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/tool"
 
 	"go.opentelemetry.io/otel"
@@ -21,11 +22,26 @@ type observableTool struct {
 }
 
 func NewTool(provider string, p tool.Provider) Tool {
-	return &observableTool{
+	t := &observableTool{
 		tool: p,
 
 		provider: provider,
 	}
+
+	// Tools rendering their own results keep doing so
+	if _, ok := p.(tool.Resulter); ok {
+		return &observableResulterTool{t}
+	}
+
+	return t
+}
+
+type observableResulterTool struct {
+	*observableTool
+}
+
+func (p *observableResulterTool) Result(name string, value any) provider.ToolResult {
+	return p.tool.(tool.Resulter).Result(name, value)
 }
 
 func (p *observableTool) otelSetup() {
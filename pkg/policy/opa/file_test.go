@@ -0,0 +1,65 @@
+package opa
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/policy"
+)
+
+const toolPolicy = `package wingman
+
+allow if input.resource == "model"
+
+allow if {
+	input.resource == "tool"
+	not contains(object.get(input.tool.arguments, "command", ""), "kubectl delete")
+}
+
+allow if {
+	input.resource == "tool"
+	"sre" in input.groups
+}
+`
+
+func TestFileVerifyToolCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.rego")
+
+	if err := os.WriteFile(path, []byte(toolPolicy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(ctx context.Context, command string) error {
+		ctx = policy.WithToolCall(ctx, policy.ToolCall{
+			Name:      "shell",
+			Arguments: map[string]any{"command": command},
+		})
+
+		return p.Verify(ctx, policy.ResourceTool, "sandbox", policy.ActionExecute)
+	}
+
+	dev := context.WithValue(context.Background(), auth.GroupsContextKey, []string{"dev"})
+	sre := context.WithValue(context.Background(), auth.GroupsContextKey, []string{"sre"})
+
+	if err := call(dev, "kubectl get pods"); err != nil {
+		t.Fatalf("expected allow, got %v", err)
+	}
+
+	if err := call(dev, "kubectl delete pod web"); !errors.Is(err, policy.ErrAccessDenied) {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+
+	if err := call(sre, "kubectl delete pod web"); err != nil {
+		t.Fatalf("expected allow, got %v", err)
+	}
+}
@@ -16,7 +16,7 @@ func evalInput(ctx context.Context, resource policy.Resource, id string, action
 		groups = []string{}
 	}
 
-	return map[string]any{
+	input := map[string]any{
 		"resource": resource,
 		"id":       id,
 		"action":   action,
@@ -25,4 +25,19 @@ func evalInput(ctx context.Context, resource policy.Resource, id string, action
 		"email":  email,
 		"groups": groups,
 	}
+
+	if call, ok := policy.ToolCallFromContext(ctx); ok {
+		arguments := call.Arguments
+
+		if arguments == nil {
+			arguments = map[string]any{}
+		}
+
+		input["tool"] = map[string]any{
+			"name":      call.Name,
+			"arguments": arguments,
+		}
+	}
+
+	return input
 }
@@ -12,14 +12,41 @@ type Resource string
 const (
 	ResourceModel Resource = "model"
 	ResourceMCP   Resource = "mcp"
+
+	// ResourceMemory guards a memory store's admin endpoints for memories
+	// of users other than the caller
+	ResourceMemory Resource = "memory"
+
+	// ResourceTool guards each call of a tool, with the call in the context
+	ResourceTool Resource = "tool"
 )
 
 type Action string
 
 const (
-	ActionAccess Action = "access"
+	ActionAccess  Action = "access"
+	ActionExecute Action = "execute"
 )
 
 type Provider interface {
 	Verify(ctx context.Context, resource Resource, id string, action Action) error
 }
+
+// ToolCall is the call a tool is verified for
+type ToolCall struct {
+	Name      string
+	Arguments map[string]any
+}
+
+type toolCallKey struct{}
+
+// WithToolCall returns a context carrying the tool call to verify
+func WithToolCall(ctx context.Context, call ToolCall) context.Context {
+	return context.WithValue(ctx, toolCallKey{}, call)
+}
+
+// ToolCallFromContext returns the tool call carried by the context, if any
+func ToolCallFromContext(ctx context.Context) (ToolCall, bool) {
+	call, ok := ctx.Value(toolCallKey{}).(ToolCall)
+	return call, ok
+}
@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+type authorizedTool struct {
+	id string
+
+	tool   tool.Provider
+	policy Provider
+}
+
+// NewTool verifies every call of the tools of a provider with the policy.
+// A denied call fails with ErrAccessDenied, which agents pass back to the
+// model as a tool error.
+func NewTool(p Provider, id string, t tool.Provider) tool.Provider {
+	a := &authorizedTool{
+		id: id,
+
+		tool:   t,
+		policy: p,
+	}
+
+	// Tools rendering their own results keep doing so
+	if _, ok := t.(tool.Resulter); ok {
+		return &authorizedResulterTool{a}
+	}
+
+	return a
+}
+
+type authorizedResulterTool struct {
+	*authorizedTool
+}
+
+func (t *authorizedResulterTool) Result(name string, value any) provider.ToolResult {
+	return t.tool.(tool.Resulter).Result(name, value)
+}
+
+func (t *authorizedTool) Tools(ctx context.Context) ([]tool.Tool, error) {
+	return t.tool.Tools(ctx)
+}
+
+func (t *authorizedTool) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	if err := VerifyToolCall(ctx, t.policy, t.id, name, parameters); err != nil {
+		return nil, err
+	}
+
+	return t.tool.Execute(ctx, name, parameters)
+}
+
+// VerifyToolCall verifies a call of the tool name of the provider id and
+// audits denials
+func VerifyToolCall(ctx context.Context, p Provider, id, name string, arguments map[string]any) error {
+	err := p.Verify(WithToolCall(ctx, ToolCall{Name: name, Arguments: arguments}), ResourceTool, id, ActionExecute)
+
+	if err == nil {
+		return nil
+	}
+
+	user, _ := ctx.Value(auth.UserContextKey).(string)
+
+	slog.Warn("policy: tool call denied", "tool", id, "name", name, "user", user, "error", err)
+
+	return fmt.Errorf("tool %s: %w", name, err)
+}
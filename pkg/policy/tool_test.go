@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+type denyCommand struct {
+	command string
+
+	seen []ToolCall
+}
+
+func (p *denyCommand) Verify(ctx context.Context, resource Resource, id string, action Action) error {
+	call, _ := ToolCallFromContext(ctx)
+	p.seen = append(p.seen, call)
+
+	if resource != ResourceTool || id != "shell" || action != ActionExecute {
+		return ErrAccessDenied
+	}
+
+	if call.Arguments["command"] == p.command {
+		return ErrAccessDenied
+	}
+
+	return nil
+}
+
+type stubTool struct {
+	calls int
+}
+
+func (t *stubTool) Tools(ctx context.Context) ([]tool.Tool, error) {
+	return []tool.Tool{{Name: "run"}}, nil
+}
+
+func (t *stubTool) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	t.calls++
+	return "ok", nil
+}
+
+type stubResulterTool struct {
+	stubTool
+}
+
+func (t *stubResulterTool) Result(name string, value any) provider.ToolResult {
+	return provider.ToolResult{Parts: []provider.Part{{Text: "rendered"}}}
+}
+
+func TestToolVerifiesCalls(t *testing.T) {
+	p := &denyCommand{command: "rm -rf /"}
+	s := &stubTool{}
+
+	tool := NewTool(p, "shell", s)
+
+	result, err := tool.Execute(context.Background(), "run", map[string]any{"command": "ls"})
+
+	if err != nil || result != "ok" {
+		t.Fatalf("expected the call to pass, got %v, %v", result, err)
+	}
+
+	if _, err := tool.Execute(context.Background(), "run", map[string]any{"command": "rm -rf /"}); !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+
+	if s.calls != 1 {
+		t.Fatalf("expected the denied call not to run, got %d calls", s.calls)
+	}
+
+	if len(p.seen) != 2 || p.seen[1].Name != "run" {
+		t.Fatalf("expected the calls in the context, got %v", p.seen)
+	}
+}
+
+func TestToolKeepsResulter(t *testing.T) {
+	p := &denyCommand{}
+
+	if _, ok := NewTool(p, "shell", &stubTool{}).(tool.Resulter); ok {
+		t.Fatal("expected no resulter")
+	}
+
+	r, ok := NewTool(p, "shell", &stubResulterTool{}).(tool.Resulter)
+
+	if !ok {
+		t.Fatal("expected a resulter")
+	}
+
+	if got := r.Result("run", "ok").Parts[0].Text; got != "rendered" {
+		t.Fatalf("unexpected result: %s", got)
+	}
+}
@@ -0,0 +1,216 @@
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/otel"
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+var _ provider.Completer = (*Completer)(nil)
+
+// Completer validates structured output against the requested JSON schema
+// for backends without native constrained decoding. Requests with a strict
+// schema are buffered; turns calling tools are passed on unvalidated, final
+// text that does not match is sent back to the model
+// with the validation error, up to the configured number of retries. Output
+// that was repaired is marked in the stop details, output that stayed
+// invalid is returned incomplete with stop reason schema_mismatch.
+type Completer struct {
+	completer provider.Completer
+
+	retries int
+	metrics *otel.SchemaMetrics
+}
+
+func FromCompleter(model string, completer provider.Completer, retries int) *Completer {
+	return &Completer{
+		completer: completer,
+
+		retries: retries,
+		metrics: otel.NewSchemaMetrics(model),
+	}
+}
+
+const repairPrompt = "Your reply does not match the required JSON schema: %s\n\nReply again with only the corrected JSON, without code fences or any other text."
+
+func (c *Completer) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	if options == nil || options.Schema == nil || options.Schema.Strict == nil || !*options.Schema.Strict {
+		return c.completer.Complete(ctx, messages, options)
+	}
+
+	schema, err := resolveSchema(options.Schema.Properties)
+
+	// A schema that cannot be resolved here is left to the backend to reject
+	if err != nil {
+		return c.completer.Complete(ctx, messages, options)
+	}
+
+	return func(yield func(*provider.Completion, error) bool) {
+		input := slices.Clone(messages)
+
+		usage := &provider.Usage{}
+
+		for attempt := 1; ; attempt++ {
+			acc := provider.CompletionAccumulator{}
+
+			for completion, err := range c.completer.Complete(ctx, input, options) {
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+
+				acc.Add(*completion)
+			}
+
+			result := acc.Result()
+
+			if result.Usage != nil {
+				addUsage(usage, result.Usage)
+				result.Usage = usage
+			}
+
+			// Truncated or refused output is passed on as is
+			if result.Message == nil || (result.Status != "" && result.Status != provider.CompletionStatusCompleted) {
+				yield(result, nil)
+				return
+			}
+
+			// Turns calling tools are not the final answer the schema is
+			// about, e.g. in agent loops sending the schema on every turn
+			if hasToolCalls(result.Message) {
+				yield(result, nil)
+				return
+			}
+
+			text := result.Message.Text()
+			value, err := validate(schema, text)
+
+			if err == nil {
+				outcome := "valid"
+
+				if attempt > 1 || value != text {
+					outcome = "repaired"
+
+					setText(result.Message, value)
+
+					result.StopDetails = &provider.StopDetails{
+						Type: "schema",
+
+						Category:    "repaired",
+						Explanation: fmt.Sprintf("output matched the schema after %d attempts", attempt),
+					}
+				}
+
+				c.metrics.Validated(ctx, outcome, attempt)
+
+				yield(result, nil)
+				return
+			}
+
+			if attempt > c.retries {
+				c.metrics.Validated(ctx, "invalid", attempt)
+
+				result.Status = provider.CompletionStatusIncomplete
+				result.StopReason = provider.StopReasonSchemaMismatch
+
+				result.StopDetails = &provider.StopDetails{
+					Type: "schema",
+
+					Category:    "invalid",
+					Explanation: err.Error(),
+				}
+
+				yield(result, nil)
+				return
+			}
+
+			input = append(input,
+				provider.AssistantMessage(text),
+				provider.UserMessage(fmt.Sprintf(repairPrompt, err.Error())),
+			)
+		}
+	}
+}
+
+func resolveSchema(properties map[string]any) (*jsonschema.Resolved, error) {
+	data, err := json.Marshal(properties)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var schema jsonschema.Schema
+
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+
+	return schema.Resolve(nil)
+}
+
+// validate checks text against the schema. JSON wrapped in code fences is
+// accepted and returned without them.
+func validate(schema *jsonschema.Resolved, text string) (string, error) {
+	value := strings.TrimSpace(text)
+
+	if after, ok := strings.CutPrefix(value, "```"); ok {
+		if i := strings.IndexByte(after, '\n'); i >= 0 {
+			after = after[i+1:]
+		}
+
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(after), "```"))
+	}
+
+	var instance any
+
+	if err := json.Unmarshal([]byte(value), &instance); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if err := schema.Validate(instance); err != nil {
+		return "", err
+	}
+
+	if value == strings.TrimSpace(text) {
+		return text, nil
+	}
+
+	return value, nil
+}
+
+func hasToolCalls(m *provider.Message) bool {
+	return slices.ContainsFunc(m.Content, func(c provider.Content) bool {
+		return c.ToolCall != nil
+	})
+}
+
+// setText replaces the text content of the message, other content is kept
+func setText(m *provider.Message, text string) {
+	content := make([]provider.Content, 0, len(m.Content))
+
+	for _, c := range m.Content {
+		if c.Text != "" {
+			continue
+		}
+
+		content = append(content, c)
+	}
+
+	m.Content = append(content, provider.TextContent(text))
+}
+
+func addUsage(total, usage *provider.Usage) {
+	total.InputTokens += usage.InputTokens
+	total.OutputTokens += usage.OutputTokens
+	total.ReasoningTokens += usage.ReasoningTokens
+	total.CacheReadInputTokens += usage.CacheReadInputTokens
+	total.CacheCreationInputTokens += usage.CacheCreationInputTokens
+}
@@ -0,0 +1,173 @@
+package structured
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+type replyCompleter struct {
+	replies []string
+
+	messages [][]provider.Message
+}
+
+func (c *replyCompleter) Complete(_ context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		c.messages = append(c.messages, messages)
+
+		reply := c.replies[len(c.messages)-1]
+
+		content := []provider.Content{provider.TextContent(reply)}
+
+		if name, ok := strings.CutPrefix(reply, "call:"); ok {
+			content = []provider.Content{provider.ToolCallContent(provider.ToolCall{ID: "call-1", Name: name, Arguments: "{}"})}
+		}
+
+		yield(&provider.Completion{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: content,
+			},
+
+			Usage: &provider.Usage{InputTokens: 10, OutputTokens: 5},
+		}, nil)
+	}
+}
+
+func schemaOptions(strict bool) *provider.CompleteOptions {
+	return &provider.CompleteOptions{
+		Schema: &provider.Schema{
+			Name:   "person",
+			Strict: &strict,
+
+			Properties: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+					"age":  map[string]any{"type": "integer"},
+				},
+				"required":             []string{"name", "age"},
+				"additionalProperties": false,
+			},
+		},
+	}
+}
+
+func complete(t *testing.T, c provider.Completer, options *provider.CompleteOptions) *provider.Completion {
+	t.Helper()
+
+	acc := provider.CompletionAccumulator{}
+
+	for completion, err := range c.Complete(context.Background(), []provider.Message{provider.UserMessage("Who?")}, options) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		acc.Add(*completion)
+	}
+
+	return acc.Result()
+}
+
+func TestCompleterValid(t *testing.T) {
+	inner := &replyCompleter{replies: []string{`{"name":"Ada","age":36}`}}
+
+	result := complete(t, FromCompleter("test", inner, 2), schemaOptions(true))
+
+	if got := result.Message.Text(); got != `{"name":"Ada","age":36}` {
+		t.Fatalf("text = %q", got)
+	}
+
+	if result.StopDetails != nil || len(inner.messages) != 1 {
+		t.Fatalf("expected a single attempt without details, got %d attempts, %+v", len(inner.messages), result.StopDetails)
+	}
+}
+
+func TestCompleterRepairs(t *testing.T) {
+	inner := &replyCompleter{replies: []string{
+		`{"name":"Ada"}`,
+		`{"name":"Ada","age":36}`,
+	}}
+
+	result := complete(t, FromCompleter("test", inner, 2), schemaOptions(true))
+
+	if got := result.Message.Text(); got != `{"name":"Ada","age":36}` {
+		t.Fatalf("text = %q", got)
+	}
+
+	if result.StopDetails == nil || result.StopDetails.Category != "repaired" {
+		t.Fatalf("expected repaired stop details, got %+v", result.StopDetails)
+	}
+
+	if result.Usage.InputTokens != 20 {
+		t.Fatalf("usage should cover both attempts, got %d input tokens", result.Usage.InputTokens)
+	}
+
+	retry := inner.messages[1]
+
+	if len(retry) != 3 || retry[1].Text() != `{"name":"Ada"}` || !strings.Contains(retry[2].Text(), "age") {
+		t.Fatalf("retry should carry the invalid reply and the validation error, got %+v", retry)
+	}
+}
+
+func TestCompleterStripsCodeFences(t *testing.T) {
+	inner := &replyCompleter{replies: []string{"```json\n{\"name\":\"Ada\",\"age\":36}\n```"}}
+
+	result := complete(t, FromCompleter("test", inner, 2), schemaOptions(true))
+
+	if got := result.Message.Text(); got != `{"name":"Ada","age":36}` {
+		t.Fatalf("text = %q", got)
+	}
+
+	if len(inner.messages) != 1 {
+		t.Fatalf("fenced JSON should not be retried, got %d attempts", len(inner.messages))
+	}
+}
+
+func TestCompleterGivesUp(t *testing.T) {
+	inner := &replyCompleter{replies: []string{"not json", `{"name":1,"age":36}`}}
+
+	result := complete(t, FromCompleter("test", inner, 1), schemaOptions(true))
+
+	if result.Status != provider.CompletionStatusIncomplete || result.StopReason != provider.StopReasonSchemaMismatch {
+		t.Fatalf("expected incomplete schema_mismatch, got %s %s", result.Status, result.StopReason)
+	}
+
+	if result.StopDetails == nil || result.StopDetails.Explanation == "" {
+		t.Fatalf("expected the validation error in the stop details, got %+v", result.StopDetails)
+	}
+
+	if len(inner.messages) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(inner.messages))
+	}
+}
+
+func TestCompleterPassesToolCalls(t *testing.T) {
+	inner := &replyCompleter{replies: []string{"call:lookup"}}
+
+	result := complete(t, FromCompleter("test", inner, 2), schemaOptions(true))
+
+	calls := result.Message.ToolCalls()
+
+	if len(calls) != 1 || calls[0].Name != "lookup" {
+		t.Fatalf("expected the tool call, got %+v", result.Message.Content)
+	}
+
+	if result.StopReason != "" || result.StopDetails != nil || len(inner.messages) != 1 {
+		t.Fatalf("expected a single unvalidated attempt, got %d attempts, %q", len(inner.messages), result.StopReason)
+	}
+}
+
+func TestCompleterIgnoresNonStrict(t *testing.T) {
+	inner := &replyCompleter{replies: []string{"not json"}}
+
+	result := complete(t, FromCompleter("test", inner, 2), schemaOptions(false))
+
+	if result.Message.Text() != "not json" || result.Status == provider.CompletionStatusIncomplete {
+		t.Fatalf("non-strict output should pass through, got %q %s", result.Message.Text(), result.Status)
+	}
+}
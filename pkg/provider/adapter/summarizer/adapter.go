@@ -44,6 +44,15 @@ func (a *Adapter) Summarize(ctx context.Context, content string, options *summar
 
 	segments := splitter.Split(content)
 
+	segmentPrompt, combinePrompt := segmentPrompt, combinePrompt
+
+	if options != nil && options.Instructions != "" {
+		focus := " Focus the summary on the following instructions and keep the details relevant to them: " + options.Instructions
+
+		segmentPrompt += focus
+		combinePrompt += focus
+	}
+
 	if len(segments) == 0 {
 		return nil, errors.New("summarizer: no content to summarize")
 	}
@@ -265,6 +265,15 @@ const (
 	StopReasonCompaction      StopReason = "compaction"
 	StopReasonRefusal         StopReason = "refusal"
 	StopReasonContextExceeded StopReason = "context_exceeded"
+
+	// StopReasonAgentLimit marks an agent run ended by one of its limits;
+	// StopDetails.Category names the limit.
+	StopReasonAgentLimit StopReason = "agent_limit"
+
+	// StopReasonSchemaMismatch marks structured output that still did not
+	// match the requested schema after all repair attempts; StopDetails
+	// explains the validation error.
+	StopReasonSchemaMismatch StopReason = "schema_mismatch"
 )
 
 type Completion struct {
@@ -0,0 +1,370 @@
+package custom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Completer = (*Completer)(nil)
+
+type Completer struct {
+	*Config
+	client CompleterClient
+}
+
+func NewCompleter(url, model string, options ...Option) (*Completer, error) {
+	cfg, err := newConfig(url, model, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := cfg.newConn()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Completer{
+		Config: cfg,
+		client: NewCompleterClient(conn),
+	}, nil
+}
+
+func (c *Completer) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		if options == nil {
+			options = new(provider.CompleteOptions)
+		}
+
+		req := &CompleteRequest{
+			Model: c.model,
+
+			Messages: convertMessages(messages),
+			Options:  convertOptions(options),
+		}
+
+		stream, err := c.client.Complete(ctx, req)
+
+		if err != nil {
+			yield(nil, convertError(err))
+			return
+		}
+
+		var status bool
+
+		for {
+			resp, err := stream.Recv()
+
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				yield(nil, convertError(err))
+				return
+			}
+
+			completion := toCompletion(resp)
+
+			if completion.Model == "" {
+				completion.Model = c.model
+			}
+
+			status = status || completion.Status != ""
+
+			if !yield(completion, nil) {
+				return
+			}
+		}
+
+		// plugins may end the stream without a status
+		if !status {
+			yield(&provider.Completion{
+				Model:  c.model,
+				Status: provider.CompletionStatusCompleted,
+			}, nil)
+		}
+	}
+}
+
+func convertOptions(options *provider.CompleteOptions) *CompleteOptions {
+	result := &CompleteOptions{
+		Stop: options.Stop,
+	}
+
+	if options.MaxTokens != nil {
+		val := int32(*options.MaxTokens)
+		result.MaxTokens = &val
+	}
+
+	result.Temperature = options.Temperature
+
+	for _, t := range options.Tools {
+		parameters, _ := json.Marshal(t.Parameters)
+
+		result.Tools = append(result.Tools, &Tool{
+			Kind: string(t.Kind),
+
+			Name:        t.Name,
+			Description: t.Description,
+
+			Parameters: string(parameters),
+
+			Strict: t.Strict,
+		})
+	}
+
+	if o := options.ToolOptions; o != nil {
+		result.ToolOptions = &ToolOptions{
+			Allowed: o.Allowed,
+			Choice:  string(o.Choice),
+
+			DisableParallelToolCalls: o.DisableParallelToolCalls,
+		}
+	}
+
+	if o := options.OutputOptions; o != nil {
+		result.OutputOptions = &OutputOptions{
+			Verbosity: string(o.Verbosity),
+		}
+	}
+
+	if o := options.ReasoningOptions; o != nil {
+		result.ReasoningOptions = &ReasoningOptions{
+			Type:    string(o.Type),
+			Effort:  string(o.Effort),
+			Context: string(o.Context),
+
+			IncludeSummary: o.IncludeSummary,
+		}
+	}
+
+	if o := options.CompactionOptions; o != nil {
+		result.CompactionOptions = &CompactionOptions{
+			Trigger:   o.Trigger,
+			Threshold: int32(o.Threshold),
+		}
+	}
+
+	if s := options.Schema; s != nil {
+		properties, _ := json.Marshal(s.Properties)
+
+		result.Schema = &Schema{
+			Name:        s.Name,
+			Description: s.Description,
+
+			Strict: s.Strict,
+
+			Properties: string(properties),
+		}
+	}
+
+	return result
+}
+
+func convertMessages(messages []provider.Message) []*Message {
+	var result []*Message
+
+	for _, m := range messages {
+		message := &Message{
+			Role: string(m.Role),
+		}
+
+		for _, c := range m.Content {
+			content := &Content{
+				Text:    c.Text,
+				Refusal: c.Refusal,
+
+				File: convertFile(c.File),
+			}
+
+			if r := c.Reasoning; r != nil {
+				content.Reasoning = &Reasoning{
+					Id: r.ID,
+
+					Text:    r.Text,
+					Summary: r.Summary,
+
+					Signature: r.Signature,
+					Redacted:  r.Redacted,
+				}
+			}
+
+			if r := c.Compaction; r != nil {
+				content.Compaction = &Compaction{
+					Id: r.ID,
+
+					Content:   r.Content,
+					Signature: r.Signature,
+				}
+			}
+
+			if t := c.ToolCall; t != nil {
+				content.ToolCall = &ToolCall{
+					Id:   t.ID,
+					Kind: string(t.Kind),
+
+					Name:      t.Name,
+					Namespace: t.Namespace,
+
+					Execution: t.Execution,
+					Arguments: t.Arguments,
+				}
+			}
+
+			if t := c.ToolResult; t != nil {
+				result := &ToolResult{
+					Id:   t.ID,
+					Kind: string(t.Kind),
+
+					IsError: t.IsError,
+
+					Execution: t.Execution,
+					Payload:   t.Payload,
+				}
+
+				for _, p := range t.Parts {
+					result.Parts = append(result.Parts, &Part{
+						Text: p.Text,
+						File: convertFile(p.File),
+					})
+				}
+
+				content.ToolResult = result
+			}
+
+			message.Content = append(message.Content, content)
+		}
+
+		result = append(result, message)
+	}
+
+	return result
+}
+
+func convertFile(f *provider.File) *File {
+	if f == nil {
+		return nil
+	}
+
+	return &File{
+		Name: f.Name,
+
+		Content:     f.Content,
+		ContentType: f.ContentType,
+	}
+}
+
+func toCompletion(c *Completion) *provider.Completion {
+	result := &provider.Completion{
+		ID:    c.Id,
+		Model: c.Model,
+
+		Status: provider.CompletionStatus(c.Status),
+
+		StopReason:   provider.StopReason(c.StopReason),
+		StopSequence: c.StopSequence,
+	}
+
+	if d := c.StopDetails; d != nil {
+		result.StopDetails = &provider.StopDetails{
+			Type:        d.Type,
+			Category:    d.Category,
+			Explanation: d.Explanation,
+		}
+	}
+
+	if m := c.Message; m != nil {
+		result.Message = toMessage(m)
+	}
+
+	if u := c.Usage; u != nil {
+		result.Usage = &provider.Usage{
+			InputTokens:  int(u.InputTokens),
+			OutputTokens: int(u.OutputTokens),
+
+			ReasoningTokens: int(u.ReasoningTokens),
+
+			CacheReadInputTokens:     int(u.CacheReadInputTokens),
+			CacheCreationInputTokens: int(u.CacheCreationInputTokens),
+		}
+	}
+
+	return result
+}
+
+func toMessage(m *Message) *provider.Message {
+	result := &provider.Message{
+		Role: provider.MessageRole(m.Role),
+	}
+
+	if result.Role == "" {
+		result.Role = provider.MessageRoleAssistant
+	}
+
+	for _, c := range m.Content {
+		content := provider.Content{
+			Text:    c.Text,
+			Refusal: c.Refusal,
+
+			File: toFile(c.File),
+		}
+
+		if r := c.Reasoning; r != nil {
+			content.Reasoning = &provider.Reasoning{
+				ID: r.Id,
+
+				Text:    r.Text,
+				Summary: r.Summary,
+
+				Signature: r.Signature,
+				Redacted:  r.Redacted,
+			}
+		}
+
+		if r := c.Compaction; r != nil {
+			content.Compaction = &provider.Compaction{
+				ID: r.Id,
+
+				Content:   r.Content,
+				Signature: r.Signature,
+			}
+		}
+
+		if t := c.ToolCall; t != nil {
+			content.ToolCall = &provider.ToolCall{
+				ID:   t.Id,
+				Kind: provider.ToolKind(t.Kind),
+
+				Name:      t.Name,
+				Namespace: t.Namespace,
+
+				Execution: t.Execution,
+				Arguments: t.Arguments,
+			}
+		}
+
+		result.Content = append(result.Content, content)
+	}
+
+	return result
+}
+
+func toFile(f *File) *provider.File {
+	if f == nil {
+		return nil
+	}
+
+	return &provider.File{
+		Name: f.Name,
+
+		Content:     f.Content,
+		ContentType: f.ContentType,
+	}
+}
@@ -0,0 +1,165 @@
+package custom
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testCompleter struct {
+	UnimplementedCompleterServer
+
+	request *CompleteRequest
+}
+
+func (s *testCompleter) Complete(req *CompleteRequest, stream grpc.ServerStreamingServer[Completion]) error {
+	s.request = req
+
+	if req.Model == "missing" {
+		return status.Error(codes.NotFound, "model not found")
+	}
+
+	deltas := []*Completion{
+		{Id: "1", Message: &Message{Role: "assistant", Content: []*Content{{Text: "Hello"}}}},
+		{Id: "1", Message: &Message{Content: []*Content{{Text: " world"}}}},
+		{Id: "1", Message: &Message{Content: []*Content{{ToolCall: &ToolCall{Id: "call_1", Name: "search", Arguments: `{"query":`}}}}},
+		{Id: "1", Message: &Message{Content: []*Content{{ToolCall: &ToolCall{Arguments: `"wingman"}`}}}}},
+		{Id: "1", Status: "completed", StopReason: "tool_use", StopDetails: &StopDetails{Type: "schema", Category: "repaired"}, Usage: &Usage{InputTokens: 12, OutputTokens: 5}},
+	}
+
+	for _, d := range deltas {
+		if err := stream.Send(d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func serve(t *testing.T, register func(*grpc.Server)) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := grpc.NewServer()
+	register(s)
+
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	return "grpc://" + l.Addr().String()
+}
+
+func TestComplete(t *testing.T) {
+	server := &testCompleter{}
+
+	url := serve(t, func(s *grpc.Server) {
+		RegisterCompleterServer(s, server)
+	})
+
+	c, err := NewCompleter(url, "llama")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := []provider.Message{
+		provider.SystemMessage("Be brief."),
+		provider.UserMessage("Hi"),
+		{Role: provider.MessageRoleUser, Content: []provider.Content{provider.ToolResultContent(provider.ToolResult{ID: "call_0", Parts: []provider.Part{{Text: "42"}}})}},
+	}
+
+	temperature := float32(0.5)
+
+	var acc provider.CompletionAccumulator
+
+	for completion, err := range c.Complete(context.Background(), messages, &provider.CompleteOptions{
+		Temperature: &temperature,
+
+		Tools: []provider.Tool{{Name: "search", Parameters: map[string]any{"type": "object"}}},
+
+		OutputOptions:     &provider.OutputOptions{Verbosity: provider.VerbosityLow},
+		ReasoningOptions:  &provider.ReasoningOptions{Effort: provider.EffortHigh, Context: provider.ReasoningContextAllTurns},
+		CompactionOptions: &provider.CompactionOptions{Trigger: true, Threshold: 1000},
+	}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		acc.Add(*completion)
+	}
+
+	req := server.request
+
+	if req.Model != "llama" || len(req.Messages) != 3 || req.Messages[0].Role != "system" {
+		t.Fatalf("unexpected request: %v", req)
+	}
+
+	if r := req.Messages[2].Content[0].ToolResult; r == nil || r.Id != "call_0" || r.Parts[0].Text != "42" {
+		t.Fatalf("unexpected tool result: %v", r)
+	}
+
+	if o := req.Options; o.GetTemperature() != 0.5 || len(o.Tools) != 1 || o.Tools[0].Parameters != `{"type":"object"}` {
+		t.Fatalf("unexpected options: %v", o)
+	}
+
+	if o := req.Options; o.OutputOptions.GetVerbosity() != "low" || o.ReasoningOptions.GetContext() != "all_turns" || !o.CompactionOptions.GetTrigger() || o.CompactionOptions.GetThreshold() != 1000 {
+		t.Fatalf("unexpected output, reasoning or compaction options: %v", o)
+	}
+
+	result := acc.Result()
+
+	if result.Model != "llama" || result.Status != provider.CompletionStatusCompleted || result.StopReason != provider.StopReasonToolUse {
+		t.Fatalf("unexpected completion: %+v", result)
+	}
+
+	if d := result.StopDetails; d == nil || d.Type != "schema" || d.Category != "repaired" {
+		t.Fatalf("unexpected stop details: %+v", d)
+	}
+
+	if text := result.Message.Text(); text != "Hello world" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+
+	calls := result.Message.ToolCalls()
+
+	if len(calls) != 1 || calls[0].Name != "search" || calls[0].Arguments != `{"query":"wingman"}` {
+		t.Fatalf("unexpected tool calls: %v", calls)
+	}
+
+	if result.Usage == nil || result.Usage.InputTokens != 12 || result.Usage.OutputTokens != 5 {
+		t.Fatalf("unexpected usage: %v", result.Usage)
+	}
+}
+
+func TestCompleteError(t *testing.T) {
+	url := serve(t, func(s *grpc.Server) {
+		RegisterCompleterServer(s, &testCompleter{})
+	})
+
+	c, err := NewCompleter(url, "missing")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range c.Complete(context.Background(), []provider.Message{provider.UserMessage("Hi")}, nil) {
+		if code := provider.CodeFromError(err, 0); code != 404 {
+			t.Fatalf("expected not found, got %v", err)
+		}
+
+		return
+	}
+
+	t.Fatal("expected an error")
+}
@@ -0,0 +1,40 @@
+package custom
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type Config struct {
+	url   string
+	model string
+}
+
+type Option func(*Config)
+
+func newConfig(url, model string, options ...Option) (*Config, error) {
+	if url == "" || !strings.HasPrefix(url, "grpc://") {
+		return nil, errors.New("invalid url")
+	}
+
+	cfg := &Config{
+		url:   url,
+		model: model,
+	}
+
+	for _, option := range options {
+		option(cfg)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) newConn() (*grpc.ClientConn, error) {
+	return grpc.NewClient(strings.TrimPrefix(c.url, "grpc://"),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(100*1024*1024)), // 100MB max receive message size
+	)
+}
@@ -0,0 +1,75 @@
+package custom
+
+import (
+	"context"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Embedder = (*Embedder)(nil)
+
+type Embedder struct {
+	*Config
+	client EmbedderClient
+}
+
+func NewEmbedder(url, model string, options ...Option) (*Embedder, error) {
+	cfg, err := newConfig(url, model, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := cfg.newConn()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embedder{
+		Config: cfg,
+		client: NewEmbedderClient(conn),
+	}, nil
+}
+
+func (e *Embedder) Embed(ctx context.Context, texts []string, options *provider.EmbedOptions) (*provider.Embedding, error) {
+	if options == nil {
+		options = new(provider.EmbedOptions)
+	}
+
+	req := &EmbedRequest{
+		Model: e.model,
+		Texts: texts,
+	}
+
+	if options.Dimensions != nil {
+		dimensions := int32(*options.Dimensions)
+		req.Dimensions = &dimensions
+	}
+
+	resp, err := e.client.Embed(ctx, req)
+
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	result := &provider.Embedding{
+		Model: resp.Model,
+	}
+
+	if result.Model == "" {
+		result.Model = e.model
+	}
+
+	if resp.InputTokens > 0 {
+		result.Usage = &provider.Usage{
+			InputTokens: int(resp.InputTokens),
+		}
+	}
+
+	for _, e := range resp.Embeddings {
+		result.Embeddings = append(result.Embeddings, e.Data)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package custom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"google.golang.org/grpc"
+)
+
+type testEmbedder struct {
+	UnimplementedEmbedderServer
+}
+
+func (s *testEmbedder) Embed(ctx context.Context, req *EmbedRequest) (*EmbedResponse, error) {
+	resp := &EmbedResponse{InputTokens: int32(len(req.Texts))}
+
+	for range req.Texts {
+		resp.Embeddings = append(resp.Embeddings, &Embedding{Data: make([]float32, req.GetDimensions())})
+	}
+
+	return resp, nil
+}
+
+func TestEmbed(t *testing.T) {
+	url := serve(t, func(s *grpc.Server) {
+		RegisterEmbedderServer(s, &testEmbedder{})
+	})
+
+	e, err := NewEmbedder(url, "e5")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dimensions := 4
+
+	embedding, err := e.Embed(context.Background(), []string{"a", "b"}, &provider.EmbedOptions{Dimensions: &dimensions})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if embedding.Model != "e5" || len(embedding.Embeddings) != 2 || len(embedding.Embeddings[0]) != 4 || embedding.Usage.InputTokens != 2 {
+		t.Fatalf("unexpected embedding: %+v", embedding)
+	}
+}
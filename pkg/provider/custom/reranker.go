@@ -0,0 +1,68 @@
+package custom
+
+import (
+	"context"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Reranker = (*Reranker)(nil)
+
+type Reranker struct {
+	*Config
+	client RerankerClient
+}
+
+func NewReranker(url, model string, options ...Option) (*Reranker, error) {
+	cfg, err := newConfig(url, model, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := cfg.newConn()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reranker{
+		Config: cfg,
+		client: NewRerankerClient(conn),
+	}, nil
+}
+
+func (r *Reranker) Rerank(ctx context.Context, query string, texts []string, options *provider.RerankOptions) ([]provider.Ranking, error) {
+	if options == nil {
+		options = new(provider.RerankOptions)
+	}
+
+	req := &RerankRequest{
+		Model: r.model,
+
+		Query: query,
+		Texts: texts,
+	}
+
+	if options.Limit != nil {
+		limit := int32(*options.Limit)
+		req.Limit = &limit
+	}
+
+	resp, err := r.client.Rerank(ctx, req)
+
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	var results []provider.Ranking
+
+	for _, r := range resp.Rankings {
+		results = append(results, provider.Ranking{
+			Text:  r.Text,
+			Score: r.Score,
+		})
+	}
+
+	return results, nil
+}
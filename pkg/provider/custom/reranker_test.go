@@ -0,0 +1,52 @@
+package custom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"google.golang.org/grpc"
+)
+
+type testReranker struct {
+	UnimplementedRerankerServer
+}
+
+func (s *testReranker) Rerank(ctx context.Context, req *RerankRequest) (*RerankResponse, error) {
+	resp := &RerankResponse{}
+
+	for i := len(req.Texts) - 1; i >= 0; i-- {
+		if req.Limit != nil && len(resp.Rankings) == int(*req.Limit) {
+			break
+		}
+
+		resp.Rankings = append(resp.Rankings, &Ranking{Text: req.Texts[i], Score: float64(i)})
+	}
+
+	return resp, nil
+}
+
+func TestRerank(t *testing.T) {
+	url := serve(t, func(s *grpc.Server) {
+		RegisterRerankerServer(s, &testReranker{})
+	})
+
+	r, err := NewReranker(url, "bge")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limit := 2
+
+	rankings, err := r.Rerank(context.Background(), "query", []string{"a", "b", "c"}, &provider.RerankOptions{Limit: &limit})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rankings) != 2 || rankings[0].Text != "c" || rankings[0].Score != 2 {
+		t.Fatalf("unexpected rankings: %v", rankings)
+	}
+}
@@ -0,0 +1,49 @@
+package custom
+
+import (
+	"net/http"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// convertError maps the status of a plugin to the matching HTTP error, so
+// clients see the same errors as from any other provider
+func convertError(err error) error {
+	s, ok := status.FromError(err)
+
+	if !ok {
+		return err
+	}
+
+	code := http.StatusBadGateway
+
+	switch s.Code() {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		code = http.StatusBadRequest
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.ResourceExhausted:
+		code = http.StatusTooManyRequests
+	case codes.Unimplemented:
+		code = http.StatusNotImplemented
+	case codes.Unavailable:
+		code = http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		code = http.StatusGatewayTimeout
+	case codes.Canceled:
+		return err
+	}
+
+	return &provider.ProviderError{
+		Code:    code,
+		Message: s.Message(),
+		Err:     err,
+	}
+}
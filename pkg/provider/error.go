@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -72,6 +73,48 @@ func TypeFromError(err error) string {
 	return ""
 }
 
+// contextExceededPhrases are message fragments providers use when rejecting a
+// prompt that does not fit the model's context window. Not every backend sets
+// a dedicated error type (Anthropic and Bedrock reply with a plain 400).
+var contextExceededPhrases = []string{
+	"context length",
+	"context window",
+	"context_length_exceeded",
+	"maximum context",
+	"prompt is too long",
+	"input is too long",
+	"too many input tokens",
+}
+
+// IsContextExceededError reports whether the error is a provider rejecting a
+// prompt that exceeds the model's context window.
+func IsContextExceededError(err error) bool {
+	provErr, ok := errors.AsType[*ProviderError](err)
+
+	if !ok {
+		return false
+	}
+
+	switch provErr.Type {
+	case "context_length_exceeded", "model_context_window_exceeded":
+		return true
+	}
+
+	if provErr.Code != http.StatusBadRequest && provErr.Code != http.StatusRequestEntityTooLarge {
+		return false
+	}
+
+	message := strings.ToLower(provErr.Message)
+
+	for _, phrase := range contextExceededPhrases {
+		if strings.Contains(message, phrase) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // RetryAfterHeaderValue formats a Retry-After duration as an HTTP header value (seconds).
 func RetryAfterHeaderValue(d time.Duration) string {
 	if d <= 0 {
@@ -2,6 +2,26 @@ package provider
 
 type Model struct {
 	ID string
+
+	// MaxContext is the model's context window in tokens. Zero means unknown.
+	MaxContext int
+
+	// Dimensions is an embedder's vector size. Zero means unknown.
+	Dimensions int
+
+	// Pricing is the model's list price. Nil means unknown.
+	Pricing *Pricing
+}
+
+// Pricing is a model's list price in USD per million tokens
+type Pricing struct {
+	Input  float64
+	Output float64
+}
+
+// Cost returns the price of the given usage in USD
+func (p Pricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1e6
 }
 
 type File struct {
@@ -52,6 +72,11 @@ const (
 	ToolKindComputer   ToolKind = "computer"
 	ToolKindShell      ToolKind = "shell"
 	ToolKindToolSearch ToolKind = "tool_search"
+
+	// ToolKindApproval marks a tool call an agent holds back until the
+	// caller approves it. The caller answers with a ToolResult of the same
+	// kind and ID; IsError reports a denial, with the reason in Parts.
+	ToolKindApproval ToolKind = "approval"
 )
 
 type Display struct {
@@ -11,7 +11,13 @@ import (
 // (TTFT), error rate and current load, with circuit breaker protection and
 // transparent failover
 func NewCompleter(completers []provider.Completer, options ...router.Option) (*router.Completer, error) {
-	return router.NewCompleter(completers, selectProvider, options...)
+	return router.NewCompleter(completers, NewStrategy(), options...)
+}
+
+// NewStrategy creates the adaptive selection strategy, usable by routers of
+// any provider type
+func NewStrategy() router.Strategy {
+	return selectProvider
 }
 
 // explorationRate is the fraction of requests routed uniformly at random
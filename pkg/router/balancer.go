@@ -0,0 +1,274 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/limiter"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// balancer routes requests of one provider type across its members with the
+// same circuit breaking and failover as the Completer. It backs the routers
+// for embedders, rerankers, renderers, synthesizers and transcribers.
+type balancer[P any] struct {
+	pool
+
+	members []P
+
+	fallback    P
+	hasFallback bool
+}
+
+func newBalancer[P any](members []P, strategy Strategy, options []Option) (*balancer[P], error) {
+	if len(members) == 0 {
+		return nil, errors.New("at least one provider is required")
+	}
+
+	s := newSettings(options)
+
+	if s.windows != nil {
+		return nil, errors.New("context windows are only supported for completers")
+	}
+
+	if _, ok := any(members[0]).(provider.Embedder); s.dimensions != 0 && !ok {
+		return nil, errors.New("dimensions are only supported for embedders")
+	}
+
+	fallback, err := fallbackAs[P](s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &balancer[P]{
+		pool: newPool(len(members), strategy, s),
+
+		members: members,
+
+		fallback:    fallback,
+		hasFallback: s.fallback != nil,
+	}, nil
+}
+
+// call routes a unary request, failing over to the next healthy member as
+// long as the error says something about the member rather than the request.
+// The full request latency feeds the responsiveness metric.
+func call[P, R any](ctx context.Context, b *balancer[P], fn func(ctx context.Context, member P) (R, error)) (R, error) {
+	var zero R
+
+	tried := make(map[int]bool, len(b.members))
+
+	var lastErr error
+
+	for len(tried) < len(b.members) {
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+
+		index, probe := b.acquire(tried, b.strategy)
+
+		if index < 0 {
+			break
+		}
+
+		tried[index] = true
+
+		stat := b.stats[index]
+		start := time.Now()
+
+		result, err := fn(ctx, b.members[index])
+
+		switch {
+		case err == nil:
+			stat.RecordSuccess(time.Since(start), probe)
+			return result, nil
+
+		case ctx.Err() != nil:
+			stat.Release(probe)
+			return zero, ctx.Err()
+
+		case isRequestError(err):
+			stat.Release(probe)
+			return zero, err
+
+		case limiter.IsLimitError(err):
+			stat.Release(probe)
+
+		default:
+			stat.RecordFailure(b.failureThreshold, probe, err)
+		}
+
+		lastErr = err
+	}
+
+	if b.hasFallback {
+		return fn(ctx, b.fallback)
+	}
+
+	if lastErr != nil {
+		return zero, lastErr
+	}
+
+	return zero, unavailableError()
+}
+
+// stream routes a streaming request. Until the first chunk is delivered, a
+// failing or silent member (see WithFirstTokenTimeout) is skipped for the
+// next healthy one; afterwards errors pass through to the caller.
+func stream[P, R any](ctx context.Context, b *balancer[P], fn func(ctx context.Context, member P) iter.Seq2[R, error]) iter.Seq2[R, error] {
+	return func(yield func(R, error) bool) {
+		var zero R
+
+		tried := make(map[int]bool, len(b.members))
+
+		var lastErr error
+
+		for len(tried) < len(b.members) {
+			if ctx.Err() != nil {
+				yield(zero, ctx.Err())
+				return
+			}
+
+			index, probe := b.acquire(tried, b.strategy)
+
+			if index < 0 {
+				break
+			}
+
+			tried[index] = true
+
+			done, err := attemptStream(ctx, b, index, probe, fn, yield)
+
+			if done {
+				return
+			}
+
+			lastErr = err
+		}
+
+		if b.hasFallback {
+			for result, err := range fn(ctx, b.fallback) {
+				if !yield(result, err) {
+					return
+				}
+			}
+
+			return
+		}
+
+		if lastErr == nil {
+			lastErr = unavailableError()
+		}
+
+		yield(zero, lastErr)
+	}
+}
+
+// attemptStream runs a streaming request against a single member. It returns
+// done=true when the router must not fail over; otherwise the error describes
+// why the attempt failed before producing output.
+func attemptStream[P, R any](ctx context.Context, b *balancer[P], index int, probe bool, fn func(ctx context.Context, member P) iter.Seq2[R, error], yield func(R, error) bool) (bool, error) {
+	var zero R
+
+	stat := b.stats[index]
+
+	attemptCtx := ctx
+
+	var timer *time.Timer
+
+	if b.firstTokenTimeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithCancel(ctx)
+		defer cancel()
+
+		timer = time.AfterFunc(b.firstTokenTimeout, cancel)
+		defer timer.Stop()
+	}
+
+	start := time.Now()
+
+	var ttft time.Duration
+	var delivered bool
+	var attemptErr, streamErr error
+
+	for result, err := range fn(attemptCtx, b.members[index]) {
+		if err != nil {
+			if !delivered {
+				attemptErr = err
+				break
+			}
+
+			streamErr = err
+
+			if !yield(result, err) {
+				break
+			}
+
+			continue
+		}
+
+		if !delivered {
+			delivered = true
+			ttft = time.Since(start)
+
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+
+		streamErr = nil
+
+		if !yield(result, nil) {
+			break
+		}
+	}
+
+	switch {
+	case delivered:
+		if streamErr != nil && ctx.Err() == nil && attemptCtx.Err() == nil {
+			stat.RecordFailure(b.failureThreshold, probe, streamErr)
+		} else {
+			stat.RecordSuccess(ttft, probe)
+		}
+
+		return true, nil
+
+	case ctx.Err() != nil:
+		stat.Release(probe)
+		yield(zero, ctx.Err())
+		return true, nil
+
+	case attemptErr != nil:
+		if attemptCtx.Err() != nil {
+			stat.RecordFailure(b.failureThreshold, probe, nil)
+			return false, &provider.ProviderError{
+				Code:    http.StatusGatewayTimeout,
+				Message: fmt.Sprintf("no response within %s", b.firstTokenTimeout),
+				Err:     attemptErr,
+			}
+		}
+
+		if isRequestError(attemptErr) {
+			stat.Release(probe)
+			yield(zero, attemptErr)
+			return true, nil
+		}
+
+		if limiter.IsLimitError(attemptErr) {
+			stat.Release(probe)
+			return false, attemptErr
+		}
+
+		stat.RecordFailure(b.failureThreshold, probe, attemptErr)
+		return false, attemptErr
+
+	default:
+		stat.RecordFailure(b.failureThreshold, probe, nil)
+		return false, errors.New("provider returned no response")
+	}
+}
@@ -0,0 +1,429 @@
+// Package budget implements a cost- and latency-aware router. For every
+// request it estimates the cost on each member from the prompt size and the
+// member's list price, and picks the cheapest healthy member expected to meet
+// the latency SLO. As the hourly budget runs out, traffic shifts to the
+// cheapest members regardless of latency.
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/router"
+	"github.com/adrianliechti/wingman/pkg/tokens"
+)
+
+var _ provider.Completer = (*Completer)(nil)
+
+// Member is a routable backend with the price its cost estimate is based on.
+// Model selects the tokenizer used to estimate the prompt.
+type Member struct {
+	Completer provider.Completer
+
+	Model   string
+	Pricing provider.Pricing
+}
+
+// Options configures the latency objective and the spending limits. Zero
+// values disable the respective limit.
+type Options struct {
+	// SLO is the target time to first token. Members whose observed TTFT
+	// exceeds it are only used when no member meets it.
+	SLO time.Duration
+
+	// MaxRequestCost is the highest estimated cost in USD a single request
+	// may incur. Members estimated above it are skipped.
+	MaxRequestCost float64
+
+	// HourlyBudget is the spend in USD over the trailing hour above which
+	// traffic shifts to the cheapest members.
+	HourlyBudget float64
+
+	// Threshold is the fraction of HourlyBudget at which the shift starts
+	// (default 0.8).
+	Threshold float64
+
+	// Fallback serves requests no member can. Its estimated cost is held
+	// against MaxRequestCost like that of the members.
+	Fallback *Member
+}
+
+const (
+	defaultThreshold = 0.8
+
+	// defaultOutputTokens is the assumed completion length until the first
+	// responses have been observed
+	defaultOutputTokens = 1024
+
+	outputAlpha = 0.2 // EMA weight for completion length
+)
+
+// Completer routes each request to the cheapest member meeting the SLO, with
+// the circuit breaking and failover of router.Completer
+type Completer struct {
+	router *router.Completer
+
+	members []Member
+	options Options
+
+	ledger *ledger
+
+	mu           sync.Mutex
+	outputTokens float64
+}
+
+// NewCompleter creates a budget router over the given members. The router
+// options configure the underlying health tracking and fallback.
+func NewCompleter(members []Member, options Options, routerOptions ...router.Option) (*Completer, error) {
+	if len(members) == 0 {
+		return nil, errors.New("at least one completer is required")
+	}
+
+	if options.SLO < 0 || options.MaxRequestCost < 0 || options.HourlyBudget < 0 {
+		return nil, errors.New("budget limits must not be negative")
+	}
+
+	if options.Threshold < 0 || options.Threshold > 1 {
+		return nil, errors.New("budget threshold must be between 0 and 1")
+	}
+
+	if options.Threshold == 0 {
+		options.Threshold = defaultThreshold
+	}
+
+	c := &Completer{
+		members: members,
+		options: options,
+
+		ledger: &ledger{},
+
+		outputTokens: defaultOutputTokens,
+	}
+
+	completers := make([]provider.Completer, len(members))
+
+	for i, m := range members {
+		completers[i] = &meter{
+			completer: m.Completer,
+			pricing:   m.Pricing,
+			owner:     c,
+		}
+	}
+
+	if f := options.Fallback; f != nil {
+		routerOptions = append(routerOptions, router.WithFallback[provider.Completer](&fallback{
+			meter: meter{
+				completer: f.Completer,
+				pricing:   f.Pricing,
+				owner:     c,
+			},
+		}))
+	}
+
+	r, err := router.NewCompleter(completers, c.cheapest, routerOptions...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.router = r
+
+	return c, nil
+}
+
+// Stats exposes the per-member stats, indexed like the members
+func (c *Completer) Stats() []*router.ProviderStats {
+	return c.router.Stats()
+}
+
+// Spend returns the spend in USD over the trailing hour
+func (c *Completer) Spend() float64 {
+	return c.ledger.total(time.Now())
+}
+
+func (c *Completer) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	costs, fallbackCost := c.estimate(messages, options)
+
+	skip := make([]bool, len(c.members))
+
+	eligible := c.options.Fallback != nil && !c.exceeds(fallbackCost)
+	cheapest := costs[0]
+
+	if c.options.Fallback != nil {
+		cheapest = fallbackCost
+	}
+
+	for i, cost := range costs {
+		cheapest = min(cheapest, cost)
+
+		if c.exceeds(cost) {
+			skip[i] = true
+			continue
+		}
+
+		eligible = true
+	}
+
+	if !eligible {
+		return func(yield func(*provider.Completion, error) bool) {
+			yield(nil, c.costExceeded(cheapest))
+		}
+	}
+
+	// The fallback is only chosen within the router; it checks its own cost
+	ctx = context.WithValue(ctx, fallbackCostKey{}, fallbackCost)
+
+	spend := c.ledger.total(time.Now())
+	constrained := c.options.HourlyBudget > 0 && spend >= c.options.HourlyBudget*c.options.Threshold
+
+	span := trace.SpanFromContext(ctx)
+
+	span.SetAttributes(
+		attribute.Float64("wingman.router.budget.hourly_spend", spend),
+		attribute.Bool("wingman.router.budget.constrained", constrained),
+	)
+
+	strategy := func(candidates []int, stats []*router.ProviderStats) int {
+		index, meetsSLO := c.pick(candidates, stats, costs, constrained)
+
+		span.SetAttributes(
+			attribute.String("wingman.router.budget.model", c.members[index].Model),
+			attribute.Float64("wingman.router.budget.estimated_cost", costs[index]),
+			attribute.Int64("wingman.router.budget.expected_ttft_ms", stats[index].Metrics().TTFT.Milliseconds()),
+			attribute.Bool("wingman.router.budget.slo_met", meetsSLO),
+		)
+
+		return index
+	}
+
+	return c.router.Route(ctx, strategy, skip, messages, options)
+}
+
+// pick selects the cheapest closed-circuit candidate expected to meet the SLO.
+// Without one it falls back to the fastest candidate; under budget pressure
+// it picks the cheapest candidate regardless of latency.
+func (c *Completer) pick(candidates []int, stats []*router.ProviderStats, costs []float64, constrained bool) (int, bool) {
+	metrics := make(map[int]router.Metrics, len(candidates))
+
+	for _, i := range candidates {
+		metrics[i] = stats[i].Metrics()
+	}
+
+	meets := func(i int) bool {
+		m := metrics[i]
+		return m.State == router.CircuitClosed && (c.options.SLO == 0 || m.TTFT <= c.options.SLO)
+	}
+
+	best := -1
+
+	for _, i := range candidates {
+		if !constrained && !meets(i) {
+			continue
+		}
+
+		if best < 0 || costs[i] < costs[best] || (costs[i] == costs[best] && metrics[i].TTFT < metrics[best].TTFT) {
+			best = i
+		}
+	}
+
+	if best >= 0 {
+		return best, meets(best)
+	}
+
+	for _, i := range candidates {
+		if best < 0 || faster(metrics[i], metrics[best]) {
+			best = i
+		}
+	}
+
+	return best, false
+}
+
+// exceeds reports whether an estimated cost is above MaxRequestCost
+func (c *Completer) exceeds(cost float64) bool {
+	return c.options.MaxRequestCost > 0 && cost > c.options.MaxRequestCost
+}
+
+func (c *Completer) costExceeded(cost float64) error {
+	return &provider.ProviderError{
+		Code:    http.StatusBadRequest,
+		Type:    "request_cost_exceeded",
+		Message: fmt.Sprintf("estimated request cost of $%.4f exceeds the limit of $%.4f", cost, c.options.MaxRequestCost),
+	}
+}
+
+// faster prefers closed circuits, then the lower observed TTFT
+func faster(a, b router.Metrics) bool {
+	if (a.State == router.CircuitClosed) != (b.State == router.CircuitClosed) {
+		return a.State == router.CircuitClosed
+	}
+
+	return a.TTFT < b.TTFT
+}
+
+// cheapest is the strategy for requests without a cost estimate
+func (c *Completer) cheapest(candidates []int, stats []*router.ProviderStats) int {
+	costs := make([]float64, len(c.members))
+
+	for i, m := range c.members {
+		costs[i] = m.Pricing.Cost(0, 1)
+	}
+
+	index, _ := c.pick(candidates, stats, costs, false)
+	return index
+}
+
+// estimate returns the expected cost of the request on each member and on
+// the fallback. The prompt is estimated once per tokenizer; the completion
+// length is the observed average, capped by max_tokens.
+func (c *Completer) estimate(messages []provider.Message, options *provider.CompleteOptions) ([]float64, float64) {
+	input := tokens.Input{
+		Messages: messages,
+	}
+
+	c.mu.Lock()
+	output := int(c.outputTokens)
+	c.mu.Unlock()
+
+	if options != nil {
+		input.Tools = options.Tools
+
+		if options.MaxTokens != nil && *options.MaxTokens > 0 {
+			output = min(output, *options.MaxTokens)
+		}
+	}
+
+	estimates := make(map[string]int)
+
+	cost := func(m Member) float64 {
+		estimate, ok := estimates[m.Model]
+
+		if !ok {
+			estimate = tokens.Estimate(m.Model, input)
+			estimates[m.Model] = estimate
+		}
+
+		return m.Pricing.Cost(estimate, output)
+	}
+
+	costs := make([]float64, len(c.members))
+
+	for i, m := range c.members {
+		costs[i] = cost(m)
+	}
+
+	var fallback float64
+
+	if c.options.Fallback != nil {
+		fallback = cost(*c.options.Fallback)
+	}
+
+	return costs, fallback
+}
+
+// record books the actual cost of a completed request
+func (c *Completer) record(pricing provider.Pricing, usage *provider.Usage) {
+	c.ledger.add(time.Now(), pricing.Cost(usage.InputTokens, usage.OutputTokens))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.outputTokens = outputAlpha*float64(usage.OutputTokens) + (1-outputAlpha)*c.outputTokens
+}
+
+// meter books the usage reported by a member against the budget
+type meter struct {
+	completer provider.Completer
+	pricing   provider.Pricing
+
+	owner *Completer
+}
+
+func (m *meter) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		var usage *provider.Usage
+
+		defer func() {
+			if usage != nil {
+				m.owner.record(m.pricing, usage)
+			}
+		}()
+
+		for completion, err := range m.completer.Complete(ctx, messages, options) {
+			if completion != nil && completion.Usage != nil {
+				usage = completion.Usage
+			}
+
+			if !yield(completion, err) {
+				return
+			}
+		}
+	}
+}
+
+type fallbackCostKey struct{}
+
+// fallback books its usage like a member and refuses requests whose estimated
+// cost on it exceeds MaxRequestCost
+type fallback struct {
+	meter
+}
+
+func (f *fallback) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	if cost, ok := ctx.Value(fallbackCostKey{}).(float64); ok && f.owner.exceeds(cost) {
+		return func(yield func(*provider.Completion, error) bool) {
+			yield(nil, f.owner.costExceeded(cost))
+		}
+	}
+
+	return f.meter.Complete(ctx, messages, options)
+}
+
+// ledger sums spend over a trailing hour in one-minute buckets
+type ledger struct {
+	mu sync.Mutex
+
+	buckets [60]float64
+	minutes [60]int64
+}
+
+func (l *ledger) add(now time.Time, cost float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	minute := now.Unix() / 60
+	i := minute % 60
+
+	if l.minutes[i] != minute {
+		l.minutes[i] = minute
+		l.buckets[i] = 0
+	}
+
+	l.buckets[i] += cost
+}
+
+func (l *ledger) total(now time.Time) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	minute := now.Unix() / 60
+
+	var total float64
+
+	for i, m := range l.minutes {
+		if minute-m < 60 {
+			total += l.buckets[i]
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,257 @@
+package budget
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/router"
+)
+
+type mockCompleter struct {
+	name  string
+	calls int
+}
+
+func (m *mockCompleter) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		m.calls++
+
+		yield(&provider.Completion{
+			Model: m.name,
+
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{provider.TextContent("ok")},
+			},
+
+			Usage: &provider.Usage{
+				InputTokens:  1000,
+				OutputTokens: 1000,
+			},
+		}, nil)
+	}
+}
+
+type failingCompleter struct{}
+
+func (failingCompleter) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		yield(nil, &provider.ProviderError{Code: 503, Message: "unavailable"})
+	}
+}
+
+func complete(t *testing.T, c *Completer, prompt string) (string, error) {
+	t.Helper()
+
+	messages := []provider.Message{provider.UserMessage(prompt)}
+
+	var model string
+
+	for completion, err := range c.Complete(context.Background(), messages, nil) {
+		if err != nil {
+			return "", err
+		}
+
+		model = completion.Model
+	}
+
+	return model, nil
+}
+
+func members(m ...*mockCompleter) []Member {
+	prices := []provider.Pricing{
+		{Input: 10, Output: 30}, // expensive
+		{Input: 1, Output: 3},   // cheap
+	}
+
+	var result []Member
+
+	for i, c := range m {
+		result = append(result, Member{
+			Completer: c,
+
+			Model:   c.name,
+			Pricing: prices[i],
+		})
+	}
+
+	return result
+}
+
+func setTTFT(stats *router.ProviderStats, ttft time.Duration) {
+	for range 20 {
+		stats.Acquire(time.Minute)
+		stats.RecordSuccess(ttft, false)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	t.Run("picks the cheapest member meeting the SLO", func(t *testing.T) {
+		expensive, cheap := &mockCompleter{name: "expensive"}, &mockCompleter{name: "cheap"}
+
+		c, err := NewCompleter(members(expensive, cheap), Options{SLO: time.Second})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		setTTFT(c.Stats()[0], 200*time.Millisecond)
+		setTTFT(c.Stats()[1], 500*time.Millisecond)
+
+		if model, err := complete(t, c, "hello"); err != nil || model != "cheap" {
+			t.Fatalf("expected cheap, got %q (%v)", model, err)
+		}
+	})
+
+	t.Run("prefers a member meeting the SLO over a cheaper one", func(t *testing.T) {
+		expensive, cheap := &mockCompleter{name: "expensive"}, &mockCompleter{name: "cheap"}
+
+		c, _ := NewCompleter(members(expensive, cheap), Options{SLO: time.Second})
+
+		setTTFT(c.Stats()[0], 200*time.Millisecond)
+		setTTFT(c.Stats()[1], 5*time.Second)
+
+		if model, err := complete(t, c, "hello"); err != nil || model != "expensive" {
+			t.Fatalf("expected expensive, got %q (%v)", model, err)
+		}
+	})
+
+	t.Run("shifts to the cheapest member near the hourly budget", func(t *testing.T) {
+		expensive, cheap := &mockCompleter{name: "expensive"}, &mockCompleter{name: "cheap"}
+
+		c, _ := NewCompleter(members(expensive, cheap), Options{SLO: time.Second, HourlyBudget: 0.045})
+
+		setTTFT(c.Stats()[0], 200*time.Millisecond)
+		setTTFT(c.Stats()[1], 5*time.Second)
+
+		// 1000 input + 1000 output tokens on the expensive member cost $0.04,
+		// crossing 80% of the budget
+		if model, _ := complete(t, c, "hello"); model != "expensive" {
+			t.Fatalf("expected expensive before the budget is spent, got %q", model)
+		}
+
+		if spend := c.Spend(); spend < 0.04 {
+			t.Fatalf("expected the usage to be booked, got $%f", spend)
+		}
+
+		if model, _ := complete(t, c, "hello"); model != "cheap" {
+			t.Fatalf("expected cheap under budget pressure, got %q", model)
+		}
+	})
+
+	t.Run("skips members above the request cost ceiling", func(t *testing.T) {
+		expensive, cheap := &mockCompleter{name: "expensive"}, &mockCompleter{name: "cheap"}
+
+		c, _ := NewCompleter(members(expensive, cheap), Options{MaxRequestCost: 0.01})
+
+		setTTFT(c.Stats()[0], 200*time.Millisecond)
+		setTTFT(c.Stats()[1], 5*time.Second)
+
+		if model, err := complete(t, c, "hello"); err != nil || model != "cheap" {
+			t.Fatalf("expected cheap, got %q (%v)", model, err)
+		}
+
+		if expensive.calls != 0 {
+			t.Errorf("expected the expensive member to be skipped")
+		}
+	})
+
+	t.Run("rejects requests no member can afford", func(t *testing.T) {
+		expensive, cheap := &mockCompleter{name: "expensive"}, &mockCompleter{name: "cheap"}
+
+		c, _ := NewCompleter(members(expensive, cheap), Options{MaxRequestCost: 0.001})
+
+		_, err := complete(t, c, strings.Repeat("a long prompt ", 10000))
+
+		if pe, ok := err.(*provider.ProviderError); !ok || pe.Type != "request_cost_exceeded" {
+			t.Fatalf("expected request_cost_exceeded, got %v", err)
+		}
+	})
+
+	t.Run("uses an affordable fallback", func(t *testing.T) {
+		expensive, fallback := &mockCompleter{name: "expensive"}, &mockCompleter{name: "fallback"}
+
+		c, _ := NewCompleter(members(expensive), Options{
+			MaxRequestCost: 0.01,
+
+			Fallback: &Member{Completer: fallback, Model: "fallback", Pricing: provider.Pricing{Input: 1, Output: 3}},
+		})
+
+		if model, err := complete(t, c, "hello"); err != nil || model != "fallback" {
+			t.Fatalf("expected fallback, got %q (%v)", model, err)
+		}
+
+		if expensive.calls != 0 {
+			t.Errorf("expected the expensive member to be skipped")
+		}
+	})
+
+	t.Run("rejects a fallback above the request cost ceiling", func(t *testing.T) {
+		expensive, fallback := &mockCompleter{name: "expensive"}, &mockCompleter{name: "fallback"}
+
+		c, _ := NewCompleter(members(expensive), Options{
+			MaxRequestCost: 0.01,
+
+			Fallback: &Member{Completer: fallback, Model: "fallback", Pricing: provider.Pricing{Input: 10, Output: 30}},
+		})
+
+		_, err := complete(t, c, "hello")
+
+		if pe, ok := err.(*provider.ProviderError); !ok || pe.Type != "request_cost_exceeded" {
+			t.Fatalf("expected request_cost_exceeded, got %v", err)
+		}
+
+		if fallback.calls != 0 {
+			t.Errorf("expected the fallback not to be called")
+		}
+	})
+
+	t.Run("rejects a fallback above the request cost ceiling after failover", func(t *testing.T) {
+		fallback := &mockCompleter{name: "fallback"}
+
+		c, _ := NewCompleter([]Member{
+			{Completer: failingCompleter{}, Model: "cheap", Pricing: provider.Pricing{Input: 1, Output: 3}},
+		}, Options{
+			MaxRequestCost: 0.01,
+
+			Fallback: &Member{Completer: fallback, Model: "fallback", Pricing: provider.Pricing{Input: 10, Output: 30}},
+		})
+
+		_, err := complete(t, c, "hello")
+
+		if pe, ok := err.(*provider.ProviderError); !ok || pe.Type != "request_cost_exceeded" {
+			t.Fatalf("expected request_cost_exceeded, got %v", err)
+		}
+
+		if fallback.calls != 0 {
+			t.Errorf("expected the fallback not to be called")
+		}
+	})
+}
+
+func TestLedger(t *testing.T) {
+	l := &ledger{}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l.add(start, 1)
+	l.add(start.Add(30*time.Minute), 2)
+
+	if total := l.total(start.Add(45 * time.Minute)); total != 3 {
+		t.Errorf("expected 3 within the hour, got %f", total)
+	}
+
+	if total := l.total(start.Add(61 * time.Minute)); total != 2 {
+		t.Errorf("expected the first entry to expire, got %f", total)
+	}
+
+	l.add(start.Add(120*time.Minute), 4)
+
+	if total := l.total(start.Add(120 * time.Minute)); total != 4 {
+		t.Errorf("expected reused buckets to reset, got %f", total)
+	}
+}
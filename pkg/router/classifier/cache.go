@@ -5,65 +5,96 @@ import (
 	"sync"
 )
 
-// lruCache is a small, fixed-capacity, concurrency-safe map of request
-// fingerprint to routing decision.
-type lruCache struct {
+// lruCache is a small, fixed-capacity, concurrency-safe map, used for request
+// fingerprint to routing decision and response id to decision record.
+type lruCache[K comparable, V any] struct {
 	mu sync.Mutex
 
 	capacity int
 
 	ll    *list.List
-	items map[uint64]*list.Element
+	items map[K]*list.Element
 }
 
-type lruEntry struct {
-	key   uint64
-	value decision
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
 }
 
-func newLRU(capacity int) *lruCache {
+func newLRU[K comparable, V any](capacity int) *lruCache[K, V] {
 	if capacity < 1 {
 		capacity = 1
 	}
 
-	return &lruCache{
+	return &lruCache[K, V]{
 		capacity: capacity,
 
 		ll:    list.New(),
-		items: make(map[uint64]*list.Element),
+		items: make(map[K]*list.Element),
 	}
 }
 
-func (c *lruCache) get(key uint64) (decision, bool) {
+func (c *lruCache[K, V]) get(key K) (V, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if el, ok := c.items[key]; ok {
 		c.ll.MoveToFront(el)
-		return el.Value.(*lruEntry).value, true
+		return el.Value.(*lruEntry[K, V]).value, true
 	}
 
-	return decision{}, false
+	var zero V
+	return zero, false
 }
 
-func (c *lruCache) put(key uint64, value decision) {
+func (c *lruCache[K, V]) put(key K, value V) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if el, ok := c.items[key]; ok {
 		c.ll.MoveToFront(el)
-		el.Value.(*lruEntry).value = value
+		el.Value.(*lruEntry[K, V]).value = value
 
 		return
 	}
 
-	el := c.ll.PushFront(&lruEntry{key: key, value: value})
+	el := c.ll.PushFront(&lruEntry[K, V]{key: key, value: value})
 	c.items[key] = el
 
 	if c.ll.Len() > c.capacity {
 		if oldest := c.ll.Back(); oldest != nil {
 			c.ll.Remove(oldest)
-			delete(c.items, oldest.Value.(*lruEntry).key)
+			delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
 		}
 	}
 }
+
+// take removes and returns the value of key if match accepts it
+func (c *lruCache[K, V]) take(key K, match func(V) bool) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		value := el.Value.(*lruEntry[K, V]).value
+
+		if match(value) {
+			c.ll.Remove(el)
+			delete(c.items, key)
+
+			return value, true
+		}
+	}
+
+	var zero V
+	return zero, false
+}
+
+func (c *lruCache[K, V]) remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
@@ -2,6 +2,7 @@ package classifier
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -25,11 +26,14 @@ const (
 // per-candidate mean vector ("centroid") in memory. A failed initialization is
 // retried on a later request instead of being latched permanently. A candidate
 // with no examples leaves a nil centroid and simply doesn't participate in
-// Tier 2.
+// Tier 2. Centroids learned from feedback take precedence over the ones built
+// from the examples.
 type centroidCache struct {
 	candidates []Candidate
 	embedder   provider.Embedder
 
+	learner *learner
+
 	// sem is a one-permit semaphore serializing initialization; unlike a
 	// mutex, waiting on it respects context cancellation.
 	sem chan struct{}
@@ -37,11 +41,13 @@ type centroidCache struct {
 	vectors atomic.Pointer[[][]float32]
 }
 
-func newCentroidCache(candidates []Candidate, embedder provider.Embedder) *centroidCache {
+func newCentroidCache(candidates []Candidate, embedder provider.Embedder, learner *learner) *centroidCache {
 	return &centroidCache{
 		candidates: candidates,
 		embedder:   embedder,
 
+		learner: learner,
+
 		sem: make(chan struct{}, 1),
 	}
 }
@@ -72,11 +78,39 @@ func (cc *centroidCache) get(ctx context.Context) [][]float32 {
 		return nil
 	}
 
+	for i := range vectors {
+		if learned := cc.learner.centroid(i); learned != nil && (vectors[i] == nil || len(vectors[i]) == len(learned)) {
+			vectors[i] = learned
+		}
+	}
+
 	cc.vectors.Store(&vectors)
 
 	return vectors
 }
 
+// override replaces a single centroid after feedback. Before initialization
+// it does nothing: the build picks the learned centroid up itself.
+func (cc *centroidCache) override(i int, centroid []float32) {
+	if centroid == nil {
+		return
+	}
+
+	cc.sem <- struct{}{}
+	defer func() { <-cc.sem }()
+
+	v := cc.vectors.Load()
+
+	if v == nil {
+		return
+	}
+
+	vectors := slices.Clone(*v)
+	vectors[i] = centroid
+
+	cc.vectors.Store(&vectors)
+}
+
 func (cc *centroidCache) build(ctx context.Context) ([][]float32, bool) {
 	vectors := make([][]float32, len(cc.candidates))
 
@@ -0,0 +1,482 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+)
+
+// Tier names the cascade stage that resolved a routing decision.
+type Tier string
+
+const (
+	// TierConstraint means the hard constraints left a single candidate (or
+	// none, routing to the default), so there was nothing to decide.
+	TierConstraint Tier = "constraint"
+	TierHeuristic  Tier = "heuristic"
+	TierEmbedding  Tier = "embedding"
+	TierJudge      Tier = "judge"
+)
+
+var tiers = []Tier{TierConstraint, TierHeuristic, TierEmbedding, TierJudge}
+
+const (
+	// recordCacheSize bounds how many routing decisions are kept for feedback.
+	// Feedback for a response that has been evicted is rejected.
+	recordCacheSize = 4096
+
+	// thresholdStep is how far a single rating moves a candidate's learned
+	// difficulty ceiling. Four consistent ratings shift it by one level.
+	thresholdStep = 0.25
+
+	// centroidRate is the weight a rated query gets when pulled into the
+	// candidate's centroid. Negative ratings push away at half the rate, so a
+	// few complaints can't erase the configured examples.
+	centroidRate = 0.1
+)
+
+// ErrDecisionNotFound is returned for feedback on a response the router has no
+// recorded decision for (unknown id, evicted, already rated, served to another
+// user or by another router).
+var ErrDecisionNotFound = errors.New("no routing decision recorded for response")
+
+type responseIDKey struct{}
+
+// WithResponseID returns a context recording the routing decision also under
+// id, for handlers that return the client another id than the upstream one.
+func WithResponseID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, responseIDKey{}, id)
+}
+
+// Feedback rates the answer to a routed request. A positive Rating (up to 1)
+// means the picked model handled the task well, a negative one that it was not
+// good enough. Outcome is an alternative to Rating for callers that only know
+// whether the task succeeded ("success", "accepted") or not ("failure",
+// "rejected", "escalated").
+type Feedback struct {
+	Rating  float64
+	Outcome string
+}
+
+func (f Feedback) score() (float64, error) {
+	if f.Rating != 0 {
+		return max(-1, min(1, f.Rating)), nil
+	}
+
+	switch strings.ToLower(f.Outcome) {
+	case "success", "accepted", "good":
+		return 1, nil
+
+	case "failure", "rejected", "escalated", "bad":
+		return -1, nil
+	}
+
+	return 0, errors.New("feedback requires a rating or outcome")
+}
+
+// Report summarizes the feedback received so far, per cascade tier and per
+// candidate, to tune Margin and MaxDifficulty.
+type Report struct {
+	Tiers      []TierReport
+	Candidates []CandidateReport
+}
+
+type TierReport struct {
+	Tier Tier
+
+	Good int
+	Bad  int
+
+	Accuracy float64
+}
+
+type CandidateReport struct {
+	Model string
+
+	// MaxDifficulty is the configured ceiling, Threshold the learned one the
+	// router actually compares difficulty estimates against.
+	MaxDifficulty int
+	Threshold     float64
+
+	Good int
+	Bad  int
+
+	Accuracy float64
+}
+
+// record is the routing decision kept for a response until feedback arrives.
+// It is stored under every id of the response and rated once, by the user
+// who received the response.
+type record struct {
+	ids  []string
+	user string
+
+	index       int
+	tier        Tier
+	level       int
+	fingerprint uint64
+	query       string
+}
+
+type tally struct {
+	Good int `json:"good"`
+	Bad  int `json:"bad"`
+}
+
+func (t *tally) add(score float64) {
+	if score > 0 {
+		t.Good++
+	} else {
+		t.Bad++
+	}
+}
+
+func (t tally) accuracy() float64 {
+	if t.Good+t.Bad == 0 {
+		return 0
+	}
+
+	return float64(t.Good) / float64(t.Good+t.Bad)
+}
+
+// learnerState is the persisted form of the learned routing parameters.
+// Candidates are keyed by model, so reordering the configuration keeps them.
+type learnerState struct {
+	Candidates map[string]candidateState `json:"candidates,omitempty"`
+	Tiers      map[Tier]tally            `json:"tiers,omitempty"`
+}
+
+type candidateState struct {
+	Threshold *float64  `json:"threshold,omitempty"`
+	Centroid  []float32 `json:"centroid,omitempty"`
+
+	tally
+}
+
+// learner holds the parameters adjusted by feedback: the per-candidate
+// difficulty ceilings, learned exemplar centroids and accuracy tallies. With
+// a path set, every update is persisted to a local JSON file.
+type learner struct {
+	mu sync.Mutex
+
+	path string
+
+	candidates []Candidate
+
+	thresholds []float64
+	centroids  [][]float32
+
+	tiers   map[Tier]*tally
+	tallies []tally
+}
+
+func newLearner(candidates []Candidate, path string) (*learner, error) {
+	l := &learner{
+		path: path,
+
+		candidates: candidates,
+
+		thresholds: make([]float64, len(candidates)),
+		centroids:  make([][]float32, len(candidates)),
+
+		tiers:   make(map[Tier]*tally),
+		tallies: make([]tally, len(candidates)),
+	}
+
+	for i, c := range candidates {
+		l.thresholds[i] = float64(c.MaxDifficulty)
+	}
+
+	for _, t := range tiers {
+		l.tiers[t] = &tally{}
+	}
+
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var state learnerState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	for i, c := range candidates {
+		s, ok := state.Candidates[c.Model]
+
+		if !ok {
+			continue
+		}
+
+		if s.Threshold != nil {
+			l.thresholds[i] = *s.Threshold
+		}
+
+		l.centroids[i] = s.Centroid
+		l.tallies[i] = s.tally
+	}
+
+	for t, s := range state.Tiers {
+		if v, ok := l.tiers[t]; ok {
+			*v = s
+		}
+	}
+
+	return l, nil
+}
+
+// threshold returns the learned difficulty ceiling of a candidate.
+func (l *learner) threshold(i int) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.thresholds[i]
+}
+
+// centroid returns the learned centroid of a candidate, nil if none.
+func (l *learner) centroid(i int) []float32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.centroids[i]
+}
+
+// learn applies a score to the recorded decision. The difficulty ceiling only
+// moves on decisions the cascade actually made: a constrained pick says
+// nothing about capability. A bad answer at a level the candidate claimed to
+// clear lowers its ceiling; a good answer above its ceiling raises it.
+func (l *learner) learn(r record, score float64, query []float32, base []float32) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tallies[r.index].add(score)
+	l.tiers[r.tier].add(score)
+
+	if r.tier != TierConstraint {
+		threshold := l.thresholds[r.index]
+		level := float64(r.level)
+
+		switch {
+		case score < 0 && threshold >= level:
+			threshold -= thresholdStep * -score
+
+		case score > 0 && threshold < level:
+			threshold += thresholdStep * score
+		}
+
+		l.thresholds[r.index] = max(0, min(maxLevel, threshold))
+	}
+
+	if query != nil {
+		l.centroids[r.index] = shiftCentroid(l.centroids[r.index], base, query, r.tier, score)
+	}
+
+	return l.save()
+}
+
+// shiftCentroid moves a centroid toward a well-served query, or away from a
+// badly served one when the embedding tier caused the pick. A candidate with
+// no centroid yet is seeded by its first good query.
+func shiftCentroid(learned, base, query []float32, tier Tier, score float64) []float32 {
+	current := learned
+
+	if current == nil {
+		current = base
+	}
+
+	if current == nil {
+		if score <= 0 {
+			return nil
+		}
+
+		return query
+	}
+
+	if len(current) != len(query) {
+		return learned
+	}
+
+	rate := centroidRate * score
+
+	if score < 0 {
+		if tier != TierEmbedding {
+			return learned
+		}
+
+		rate /= 2
+	}
+
+	result := make([]float32, len(current))
+
+	for d := range current {
+		result[d] = current[d] + float32(rate)*(query[d]-current[d])
+	}
+
+	return result
+}
+
+// save writes the state next to its destination and renames it into place,
+// so a crash never leaves a truncated file behind. Must be called with the
+// mutex held.
+func (l *learner) save() error {
+	if l.path == "" {
+		return nil
+	}
+
+	state := learnerState{
+		Candidates: make(map[string]candidateState, len(l.candidates)),
+		Tiers:      make(map[Tier]tally, len(l.tiers)),
+	}
+
+	for i, c := range l.candidates {
+		threshold := l.thresholds[i]
+
+		state.Candidates[c.Model] = candidateState{
+			Threshold: &threshold,
+			Centroid:  l.centroids[i],
+
+			tally: l.tallies[i],
+		}
+	}
+
+	for t, v := range l.tiers {
+		state.Tiers[t] = *v
+	}
+
+	data, err := json.Marshal(state)
+
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), l.path)
+}
+
+func (l *learner) report() Report {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var report Report
+
+	for _, t := range tiers {
+		v := l.tiers[t]
+
+		report.Tiers = append(report.Tiers, TierReport{
+			Tier: t,
+
+			Good: v.Good,
+			Bad:  v.Bad,
+
+			Accuracy: v.accuracy(),
+		})
+	}
+
+	for i, c := range l.candidates {
+		report.Candidates = append(report.Candidates, CandidateReport{
+			Model: c.Model,
+
+			MaxDifficulty: c.MaxDifficulty,
+			Threshold:     l.thresholds[i],
+
+			Good: l.tallies[i].Good,
+			Bad:  l.tallies[i].Bad,
+
+			Accuracy: l.tallies[i].accuracy(),
+		})
+	}
+
+	return report
+}
+
+// Feedback ties a rating to the routing decision recorded for a response id
+// and adjusts the serving candidate's difficulty ceiling and, with an embedder
+// configured, its exemplar centroid. Only the user who received the response
+// can rate it, and only once under any of its ids.
+func (c *Completer) Feedback(ctx context.Context, id string, feedback Feedback) error {
+	score, err := feedback.score()
+
+	if err != nil {
+		return err
+	}
+
+	user, _ := ctx.Value(auth.UserContextKey).(string)
+
+	r, ok := c.records.take(id, func(r record) bool {
+		return r.user == user
+	})
+
+	if !ok {
+		return ErrDecisionNotFound
+	}
+
+	for _, id := range r.ids {
+		c.records.remove(id)
+	}
+
+	var query, base []float32
+
+	if c.embedder != nil && r.query != "" {
+		ctx, cancel := context.WithTimeout(ctx, embedTimeout)
+		defer cancel()
+
+		if result, err := c.embedder.Embed(ctx, []string{r.query}, nil); err == nil && result != nil && len(result.Embeddings) > 0 {
+			query = result.Embeddings[0]
+		}
+
+		if vectors := c.centroids.get(ctx); vectors != nil {
+			base = vectors[r.index]
+		}
+	}
+
+	if err := c.learner.learn(r, score, query, base); err != nil {
+		return err
+	}
+
+	if query != nil {
+		c.centroids.override(r.index, c.learner.centroid(r.index))
+	}
+
+	// The cached decision was made with the old parameters; the next request
+	// of the same task routes with the learned ones.
+	c.decisionCache.remove(r.fingerprint)
+
+	return nil
+}
+
+// Report returns the feedback accuracy per tier and per candidate.
+func (c *Completer) Report() Report {
+	return c.learner.report()
+}
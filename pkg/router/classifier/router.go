@@ -18,6 +18,7 @@ import (
 	"iter"
 	"slices"
 
+	"github.com/adrianliechti/wingman/pkg/auth"
 	"github.com/adrianliechti/wingman/pkg/provider"
 )
 
@@ -56,6 +57,10 @@ type Options struct {
 
 	// DefaultIndex is the universal fail-safe candidate.
 	DefaultIndex int
+
+	// StatePath is a local JSON file persisting what the router learned from
+	// feedback. Empty keeps the learned state in memory only.
+	StatePath string
 }
 
 const (
@@ -70,10 +75,14 @@ const (
 )
 
 // decision is a routing outcome: the picked candidate and the eligible
-// fallback to stream from when the pick fails before producing output.
+// fallback to stream from when the pick fails before producing output, plus
+// the tier that resolved it and the estimated difficulty level for feedback.
 type decision struct {
 	index    int
 	fallback int
+
+	tier  Tier
+	level int
 }
 
 type Completer struct {
@@ -86,9 +95,12 @@ type Completer struct {
 
 	judge provider.Completer
 
-	decisionCache *lruCache
+	decisionCache *lruCache[uint64, decision]
 
 	centroids *centroidCache
+
+	learner *learner
+	records *lruCache[string, record]
 }
 
 var _ provider.Completer = (*Completer)(nil)
@@ -108,6 +120,12 @@ func NewCompleter(candidates []Candidate, opts Options) (*Completer, error) {
 		margin = defaultMargin
 	}
 
+	learner, err := newLearner(candidates, opts.StatePath)
+
+	if err != nil {
+		return nil, err
+	}
+
 	c := &Completer{
 		candidates: candidates,
 
@@ -118,11 +136,14 @@ func NewCompleter(candidates []Candidate, opts Options) (*Completer, error) {
 
 		judge: opts.Judge,
 
-		decisionCache: newLRU(decisionCacheSize),
+		decisionCache: newLRU[uint64, decision](decisionCacheSize),
+
+		learner: learner,
+		records: newLRU[string, record](recordCacheSize),
 	}
 
 	if opts.Embedder != nil {
-		c.centroids = newCentroidCache(candidates, opts.Embedder)
+		c.centroids = newCentroidCache(candidates, opts.Embedder, learner)
 
 		// Pre-warm the centroids off the request path, so the first ambiguous
 		// request doesn't pay the example-embedding latency.
@@ -138,22 +159,68 @@ func NewCompleter(candidates []Candidate, opts Options) (*Completer, error) {
 }
 
 func (c *Completer) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
-	d := c.classify(ctx, messages, options)
+	s := extractSignals(messages, options)
+	fp := fingerprint(s)
+
+	d := c.classify(ctx, s, fp)
 
 	return func(yield func(*provider.Completion, error) bool) {
 		emitted := false
+		recorded := false
+
+		// The decision is recorded under the response id so feedback on the
+		// answer can be tied back to the candidate that produced it. Handlers
+		// returning their own id to the client register it as an alias.
+		alias, _ := ctx.Value(responseIDKey{}).(string)
+
+		track := func(index int, completion *provider.Completion) {
+			if recorded || completion == nil || (completion.ID == "" && alias == "") {
+				return
+			}
+
+			recorded = true
+
+			user, _ := ctx.Value(auth.UserContextKey).(string)
+
+			r := record{
+				user: user,
+
+				index:       index,
+				tier:        d.tier,
+				level:       d.level,
+				fingerprint: fp,
+				query:       truncateText(s.queryText, maxQueryChars),
+			}
+
+			for _, id := range []string{completion.ID, alias} {
+				if id != "" && !slices.Contains(r.ids, id) {
+					r.ids = append(r.ids, id)
+				}
+			}
+
+			for _, id := range r.ids {
+				c.records.put(id, r)
+			}
+		}
+
+		fallback := func() {
+			recorded = false
+
+			for completion, err := range c.candidates[d.fallback].Completer.Complete(ctx, messages, options) {
+				track(d.fallback, completion)
+
+				if !yield(completion, err) {
+					return
+				}
+			}
+		}
 
 		for completion, err := range c.candidates[d.index].Completer.Complete(ctx, messages, options) {
 			// A hard failure before any output is produced falls back once, so
 			// a single bad backend can't break the request. Once output has
 			// streamed, errors propagate normally.
 			if err != nil && !emitted && d.fallback != d.index {
-				for completion, err := range c.candidates[d.fallback].Completer.Complete(ctx, messages, options) {
-					if !yield(completion, err) {
-						return
-					}
-				}
-
+				fallback()
 				return
 			}
 
@@ -164,6 +231,8 @@ func (c *Completer) Complete(ctx context.Context, messages []provider.Message, o
 				emitted = true
 			}
 
+			track(d.index, completion)
+
 			if !yield(completion, err) {
 				return
 			}
@@ -172,21 +241,14 @@ func (c *Completer) Complete(ctx context.Context, messages []provider.Message, o
 		// A stream that completed without any content is an empty answer —
 		// treat it like a failure and retry on the fallback.
 		if !emitted && d.fallback != d.index {
-			for completion, err := range c.candidates[d.fallback].Completer.Complete(ctx, messages, options) {
-				if !yield(completion, err) {
-					return
-				}
-			}
+			fallback()
 		}
 	}
 }
 
 // classify resolves the routing decision for a request, caching it so a task's
 // own tool round-trips don't re-run the cascade.
-func (c *Completer) classify(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) decision {
-	s := extractSignals(messages, options)
-	fp := fingerprint(s)
-
+func (c *Completer) classify(ctx context.Context, s signals, fp uint64) decision {
 	// A cached decision must still satisfy the hard constraints: the
 	// fingerprint is keyed on the user instruction, but tool round-trips grow
 	// the context and can push it past a cached candidate's MaxContext.
@@ -211,17 +273,18 @@ func (c *Completer) decide(ctx context.Context, s signals) decision {
 	}
 
 	if len(eligible) == 0 {
-		return decision{c.defaultIndex, c.defaultIndex}
+		return decision{index: c.defaultIndex, fallback: c.defaultIndex, tier: TierConstraint}
 	}
 
 	if len(eligible) == 1 {
-		return decision{eligible[0], eligible[0]}
+		return decision{index: eligible[0], fallback: eligible[0], tier: TierConstraint}
 	}
 
 	// Tier 1: difficulty estimate + cheapest-good-enough pick.
 	score := difficultyScore(s)
+	level := roundLevel(score)
 
-	pick := c.cheapestClearing(eligible, roundLevel(score))
+	pick := c.cheapestClearing(eligible, level)
 
 	// Pick stability decides confidence: escalation buys nothing when the
 	// score, shifted by the estimate's assumed error in either direction,
@@ -230,7 +293,7 @@ func (c *Completer) decide(ctx context.Context, s signals) decision {
 		c.cheapestClearing(eligible, roundLevel(score+ambiguityMargin)) == pick
 
 	if confident || (c.embedder == nil && c.judge == nil) {
-		return c.resolve(eligible, pick)
+		return c.resolve(eligible, pick, TierHeuristic, level)
 	}
 
 	// Tier 2: embedding similarity. Only a resolved pick (best clears the
@@ -238,7 +301,7 @@ func (c *Completer) decide(ctx context.Context, s signals) decision {
 	// argmax is noise, not signal.
 	if c.embedder != nil {
 		if best, resolved := c.embedPick(ctx, s, eligible); resolved {
-			return c.resolve(eligible, best)
+			return c.resolve(eligible, best, TierEmbedding, level)
 		}
 	}
 
@@ -246,20 +309,20 @@ func (c *Completer) decide(ctx context.Context, s signals) decision {
 	// task's tool round-trips don't re-issue this call.
 	if c.judge != nil {
 		if k := c.judgePick(ctx, s, eligible); k >= 0 {
-			return c.resolve(eligible, k)
+			return c.resolve(eligible, k, TierJudge, level)
 		}
 	}
 
-	return c.resolve(eligible, pick)
+	return c.resolve(eligible, pick, TierHeuristic, level)
 }
 
 // resolve pairs a pick with its fallback: the default candidate when it is
 // eligible and not already the pick, otherwise the most capable other eligible
 // candidate (ties broken by cost). With no alternative the pick backs itself.
-func (c *Completer) resolve(eligible []int, index int) decision {
+func (c *Completer) resolve(eligible []int, index int, tier Tier, level int) decision {
 	if index != c.defaultIndex {
 		if slices.Contains(eligible, c.defaultIndex) {
-			return decision{index, c.defaultIndex}
+			return decision{index: index, fallback: c.defaultIndex, tier: tier, level: level}
 		}
 	}
 
@@ -283,17 +346,17 @@ func (c *Completer) resolve(eligible []int, index int) decision {
 		}
 	}
 
-	return decision{index, fallback}
+	return decision{index: index, fallback: fallback, tier: tier, level: level}
 }
 
-// cheapestClearing returns the cheapest eligible candidate whose MaxDifficulty
-// clears the estimated level. If none clear it, it returns the most capable
+// cheapestClearing returns the cheapest eligible candidate whose difficulty
+// ceiling (MaxDifficulty, as adjusted by feedback) clears the estimated level. If none clear it, it returns the most capable
 // eligible candidate (breaking ties by cost).
 func (c *Completer) cheapestClearing(eligible []int, level int) int {
 	best := -1
 
 	for _, i := range eligible {
-		if c.candidates[i].MaxDifficulty < level {
+		if c.learner.threshold(i) < float64(level) {
 			continue
 		}
 
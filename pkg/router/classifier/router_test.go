@@ -3,11 +3,14 @@ package classifier
 import (
 	"context"
 	"iter"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/adrianliechti/wingman/pkg/auth"
 	"github.com/adrianliechti/wingman/pkg/provider"
 )
 
@@ -52,6 +55,8 @@ func (m *mockCompleter) Complete(ctx context.Context, messages []provider.Messag
 		}
 
 		yield(&provider.Completion{
+			ID: m.name + "-" + strconv.Itoa(m.calls),
+
 			Message: &provider.Message{
 				Role:    provider.MessageRoleAssistant,
 				Content: []provider.Content{{Text: text}},
@@ -836,3 +841,132 @@ func TestTruncateTextRuneSafe(t *testing.T) {
 		t.Fatalf("expected unchanged, got %q", got)
 	}
 }
+
+func TestFeedbackLowersThreshold(t *testing.T) {
+	cheap := &mockCompleter{name: "cheap"}
+	strong := &mockCompleter{name: "strong"}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	candidates := []Candidate{
+		{Completer: cheap, Model: "cheap", Cost: 1, MaxDifficulty: 1},
+		{Completer: strong, Model: "strong", Cost: 60, MaxDifficulty: 4},
+	}
+
+	c, err := NewCompleter(candidates, Options{StatePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every bad rating of the cheap pick lowers its ceiling, until the easy
+	// task no longer clears it and routes to strong.
+	for i := 1; ; i++ {
+		out, _ := drain(c.Complete(context.Background(), userMsg("hello there"), nil))
+
+		if out == "strong" {
+			break
+		}
+
+		if i > 8 {
+			t.Fatal("expected bad feedback to move the task to strong")
+		}
+
+		if err := c.Feedback(context.Background(), "cheap-"+strconv.Itoa(cheap.calls), Feedback{Outcome: "rejected"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := c.Report()
+
+	if report.Candidates[0].Bad == 0 || report.Candidates[0].Threshold >= 1 {
+		t.Fatalf("expected lowered threshold in report, got %+v", report.Candidates[0])
+	}
+
+	if report.Tiers[1].Tier != TierHeuristic || report.Tiers[1].Bad != report.Candidates[0].Bad {
+		t.Fatalf("expected heuristic tier tallies, got %+v", report.Tiers)
+	}
+
+	// The learned state survives a restart.
+	restored, err := NewCompleter(candidates, Options{StatePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out, _ := drain(restored.Complete(context.Background(), userMsg("hello there"), nil)); out != "strong" {
+		t.Fatalf("expected restored state to route to strong, got %q", out)
+	}
+}
+
+func TestFeedbackUnknownResponse(t *testing.T) {
+	c, err := NewCompleter([]Candidate{
+		{Completer: &mockCompleter{name: "cheap"}, Model: "cheap", MaxDifficulty: 4},
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Feedback(context.Background(), "unknown", Feedback{Rating: 1}); err != ErrDecisionNotFound {
+		t.Fatalf("expected ErrDecisionNotFound, got %v", err)
+	}
+}
+
+func TestFeedbackByResponseID(t *testing.T) {
+	c, err := NewCompleter([]Candidate{
+		{Completer: &mockCompleter{name: "cheap"}, Model: "cheap", MaxDifficulty: 4},
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithResponseID(context.Background(), "resp_1")
+	drain(c.Complete(ctx, userMsg("hello there"), nil))
+
+	if err := c.Feedback(context.Background(), "resp_1", Feedback{Rating: 1}); err != nil {
+		t.Fatalf("expected feedback on the client id, got %v", err)
+	}
+
+	// The upstream id names the same response, which is rated already
+	if err := c.Feedback(context.Background(), "cheap-1", Feedback{Rating: 1}); err != ErrDecisionNotFound {
+		t.Fatalf("expected a repeat on the upstream id to be rejected, got %v", err)
+	}
+
+	if err := c.Feedback(context.Background(), "resp_1", Feedback{Rating: 1}); err != ErrDecisionNotFound {
+		t.Fatalf("expected a repeat on the client id to be rejected, got %v", err)
+	}
+
+	if good := c.Report().Candidates[0].Good; good != 1 {
+		t.Fatalf("expected one rating to count, got %d", good)
+	}
+
+	drain(c.Complete(context.Background(), userMsg("hello again"), nil))
+
+	if err := c.Feedback(context.Background(), "cheap-2", Feedback{Rating: 1}); err != nil {
+		t.Fatalf("expected feedback on the upstream id, got %v", err)
+	}
+}
+
+func TestFeedbackByOtherUser(t *testing.T) {
+	c, err := NewCompleter([]Candidate{
+		{Completer: &mockCompleter{name: "cheap"}, Model: "cheap", MaxDifficulty: 4},
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice := context.WithValue(context.Background(), auth.UserContextKey, "alice")
+	bob := context.WithValue(context.Background(), auth.UserContextKey, "bob")
+
+	drain(c.Complete(WithResponseID(alice, "resp_1"), userMsg("hello there"), nil))
+
+	if err := c.Feedback(bob, "resp_1", Feedback{Rating: -1}); err != ErrDecisionNotFound {
+		t.Fatalf("expected feedback of another user to be rejected, got %v", err)
+	}
+
+	if err := c.Feedback(context.Background(), "resp_1", Feedback{Rating: -1}); err != ErrDecisionNotFound {
+		t.Fatalf("expected anonymous feedback to be rejected, got %v", err)
+	}
+
+	if err := c.Feedback(alice, "resp_1", Feedback{Rating: 1}); err != nil {
+		t.Fatalf("expected feedback of the receiving user, got %v", err)
+	}
+}
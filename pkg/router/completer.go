@@ -8,58 +8,43 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/adrianliechti/wingman/pkg/limiter"
 	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/tokens"
 )
 
-// Strategy selects the next provider index from the given candidates.
-// candidates is never empty; stats is indexed by provider, not by candidate.
-type Strategy func(candidates []int, stats []*ProviderStats) int
-
 // Completer routes requests across multiple providers with circuit breaker
 // protection, a first-token deadline and transparent failover: if a provider
 // fails before producing any output, the request is retried on the next
 // healthy provider instead of surfacing the error to the caller.
 type Completer struct {
+	pool
+
 	completers []provider.Completer
-	stats      []*ProviderStats
-	strategy   Strategy
 
 	fallback provider.Completer
 
-	failureThreshold  int
-	recoveryTimeout   time.Duration
-	firstTokenTimeout time.Duration
-}
-
-type Option func(*Completer)
-
-// WithFallback sets a fallback completer used when all primary providers are unavailable
-func WithFallback(fallback provider.Completer) Option {
-	return func(c *Completer) {
-		c.fallback = fallback
-	}
-}
-
-// WithFirstTokenTimeout bounds the wait for the first response token. A
-// provider that produces nothing within this window is recorded as failed and
-// the request fails over to the next provider. Zero disables the deadline.
-func WithFirstTokenTimeout(timeout time.Duration) Option {
-	return func(c *Completer) {
-		c.firstTokenTimeout = timeout
-	}
+	// windows holds the context window per provider, indexed like the
+	// completers slice. Nil when no windows are configured.
+	windows []ContextWindow
 }
 
-// WithFailureThreshold sets the number of consecutive failures that open a circuit
-func WithFailureThreshold(threshold int) Option {
-	return func(c *Completer) {
-		c.failureThreshold = threshold
-	}
+// ContextWindow describes how large a prompt a routed provider accepts. Model
+// selects the tokenizer used to estimate the prompt; a zero Tokens means the
+// window is unknown and the provider is never skipped up front.
+type ContextWindow struct {
+	Model  string
+	Tokens int
 }
 
-// WithRecoveryTimeout sets how long an open circuit waits before allowing a probe
-func WithRecoveryTimeout(timeout time.Duration) Option {
-	return func(c *Completer) {
-		c.recoveryTimeout = timeout
+// WithContextWindows sets the context window of each provider, indexed like
+// the completers slice. Providers whose window cannot fit the estimated prompt
+// are skipped, and a provider rejecting a prompt as too long escalates the
+// request to the larger-context providers and the fallback. Only completer
+// routers support context windows.
+func WithContextWindows(windows []ContextWindow) Option {
+	return func(s *settings) {
+		s.windows = windows
 	}
 }
 
@@ -69,51 +54,72 @@ func NewCompleter(completers []provider.Completer, strategy Strategy, options ..
 		return nil, errors.New("at least one completer is required")
 	}
 
-	stats := make([]*ProviderStats, len(completers))
-	for i := range stats {
-		stats[i] = NewProviderStats()
+	s := newSettings(options)
+
+	fallback, err := fallbackAs[provider.Completer](s)
+
+	if err != nil {
+		return nil, err
 	}
 
-	c := &Completer{
-		completers: completers,
-		stats:      stats,
-		strategy:   strategy,
+	if s.windows != nil && len(s.windows) != len(completers) {
+		return nil, errors.New("context windows must match the completers")
+	}
 
-		failureThreshold:  DefaultFailureThreshold,
-		recoveryTimeout:   DefaultRecoveryTimeout,
-		firstTokenTimeout: DefaultFirstTokenTimeout,
+	if s.dimensions != 0 {
+		return nil, errors.New("dimensions are only supported for embedders")
 	}
 
-	for _, option := range options {
-		option(c)
+	c := &Completer{
+		pool: newPool(len(completers), strategy, s),
+
+		completers: completers,
+
+		fallback: fallback,
+		windows:  s.windows,
 	}
 
 	return c, nil
 }
 
-// Stats exposes the per-provider stats, indexed like the completers slice
-func (c *Completer) Stats() []*ProviderStats {
-	return c.stats
-}
-
 // Complete routes the request to the best available provider, failing over to
 // other providers as long as no output has been delivered to the caller
 func (c *Completer) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return c.Route(ctx, c.strategy, nil, messages, options)
+}
+
+// Route is like Complete, but selects providers with the given strategy and
+// never tries the providers marked in skip. It lets routers that weigh
+// request-specific costs reuse the health tracking and failover; skipped
+// providers are ineligible for this request only and keep their health.
+func (c *Completer) Route(ctx context.Context, strategy Strategy, skip []bool, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
 	messages = ScrubMessages(messages)
 	options = ScrubOptions(options)
 
 	return func(yield func(*provider.Completion, error) bool) {
 		tried := make(map[int]bool, len(c.completers))
 
+		for i, skipped := range skip {
+			if skipped && i < len(c.completers) {
+				tried[i] = true
+			}
+		}
+
 		var lastErr error
 
+		// Providers that cannot fit the prompt are ineligible for this
+		// request only; they are marked tried without touching their health
+		if size, ok := c.exclude(tried, messages, options); ok && len(tried) == len(c.completers) {
+			lastErr = contextExceededError(size)
+		}
+
 		for len(tried) < len(c.completers) {
 			if ctx.Err() != nil {
 				yield(nil, ctx.Err())
 				return
 			}
 
-			index, probe := c.acquire(tried)
+			index, probe := c.acquire(tried, strategy)
 
 			if index < 0 {
 				break
@@ -130,6 +136,10 @@ func (c *Completer) Complete(ctx context.Context, messages []provider.Message, o
 			if err != nil {
 				lastErr = err
 			}
+
+			if provider.IsContextExceededError(err) {
+				c.escalate(tried, index)
+			}
 		}
 
 		if c.fallback != nil {
@@ -147,43 +157,76 @@ func (c *Completer) Complete(ctx context.Context, messages []provider.Message, o
 			return
 		}
 
-		yield(nil, &provider.ProviderError{
-			Code:    http.StatusServiceUnavailable,
-			Message: "all providers are unavailable",
-		})
+		yield(nil, unavailableError())
 	}
 }
 
-// acquire selects and claims the next provider to try. Providers in `tried`
-// are excluded; losing an acquire race marks the provider as tried so the
-// request moves on instead of spinning on it.
-func (c *Completer) acquire(tried map[int]bool) (index int, probe bool) {
-	for {
-		candidates := make([]int, 0, len(c.completers))
+// exclude marks every provider whose known context window cannot fit the
+// estimated prompt as tried. It returns the largest estimate and whether any
+// provider was excluded. Estimates are computed once per tokenizer model.
+func (c *Completer) exclude(tried map[int]bool, messages []provider.Message, options *provider.CompleteOptions) (int, bool) {
+	if c.windows == nil {
+		return 0, false
+	}
 
-		for i, stat := range c.stats {
-			if tried[i] || !stat.IsCandidate(c.recoveryTimeout) {
-				continue
-			}
+	input := tokens.Input{
+		Messages: messages,
+	}
 
-			candidates = append(candidates, i)
-		}
+	if options != nil {
+		input.Tools = options.Tools
+	}
+
+	estimates := make(map[string]int)
 
-		if len(candidates) == 0 {
-			return -1, false
+	var size int
+	var excluded bool
+
+	for i, w := range c.windows {
+		if w.Tokens <= 0 {
+			continue
 		}
 
-		index := c.strategy(candidates, c.stats)
+		estimate, ok := estimates[w.Model]
 
-		if index < 0 {
-			return -1, false
+		if !ok {
+			estimate = tokens.Estimate(w.Model, input)
+			estimates[w.Model] = estimate
 		}
 
-		if acquired, probe := c.stats[index].Acquire(c.recoveryTimeout); acquired {
-			return index, probe
+		if estimate <= w.Tokens {
+			continue
 		}
 
-		tried[index] = true
+		tried[i] = true
+
+		size = max(size, estimate)
+		excluded = true
+	}
+
+	return size, excluded
+}
+
+// escalate marks every provider whose known context window is not larger than
+// that of the provider that rejected the prompt as tried, so the request only
+// moves on to providers that could actually fit it.
+func (c *Completer) escalate(tried map[int]bool, index int) {
+	if c.windows == nil || c.windows[index].Tokens <= 0 {
+		return
+	}
+
+	for i, w := range c.windows {
+		if w.Tokens > 0 && w.Tokens <= c.windows[index].Tokens {
+			tried[i] = true
+		}
+	}
+}
+
+func contextExceededError(size int) error {
+	return &provider.ProviderError{
+		Code:    http.StatusBadRequest,
+		Type:    "context_length_exceeded",
+		Message: fmt.Sprintf("prompt of about %d tokens exceeds the context window of all providers", size),
 	}
 }
 
@@ -211,10 +254,22 @@ func (c *Completer) attempt(ctx context.Context, index int, probe bool, messages
 	start := time.Now()
 
 	var ttft time.Duration
-	var delivered bool
+	var delivered, content bool
 	var attemptErr, streamErr error
 
 	for completion, err := range c.completers[index].Complete(attemptCtx, messages, options) {
+		// A stream that stops on the context window before producing any
+		// content is a rejection of the prompt, not an answer
+		if err == nil && !content && completion != nil && completion.StopReason == provider.StopReasonContextExceeded {
+			attemptErr = &provider.ProviderError{
+				Code:    http.StatusBadRequest,
+				Type:    "context_length_exceeded",
+				Message: "prompt exceeds the context window",
+			}
+
+			break
+		}
+
 		if err != nil {
 			// Before any output the error stays internal so the request can
 			// fail over; afterwards it must be passed through to the caller
@@ -232,6 +287,10 @@ func (c *Completer) attempt(ctx context.Context, index int, probe bool, messages
 			continue
 		}
 
+		if completion != nil && completion.Message != nil && len(completion.Message.Content) > 0 {
+			content = true
+		}
+
 		if delivered {
 			streamErr = nil
 		} else {
@@ -249,6 +308,19 @@ func (c *Completer) attempt(ctx context.Context, index int, probe bool, messages
 	}
 
 	switch {
+	case provider.IsContextExceededError(attemptErr) && ctx.Err() == nil:
+		// The prompt does not fit this provider: a per-request ineligibility
+		// that says nothing about its health. The request escalates to
+		// providers with a larger window or the fallback.
+		stat.Release(probe)
+		return false, attemptErr
+
+	case limiter.IsLimitError(attemptErr) && ctx.Err() == nil:
+		// The member is at its concurrency cap and turned the request away
+		// before it reached the backend: fail over without touching health
+		stat.Release(probe)
+		return false, attemptErr
+
 	case delivered:
 		// A stream that terminated with a provider error counts against
 		// health even though the partial output went to the caller
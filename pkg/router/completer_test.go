@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"iter"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/adrianliechti/wingman/pkg/limiter"
 	"github.com/adrianliechti/wingman/pkg/provider"
 )
 
@@ -451,6 +453,32 @@ func TestClientErrors(t *testing.T) {
 		}
 	})
 
+	t.Run("concurrency limit fails over without counting as failure", func(t *testing.T) {
+		l, _ := limiter.New("test", 1, 0, 0)
+
+		release, _ := l.Acquire(context.Background())
+		defer release()
+
+		limited := limiter.NewCompleter(l, &mockCompleter{response: "limited"})
+		healthy := &mockCompleter{response: "ok"}
+
+		c, _ := NewCompleter([]provider.Completer{limited, healthy}, firstCandidate)
+
+		result, err := collect(t, c, context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result == nil || result.Message.Text() != "ok" {
+			t.Errorf("expected failover to healthy provider, got %v", result)
+		}
+
+		if rate := c.stats[0].Metrics().ErrorRate; rate != 0 {
+			t.Errorf("limit rejection must not count against provider health, got rate %v", rate)
+		}
+	})
+
 	t.Run("429 still fails over", func(t *testing.T) {
 		limited := &mockCompleter{err: &provider.ProviderError{Code: 429, Message: "rate limited"}}
 		healthy := &mockCompleter{response: "ok"}
@@ -510,3 +538,196 @@ func TestMidStreamFailure(t *testing.T) {
 		}
 	})
 }
+
+func TestContextWindows(t *testing.T) {
+	t.Run("skips providers that cannot fit the prompt", func(t *testing.T) {
+		small := &mockCompleter{response: "small"}
+		large := &mockCompleter{response: "large"}
+
+		c, _ := NewCompleter([]provider.Completer{small, large}, firstCandidate, WithContextWindows([]ContextWindow{
+			{Model: "small", Tokens: 1},
+			{Model: "large", Tokens: 100000},
+		}))
+
+		result, err := collect(t, c, context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result == nil || result.Message.Text() != "large" {
+			t.Errorf("expected the large-context provider, got %v", result)
+		}
+
+		if small.calls.Load() != 0 {
+			t.Error("provider too small for the prompt must not be called")
+		}
+	})
+
+	t.Run("context exceeded escalates without counting as failure", func(t *testing.T) {
+		exceeded := &provider.ProviderError{Code: 400, Message: "prompt is too long: 300000 tokens > 200000 maximum"}
+
+		small := &mockCompleter{err: exceeded}
+		equal := &mockCompleter{response: "equal"}
+		large := &mockCompleter{response: "large"}
+
+		c, _ := NewCompleter([]provider.Completer{small, equal, large}, firstCandidate, WithContextWindows([]ContextWindow{
+			{Model: "small", Tokens: 200000},
+			{Model: "equal", Tokens: 200000},
+			{Model: "large", Tokens: 1000000},
+		}))
+
+		result, err := collect(t, c, context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result == nil || result.Message.Text() != "large" {
+			t.Errorf("expected escalation to the larger window, got %v", result)
+		}
+
+		if equal.calls.Load() != 0 {
+			t.Error("provider with the same window must be skipped after a context rejection")
+		}
+
+		metrics := c.stats[0].Metrics()
+
+		if metrics.ErrorRate != 0 || metrics.Inflight != 0 {
+			t.Errorf("context rejection must not count against provider health, got rate %v inflight %d", metrics.ErrorRate, metrics.Inflight)
+		}
+	})
+
+	t.Run("context exceeded stop reason escalates to fallback", func(t *testing.T) {
+		rejecting := completerFunc(func(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+			return func(yield func(*provider.Completion, error) bool) {
+				yield(&provider.Completion{
+					ID:         "test",
+					Status:     provider.CompletionStatusIncomplete,
+					StopReason: provider.StopReasonContextExceeded,
+				}, nil)
+			}
+		})
+
+		fallback := &mockCompleter{response: "fallback"}
+
+		c, _ := NewCompleter([]provider.Completer{rejecting}, firstCandidate, WithFallback(fallback))
+
+		result, err := collect(t, c, context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result == nil || result.Message.Text() != "fallback" {
+			t.Errorf("expected the fallback answer, got %v", result)
+		}
+
+		if rate := c.stats[0].Metrics().ErrorRate; rate != 0 {
+			t.Errorf("context rejection must not count against provider health, got rate %v", rate)
+		}
+	})
+
+	t.Run("no provider fits", func(t *testing.T) {
+		small := &mockCompleter{response: "small"}
+
+		c, _ := NewCompleter([]provider.Completer{small}, firstCandidate, WithContextWindows([]ContextWindow{
+			{Model: "small", Tokens: 1},
+		}))
+
+		_, err := collect(t, c, context.Background())
+
+		if !provider.IsContextExceededError(err) {
+			t.Errorf("expected a context exceeded error, got %v", err)
+		}
+
+		if small.calls.Load() != 0 {
+			t.Error("provider too small for the prompt must not be called")
+		}
+	})
+}
+
+type embedderFunc func(ctx context.Context, texts []string, options *provider.EmbedOptions) (*provider.Embedding, error)
+
+func (f embedderFunc) Embed(ctx context.Context, texts []string, options *provider.EmbedOptions) (*provider.Embedding, error) {
+	return f(ctx, texts, options)
+}
+
+func TestEmbedder(t *testing.T) {
+	vectors := func(size int) embedderFunc {
+		return func(ctx context.Context, texts []string, options *provider.EmbedOptions) (*provider.Embedding, error) {
+			return &provider.Embedding{Embeddings: [][]float32{make([]float32, size)}}, nil
+		}
+	}
+
+	failing := embedderFunc(func(ctx context.Context, texts []string, options *provider.EmbedOptions) (*provider.Embedding, error) {
+		return nil, &provider.ProviderError{Code: http.StatusInternalServerError, Message: "boom"}
+	})
+
+	t.Run("fails over to the next member", func(t *testing.T) {
+		e, err := NewEmbedder([]provider.Embedder{failing, vectors(3)}, firstCandidate, WithDimensions(3))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := e.Embed(context.Background(), []string{"hello"}, nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result.Embeddings[0]) != 3 {
+			t.Errorf("expected 3 dimensions, got %d", len(result.Embeddings[0]))
+		}
+
+		if e.Stats()[0].Metrics().ErrorRate == 0 {
+			t.Errorf("expected the failing member to record a failure")
+		}
+	})
+
+	t.Run("treats a member of different dimensions as failed", func(t *testing.T) {
+		e, err := NewEmbedder([]provider.Embedder{vectors(4), vectors(3)}, firstCandidate, WithDimensions(3))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The mismatching member answers first and must not fix the size
+		for range 2 {
+			result, err := e.Embed(context.Background(), []string{"hello"}, nil)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Embeddings[0]) != 3 {
+				t.Errorf("expected the configured 3 dimensions, got %d", len(result.Embeddings[0]))
+			}
+		}
+
+		if e.Stats()[0].Metrics().ErrorRate == 0 {
+			t.Errorf("expected the mismatching member to record a failure")
+		}
+
+		if e.Stats()[1].Metrics().ErrorRate != 0 {
+			t.Errorf("expected the matching member to stay healthy")
+		}
+	})
+
+	t.Run("requires dimensions for several members", func(t *testing.T) {
+		if _, err := NewEmbedder([]provider.Embedder{vectors(3), vectors(3)}, firstCandidate); err == nil {
+			t.Fatal("expected an error without dimensions")
+		}
+
+		if _, err := NewEmbedder([]provider.Embedder{vectors(3)}, firstCandidate); err != nil {
+			t.Fatalf("expected a single member without dimensions, got %v", err)
+		}
+	})
+
+	t.Run("rejects a fallback of another type", func(t *testing.T) {
+		if _, err := NewEmbedder([]provider.Embedder{vectors(3)}, firstCandidate, WithFallback[provider.Completer](&mockCompleter{})); err == nil {
+			t.Fatal("expected an error for a mismatched fallback")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Embedder = (*Embedder)(nil)
+
+// Embedder routes embedding requests across providers serving the same
+// vector space. Mixing dimensions would corrupt any index fed by the router,
+// so a member answering with vectors of another size than configured is
+// treated as failed.
+type Embedder struct {
+	*balancer[provider.Embedder]
+
+	// dimensions is the vector size of the routed space, zero for a single
+	// member without a fallback
+	dimensions int
+}
+
+// WithDimensions sets the vector size of an embedding router. It is required
+// with more than one member or a fallback; members answering with vectors of
+// another size are recorded as failed. Only embedder routers support
+// dimensions.
+func WithDimensions(dimensions int) Option {
+	return func(s *settings) {
+		s.dimensions = dimensions
+	}
+}
+
+// NewEmbedder creates a router that picks embedders using the given strategy
+func NewEmbedder(embedders []provider.Embedder, strategy Strategy, options ...Option) (*Embedder, error) {
+	b, err := newBalancer(embedders, strategy, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSettings(options)
+
+	if s.dimensions == 0 && (len(embedders) > 1 || b.hasFallback) {
+		return nil, errors.New("embedding routers of several providers require dimensions")
+	}
+
+	return &Embedder{
+		balancer: b,
+
+		dimensions: s.dimensions,
+	}, nil
+}
+
+func (e *Embedder) Embed(ctx context.Context, texts []string, options *provider.EmbedOptions) (*provider.Embedding, error) {
+	expected := e.dimensions
+
+	if options != nil && options.Dimensions != nil {
+		expected = *options.Dimensions
+	}
+
+	return call(ctx, e.balancer, func(ctx context.Context, embedder provider.Embedder) (*provider.Embedding, error) {
+		result, err := embedder.Embed(ctx, texts, options)
+
+		if err != nil || result == nil || expected == 0 {
+			return result, err
+		}
+
+		for _, embedding := range result.Embeddings {
+			if size := len(embedding); size != expected {
+				return nil, &provider.ProviderError{
+					Code:    http.StatusBadGateway,
+					Message: fmt.Sprintf("embedding dimensions differ from the router: %d, expected %d", size, expected),
+				}
+			}
+		}
+
+		return result, nil
+	})
+}
@@ -0,0 +1,164 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// Strategy selects the next provider index from the given candidates.
+// candidates is never empty; stats is indexed by provider, not by candidate.
+type Strategy func(candidates []int, stats []*ProviderStats) int
+
+// settings collects the options shared by all router types
+type settings struct {
+	fallback any
+
+	windows []ContextWindow
+
+	dimensions int
+
+	failureThreshold  int
+	recoveryTimeout   time.Duration
+	firstTokenTimeout time.Duration
+}
+
+type Option func(*settings)
+
+func newSettings(options []Option) settings {
+	s := settings{
+		failureThreshold:  DefaultFailureThreshold,
+		recoveryTimeout:   DefaultRecoveryTimeout,
+		firstTokenTimeout: DefaultFirstTokenTimeout,
+	}
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return s
+}
+
+// fallbackAs returns the configured fallback as the router's provider type
+func fallbackAs[P any](s settings) (P, error) {
+	var zero P
+
+	if s.fallback == nil {
+		return zero, nil
+	}
+
+	fallback, ok := s.fallback.(P)
+
+	if !ok {
+		return zero, errors.New("fallback does not match the routed provider type")
+	}
+
+	return fallback, nil
+}
+
+// WithFallback sets a fallback provider used when all primary providers are
+// unavailable. It must be of the same type as the routed providers.
+func WithFallback[P any](fallback P) Option {
+	return func(s *settings) {
+		s.fallback = fallback
+	}
+}
+
+// WithFirstTokenTimeout bounds the wait for the first response token (or
+// chunk, for streaming audio). A provider that produces nothing within this
+// window is recorded as failed and the request fails over to the next
+// provider. Zero disables the deadline. Unary requests are not bounded.
+func WithFirstTokenTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.firstTokenTimeout = timeout
+	}
+}
+
+// WithFailureThreshold sets the number of consecutive failures that open a circuit
+func WithFailureThreshold(threshold int) Option {
+	return func(s *settings) {
+		s.failureThreshold = threshold
+	}
+}
+
+// WithRecoveryTimeout sets how long an open circuit waits before allowing a probe
+func WithRecoveryTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.recoveryTimeout = timeout
+	}
+}
+
+// pool tracks the health of a set of providers and claims them for requests
+// using the router's strategy
+type pool struct {
+	stats    []*ProviderStats
+	strategy Strategy
+
+	failureThreshold  int
+	recoveryTimeout   time.Duration
+	firstTokenTimeout time.Duration
+}
+
+func newPool(size int, strategy Strategy, s settings) pool {
+	stats := make([]*ProviderStats, size)
+
+	for i := range stats {
+		stats[i] = NewProviderStats()
+	}
+
+	return pool{
+		stats:    stats,
+		strategy: strategy,
+
+		failureThreshold:  s.failureThreshold,
+		recoveryTimeout:   s.recoveryTimeout,
+		firstTokenTimeout: s.firstTokenTimeout,
+	}
+}
+
+// Stats exposes the per-provider stats, indexed like the routed providers
+func (p *pool) Stats() []*ProviderStats {
+	return p.stats
+}
+
+// acquire selects and claims the next provider to try using the given
+// strategy. Providers in `tried` are excluded; losing an acquire race marks
+// the provider as tried so the request moves on instead of spinning on it.
+func (p *pool) acquire(tried map[int]bool, strategy Strategy) (index int, probe bool) {
+	for {
+		candidates := make([]int, 0, len(p.stats))
+
+		for i, stat := range p.stats {
+			if tried[i] || !stat.IsCandidate(p.recoveryTimeout) {
+				continue
+			}
+
+			candidates = append(candidates, i)
+		}
+
+		if len(candidates) == 0 {
+			return -1, false
+		}
+
+		index := strategy(candidates, p.stats)
+
+		if index < 0 {
+			return -1, false
+		}
+
+		if acquired, probe := p.stats[index].Acquire(p.recoveryTimeout); acquired {
+			return index, probe
+		}
+
+		tried[index] = true
+	}
+}
+
+func unavailableError() error {
+	return &provider.ProviderError{
+		Code:    http.StatusServiceUnavailable,
+		Message: "all providers are unavailable",
+	}
+}
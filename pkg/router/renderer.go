@@ -0,0 +1,31 @@
+package router
+
+import (
+	"context"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Renderer = (*Renderer)(nil)
+
+// Renderer routes image generation requests across providers
+type Renderer struct {
+	*balancer[provider.Renderer]
+}
+
+// NewRenderer creates a router that picks renderers using the given strategy
+func NewRenderer(renderers []provider.Renderer, strategy Strategy, options ...Option) (*Renderer, error) {
+	b, err := newBalancer(renderers, strategy, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Renderer{b}, nil
+}
+
+func (r *Renderer) Render(ctx context.Context, input string, options *provider.RenderOptions) (*provider.Rendering, error) {
+	return call(ctx, r.balancer, func(ctx context.Context, renderer provider.Renderer) (*provider.Rendering, error) {
+		return renderer.Render(ctx, input, options)
+	})
+}
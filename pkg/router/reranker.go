@@ -0,0 +1,31 @@
+package router
+
+import (
+	"context"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Reranker = (*Reranker)(nil)
+
+// Reranker routes rerank requests across providers
+type Reranker struct {
+	*balancer[provider.Reranker]
+}
+
+// NewReranker creates a router that picks rerankers using the given strategy
+func NewReranker(rerankers []provider.Reranker, strategy Strategy, options ...Option) (*Reranker, error) {
+	b, err := newBalancer(rerankers, strategy, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reranker{b}, nil
+}
+
+func (r *Reranker) Rerank(ctx context.Context, query string, texts []string, options *provider.RerankOptions) ([]provider.Ranking, error) {
+	return call(ctx, r.balancer, func(ctx context.Context, reranker provider.Reranker) ([]provider.Ranking, error) {
+		return reranker.Rerank(ctx, query, texts, options)
+	})
+}
@@ -10,11 +10,15 @@ import (
 // NewCompleter creates a router that rotates requests evenly across healthy
 // providers, with circuit breaker protection and transparent failover
 func NewCompleter(completers []provider.Completer, options ...router.Option) (*router.Completer, error) {
+	return router.NewCompleter(completers, NewStrategy(), options...)
+}
+
+// NewStrategy creates a strategy rotating evenly across the candidates. Each
+// router needs its own, as the rotation state is not shared.
+func NewStrategy() router.Strategy {
 	var next atomic.Uint64
 
-	strategy := func(candidates []int, _ []*router.ProviderStats) int {
+	return func(candidates []int, _ []*router.ProviderStats) int {
 		return candidates[(next.Add(1)-1)%uint64(len(candidates))]
 	}
-
-	return router.NewCompleter(completers, strategy, options...)
 }
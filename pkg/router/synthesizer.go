@@ -0,0 +1,33 @@
+package router
+
+import (
+	"context"
+	"iter"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Synthesizer = (*Synthesizer)(nil)
+
+// Synthesizer routes speech synthesis requests across providers, failing over
+// as long as no audio has been delivered
+type Synthesizer struct {
+	*balancer[provider.Synthesizer]
+}
+
+// NewSynthesizer creates a router that picks synthesizers using the given strategy
+func NewSynthesizer(synthesizers []provider.Synthesizer, strategy Strategy, options ...Option) (*Synthesizer, error) {
+	b, err := newBalancer(synthesizers, strategy, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Synthesizer{b}, nil
+}
+
+func (s *Synthesizer) Synthesize(ctx context.Context, input string, options *provider.SynthesizeOptions) iter.Seq2[*provider.Synthesis, error] {
+	return stream(ctx, s.balancer, func(ctx context.Context, synthesizer provider.Synthesizer) iter.Seq2[*provider.Synthesis, error] {
+		return synthesizer.Synthesize(ctx, input, options)
+	})
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"context"
+	"iter"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+var _ provider.Transcriber = (*Transcriber)(nil)
+
+// Transcriber routes transcription requests across providers, failing over
+// as long as no text has been delivered
+type Transcriber struct {
+	*balancer[provider.Transcriber]
+}
+
+// NewTranscriber creates a router that picks transcribers using the given strategy
+func NewTranscriber(transcribers []provider.Transcriber, strategy Strategy, options ...Option) (*Transcriber, error) {
+	b, err := newBalancer(transcribers, strategy, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transcriber{b}, nil
+}
+
+func (t *Transcriber) Transcribe(ctx context.Context, input provider.File, options *provider.TranscribeOptions) iter.Seq2[*provider.Transcription, error] {
+	return stream(ctx, t.balancer, func(ctx context.Context, transcriber provider.Transcriber) iter.Seq2[*provider.Transcription, error] {
+		return transcriber.Transcribe(ctx, input, options)
+	})
+}
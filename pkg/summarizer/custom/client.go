@@ -54,6 +54,8 @@ func (c *Client) Summarize(ctx context.Context, text string, options *summarizer
 
 	resp, err := c.client.Summarize(ctx, &SummarizeRequest{
 		Text: text,
+
+		Instructions: options.Instructions,
 	})
 
 	if err != nil {
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
+// 	protoc-gen-go v1.36.12
 // 	protoc        v5.29.3
 // source: summarizer.proto
 
@@ -22,8 +22,10 @@ const (
 )
 
 type SummarizeRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// instructions optionally focus the summary, e.g. on the question it should answer.
+	Instructions  string `protobuf:"bytes,2,opt,name=instructions,proto3" json:"instructions,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,6 +67,13 @@ func (x *SummarizeRequest) GetText() string {
 	return ""
 }
 
+func (x *SummarizeRequest) GetInstructions() string {
+	if x != nil {
+		return x.Instructions
+	}
+	return ""
+}
+
 type Summary struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
@@ -114,9 +123,10 @@ var File_summarizer_proto protoreflect.FileDescriptor
 const file_summarizer_proto_rawDesc = "" +
 	"\n" +
 	"\x10summarizer.proto\x12\n" +
-	"summarizer\"&\n" +
+	"summarizer\"J\n" +
 	"\x10SummarizeRequest\x12\x12\n" +
-	"\x04text\x18\x01 \x01(\tR\x04text\"\x1d\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\"\n" +
+	"\finstructions\x18\x02 \x01(\tR\finstructions\"\x1d\n" +
 	"\aSummary\x12\x12\n" +
 	"\x04text\x18\x01 \x01(\tR\x04text2N\n" +
 	"\n" +
@@ -7,6 +7,8 @@ type Provider interface {
 }
 
 type SummarizeOptions struct {
+	// Instructions optionally focus the summary, e.g. on the question it should answer.
+	Instructions string
 }
 
 type Summary struct {
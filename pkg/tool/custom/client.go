@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"strings"
+	"sync/atomic"
 
+	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/tool"
 
 	"google.golang.org/grpc"
@@ -16,11 +19,15 @@ import (
 
 var (
 	_ tool.Provider = (*Client)(nil)
+	_ tool.Resulter = (*Client)(nil)
 )
 
 type Client struct {
 	url    string
 	client ToolClient
+
+	// streaming is set once the plugin advertised ExecuteStream
+	streaming atomic.Bool
 }
 
 func New(url string, options ...Option) (*Client, error) {
@@ -57,6 +64,8 @@ func (c *Client) Tools(ctx context.Context) ([]tool.Tool, error) {
 		return nil, err
 	}
 
+	c.streaming.Store(resp.GetStreaming())
+
 	var tools []tool.Tool
 
 	for _, d := range resp.GetDefinitions() {
@@ -78,34 +87,122 @@ func (c *Client) Execute(ctx context.Context, name string, parameters map[string
 		return nil, err
 	}
 
-	resp, err := c.client.Execute(ctx, &ExecuteRequest{
+	req := &ExecuteRequest{
 		Name:       name,
 		Parameters: string(params),
-	})
+	}
+
+	// The advertised flag alone picks the call; a stream failing part way
+	// must not run the tool a second time
+	if c.streaming.Load() {
+		return c.executeStream(ctx, req)
+	}
+
+	resp, err := c.client.Execute(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseData(resp.GetData()), nil
+}
+
+// executeStream runs a tool over ExecuteStream, reporting its progress. A
+// result of text only is parsed like the one of Execute; one with files is
+// returned as parts.
+func (c *Client) executeStream(ctx context.Context, req *ExecuteRequest) (any, error) {
+	stream, err := c.client.ExecuteStream(ctx, req)
 
 	if err != nil {
 		return nil, err
 	}
 
-	data := resp.GetData()
+	var parts []provider.Part
+	var files bool
+
+	for {
+		event, err := stream.Recv()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if event.Progress != "" {
+			tool.ReportProgress(ctx, event.Progress)
+		}
+
+		if event.Text != "" {
+			// consecutive text forms one part
+			if n := len(parts); n > 0 && parts[n-1].File == nil {
+				parts[n-1].Text += event.Text
+			} else {
+				parts = append(parts, provider.Part{Text: event.Text})
+			}
+		}
+
+		if f := event.File; f != nil {
+			files = true
+
+			parts = append(parts, provider.Part{
+				File: &provider.File{
+					Name: f.Name,
+
+					Content:     f.Content,
+					ContentType: f.ContentType,
+				},
+			})
+		}
+	}
+
+	if files {
+		return parts, nil
+	}
+
+	var text string
+
+	if len(parts) > 0 {
+		text = parts[0].Text
+	}
+
+	return parseData(text), nil
+}
+
+// Result passes text and files of streamed results to the model as they are
+func (c *Client) Result(name string, value any) provider.ToolResult {
+	switch v := value.(type) {
+	case []provider.Part:
+		return provider.ToolResult{Parts: v}
+
+	case string:
+		return provider.ToolResult{Parts: []provider.Part{{Text: v}}}
+	}
+
+	data, _ := json.Marshal(value)
+	return provider.ToolResult{Parts: []provider.Part{{Text: string(data)}}}
+}
 
+func parseData(data string) any {
 	var object map[string]any
 
 	if err := json.Unmarshal([]byte(data), &object); err == nil {
-		return object, nil
+		return object
 	}
 
 	var list []any
 
 	if err := json.Unmarshal([]byte(data), &list); err == nil {
-		return list, nil
+		return list
 	}
 
 	var document map[string]any
 
 	if err := yaml.Unmarshal([]byte(data), &document); err == nil {
-		return document, nil
+		return document
 	}
 
-	return data, nil
+	return data
 }
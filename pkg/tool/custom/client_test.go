@@ -0,0 +1,178 @@
+package custom
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/tool"
+
+	"google.golang.org/grpc"
+)
+
+type testServer struct {
+	UnimplementedToolServer
+
+	streaming bool
+	cancelled chan struct{}
+}
+
+func (s *testServer) Tools(ctx context.Context, req *ToolsRequest) (*ToolsResponse, error) {
+	return &ToolsResponse{
+		Definitions: []*Definition{{Name: "render"}, {Name: "wait"}},
+		Streaming:   s.streaming,
+	}, nil
+}
+
+func (s *testServer) Execute(ctx context.Context, req *ExecuteRequest) (*ResultResponse, error) {
+	return &ResultResponse{Data: `{"unary":true}`}, nil
+}
+
+func (s *testServer) ExecuteStream(req *ExecuteRequest, stream grpc.ServerStreamingServer[ExecuteEvent]) error {
+	if req.Name == "wait" {
+		stream.Send(&ExecuteEvent{Progress: "waiting"})
+
+		<-stream.Context().Done()
+		close(s.cancelled)
+
+		return stream.Context().Err()
+	}
+
+	events := []*ExecuteEvent{
+		{Progress: "rendering"},
+		{Text: "Here is "},
+		{Text: "the chart"},
+		{File: &File{Name: "chart.png", Content: []byte{0x89, 'P', 'N', 'G'}, ContentType: "image/png"}},
+	}
+
+	for _, e := range events {
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func serve(t *testing.T, server ToolServer) *Client {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := grpc.NewServer()
+	RegisterToolServer(s, server)
+
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	c, err := New("grpc://" + l.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Tools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestExecuteStream(t *testing.T) {
+	c := serve(t, &testServer{streaming: true})
+
+	var progress []string
+
+	ctx := tool.WithProgress(context.Background(), func(message string) {
+		progress = append(progress, message)
+	})
+
+	value, err := c.Execute(ctx, "render", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(progress) != 1 || progress[0] != "rendering" {
+		t.Fatalf("unexpected progress: %v", progress)
+	}
+
+	parts := c.Result("render", value).Parts
+
+	if len(parts) != 2 || parts[0].Text != "Here is the chart" {
+		t.Fatalf("unexpected parts: %v", parts)
+	}
+
+	if f := parts[1].File; f == nil || f.Name != "chart.png" || f.ContentType != "image/png" || len(f.Content) != 4 {
+		t.Fatalf("unexpected file: %v", parts[1])
+	}
+}
+
+func TestExecuteStreamCancel(t *testing.T) {
+	server := &testServer{streaming: true, cancelled: make(chan struct{})}
+	c := serve(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ctx = tool.WithProgress(ctx, func(message string) {
+		cancel()
+	})
+
+	if _, err := c.Execute(ctx, "wait", nil); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	select {
+	case <-server.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the plugin to see the cancellation")
+	}
+}
+
+func TestExecuteUnary(t *testing.T) {
+	c := serve(t, &testServer{})
+
+	value, err := c.Execute(context.Background(), "render", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if object, ok := value.(map[string]any); !ok || object["unary"] != true {
+		t.Fatalf("expected the unary result, got %v", value)
+	}
+}
+
+type unaryServer struct {
+	UnimplementedToolServer
+
+	calls atomic.Int32
+}
+
+func (s *unaryServer) Tools(ctx context.Context, req *ToolsRequest) (*ToolsResponse, error) {
+	return &ToolsResponse{Streaming: true}, nil
+}
+
+func (s *unaryServer) Execute(ctx context.Context, req *ExecuteRequest) (*ResultResponse, error) {
+	s.calls.Add(1)
+	return &ResultResponse{Data: "plain text"}, nil
+}
+
+func TestExecuteNoFallback(t *testing.T) {
+	server := &unaryServer{}
+	c := serve(t, server)
+
+	if _, err := c.Execute(context.Background(), "render", nil); err == nil {
+		t.Fatal("expected the failed stream to be reported")
+	}
+
+	if n := server.calls.Load(); n != 0 {
+		t.Fatalf("expected no unary call after the stream, got %d", n)
+	}
+}
@@ -19,8 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Tool_Tools_FullMethodName   = "/tool.Tool/Tools"
-	Tool_Execute_FullMethodName = "/tool.Tool/Execute"
+	Tool_Tools_FullMethodName         = "/tool.Tool/Tools"
+	Tool_Execute_FullMethodName       = "/tool.Tool/Execute"
+	Tool_ExecuteStream_FullMethodName = "/tool.Tool/ExecuteStream"
 )
 
 // ToolClient is the client API for Tool service.
@@ -29,6 +30,9 @@ const (
 type ToolClient interface {
 	Tools(ctx context.Context, in *ToolsRequest, opts ...grpc.CallOption) (*ToolsResponse, error)
 	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ResultResponse, error)
+	// ExecuteStream runs a tool, reporting its progress and result as it goes.
+	// The call is cancelled when the tool call is.
+	ExecuteStream(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecuteEvent], error)
 }
 
 type toolClient struct {
@@ -59,12 +63,34 @@ func (c *toolClient) Execute(ctx context.Context, in *ExecuteRequest, opts ...gr
 	return out, nil
 }
 
+func (c *toolClient) ExecuteStream(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecuteEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Tool_ServiceDesc.Streams[0], Tool_ExecuteStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExecuteRequest, ExecuteEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Tool_ExecuteStreamClient = grpc.ServerStreamingClient[ExecuteEvent]
+
 // ToolServer is the server API for Tool service.
 // All implementations must embed UnimplementedToolServer
 // for forward compatibility.
 type ToolServer interface {
 	Tools(context.Context, *ToolsRequest) (*ToolsResponse, error)
 	Execute(context.Context, *ExecuteRequest) (*ResultResponse, error)
+	// ExecuteStream runs a tool, reporting its progress and result as it goes.
+	// The call is cancelled when the tool call is.
+	ExecuteStream(*ExecuteRequest, grpc.ServerStreamingServer[ExecuteEvent]) error
 	mustEmbedUnimplementedToolServer()
 }
 
@@ -81,6 +107,9 @@ func (UnimplementedToolServer) Tools(context.Context, *ToolsRequest) (*ToolsResp
 func (UnimplementedToolServer) Execute(context.Context, *ExecuteRequest) (*ResultResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
 }
+func (UnimplementedToolServer) ExecuteStream(*ExecuteRequest, grpc.ServerStreamingServer[ExecuteEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteStream not implemented")
+}
 func (UnimplementedToolServer) mustEmbedUnimplementedToolServer() {}
 func (UnimplementedToolServer) testEmbeddedByValue()              {}
 
@@ -138,6 +167,17 @@ func _Tool_Execute_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Tool_ExecuteStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecuteRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ToolServer).ExecuteStream(m, &grpc.GenericServerStream[ExecuteRequest, ExecuteEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Tool_ExecuteStreamServer = grpc.ServerStreamingServer[ExecuteEvent]
+
 // Tool_ServiceDesc is the grpc.ServiceDesc for Tool service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -154,6 +194,12 @@ var Tool_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Tool_Execute_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecuteStream",
+			Handler:       _Tool_ExecuteStream_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "tool.proto",
 }
@@ -0,0 +1,143 @@
+// Package delegate exposes an agent as a tool of other agents. The calling
+// model hands over a self-contained task; the agent's final answer is the
+// tool result.
+package delegate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+const defaultMaxDepth = 3
+
+var (
+	_ tool.Provider = (*Client)(nil)
+	_ tool.Resulter = (*Client)(nil)
+)
+
+var invalidName = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+type Client struct {
+	name        string
+	description string
+
+	agent provider.Completer
+
+	maxDepth int
+}
+
+// New exposes the agent as a tool named after it. Characters not allowed in
+// tool names are replaced by underscores.
+func New(name string, agent provider.Completer, options ...Option) (*Client, error) {
+	if agent == nil {
+		return nil, errors.New("delegate: missing agent")
+	}
+
+	name = invalidName.ReplaceAllString(name, "_")
+
+	if name == "" {
+		return nil, errors.New("delegate: missing name")
+	}
+
+	c := &Client{
+		name:  name,
+		agent: agent,
+
+		maxDepth: defaultMaxDepth,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.description == "" {
+		c.description = fmt.Sprintf("Delegate a task to the %q agent and get back its final answer. The agent does not see this conversation, so describe the task completely.", name)
+	}
+
+	return c, nil
+}
+
+func (c *Client) Tools(ctx context.Context) ([]tool.Tool, error) {
+	return []tool.Tool{
+		{
+			Name:        c.name,
+			Description: c.description,
+
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"task": map[string]any{
+						"type":        "string",
+						"description": "A clear, self-contained description of what the agent should do and what its answer should contain.",
+					},
+					"context": map[string]any{
+						"type":        "string",
+						"description": "Optional background the agent needs, such as facts, data or earlier findings from this conversation.",
+					},
+				},
+				"required": []string{"task"},
+			},
+		},
+	}, nil
+}
+
+func (c *Client) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	if name != c.name {
+		return nil, tool.ErrInvalidTool
+	}
+
+	task, _ := parameters["task"].(string)
+	task = strings.TrimSpace(task)
+
+	if task == "" {
+		return nil, errors.New("delegate: missing task parameter")
+	}
+
+	depth := agent.Depth(ctx)
+
+	if depth >= c.maxDepth {
+		return nil, fmt.Errorf("delegate: delegation depth limit of %d reached, solve the task without %q", c.maxDepth, c.name)
+	}
+
+	ctx = agent.WithDepth(ctx, depth+1)
+
+	if background, _ := parameters["context"].(string); strings.TrimSpace(background) != "" {
+		task = "<context>\n" + strings.TrimSpace(background) + "\n</context>\n\n" + task
+	}
+
+	messages := []provider.Message{
+		provider.UserMessage(task),
+	}
+
+	acc := provider.CompletionAccumulator{}
+
+	for completion, err := range c.agent.Complete(ctx, messages, nil) {
+		if err != nil {
+			return nil, err
+		}
+
+		acc.Add(*completion)
+	}
+
+	result := acc.Result()
+
+	if result.Message == nil {
+		return "", nil
+	}
+
+	return result.Message.Text(), nil
+}
+
+// Result implements tool.Resulter so the calling agent sees the answer as
+// plain text instead of a JSON-quoted blob.
+func (c *Client) Result(name string, value any) provider.ToolResult {
+	text, _ := value.(string)
+	return provider.ToolResult{Parts: []provider.Part{{Text: text}}}
+}
@@ -0,0 +1,99 @@
+package delegate
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+type fakeAgent struct {
+	messages []provider.Message
+	depth    int
+}
+
+func (f *fakeAgent) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		f.messages = messages
+		f.depth = agent.Depth(ctx)
+
+		for _, text := range []string{"the ", "answer"} {
+			if !yield(&provider.Completion{
+				Message: &provider.Message{
+					Role:    provider.MessageRoleAssistant,
+					Content: []provider.Content{provider.TextContent(text)},
+				},
+			}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestNew_SanitizesName(t *testing.T) {
+	c, err := New("research agent.v2", &fakeAgent{})
+
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tools, _ := c.Tools(context.Background())
+
+	if len(tools) != 1 || tools[0].Name != "research_agent_v2" {
+		t.Fatalf("unexpected tools: %+v", tools)
+	}
+}
+
+func TestExecute_ReturnsFinalAnswer(t *testing.T) {
+	a := &fakeAgent{}
+	c, _ := New("researcher", a)
+
+	result, err := c.Execute(context.Background(), "researcher", map[string]any{
+		"task":    "Summarize the findings",
+		"context": "The build fails on arm64",
+	})
+
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if result != "the answer" {
+		t.Fatalf("expected the accumulated answer, got %q", result)
+	}
+
+	if a.depth != 1 {
+		t.Fatalf("expected the agent to run at depth 1, got %d", a.depth)
+	}
+
+	prompt := a.messages[0].Text()
+
+	if !strings.Contains(prompt, "The build fails on arm64") || !strings.HasSuffix(prompt, "Summarize the findings") {
+		t.Fatalf("unexpected prompt: %q", prompt)
+	}
+}
+
+func TestExecute_DepthLimit(t *testing.T) {
+	a := &fakeAgent{}
+	c, _ := New("researcher", a, WithMaxDepth(2))
+
+	ctx := agent.WithDepth(context.Background(), 2)
+
+	if _, err := c.Execute(ctx, "researcher", map[string]any{"task": "loop"}); err == nil {
+		t.Fatal("expected the depth limit to refuse the call")
+	}
+
+	if a.messages != nil {
+		t.Fatal("expected the agent not to run")
+	}
+}
+
+func TestExecute_RequiresTask(t *testing.T) {
+	c, _ := New("researcher", &fakeAgent{})
+
+	if _, err := c.Execute(context.Background(), "researcher", map[string]any{}); err == nil {
+		t.Fatal("expected error for missing task")
+	}
+}
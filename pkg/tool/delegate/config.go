@@ -0,0 +1,20 @@
+package delegate
+
+type Option func(*Client)
+
+// WithDescription tells the calling model what the agent is good at
+func WithDescription(description string) Option {
+	return func(c *Client) {
+		c.description = description
+	}
+}
+
+// WithMaxDepth limits how many agents may be stacked through delegation
+// before calls are refused (default 3)
+func WithMaxDepth(depth int) Option {
+	return func(c *Client) {
+		if depth > 0 {
+			c.maxDepth = depth
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// Package editor runs the text editor tool on the server, against the
+// workspace of the session, so agents can draft and revise files across the
+// turns of a session without a client applying the edits.
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/provider/tools/texteditor"
+	"github.com/adrianliechti/wingman/pkg/tool"
+	"github.com/adrianliechti/wingman/pkg/workspace"
+)
+
+var (
+	_ tool.Provider = (*Client)(nil)
+	_ tool.Resulter = (*Client)(nil)
+)
+
+type Client struct {
+	workspace workspace.Provider
+
+	// dialect is the tool name of the editing dialect offered to the model
+	dialect string
+}
+
+func New(workspace workspace.Provider, options ...Option) (*Client, error) {
+	if workspace == nil {
+		return nil, errors.New("editor: missing workspace")
+	}
+
+	c := &Client{
+		workspace: workspace,
+
+		dialect: texteditor.NameTextEditor,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.dialect != texteditor.NameTextEditor && c.dialect != texteditor.NameApplyPatch {
+		return nil, errors.New("editor: invalid dialect: " + c.dialect)
+	}
+
+	return c, nil
+}
+
+func (c *Client) Tools(ctx context.Context) ([]tool.Tool, error) {
+	return []tool.Tool{
+		texteditor.FunctionTool(provider.Tool{Name: c.dialect}),
+	}, nil
+}
+
+func (c *Client) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	if name != c.dialect {
+		return nil, tool.ErrInvalidTool
+	}
+
+	session := agent.Session(ctx)
+
+	if session == "" {
+		return nil, errors.New("the editor is only available within a session")
+	}
+
+	key := workspace.Key(ctx, session)
+
+	args, _ := json.Marshal(parameters)
+
+	if name == texteditor.NameApplyPatch {
+		return c.applyPatch(ctx, key, texteditor.ParseOperation(string(args)))
+	}
+
+	return c.edit(ctx, key, texteditor.ParseInput(string(args)))
+}
+
+// Result passes the outcome to the model as text
+func (c *Client) Result(name string, value any) provider.ToolResult {
+	text, _ := value.(string)
+	return provider.ToolResult{Parts: []provider.Part{{Text: text}}}
+}
+
+// edit runs a text editor command
+func (c *Client) edit(ctx context.Context, key string, in texteditor.Input) (string, error) {
+	switch in.Command {
+	case "view":
+		return c.view(ctx, key, in.Path, in.ViewRange)
+
+	case "create":
+		path, err := workspace.Clean(in.Path)
+
+		if err != nil {
+			return "", err
+		}
+
+		if err := c.workspace.Write(ctx, key, path, []byte(in.FileText)); err != nil {
+			return "", err
+		}
+
+		return "File created successfully at: " + path, nil
+
+	case "str_replace":
+		path, content, err := c.read(ctx, key, in.Path)
+
+		if err != nil {
+			return "", err
+		}
+
+		if in.OldStr == "" {
+			return "", errors.New("old_str must not be empty")
+		}
+
+		switch n := strings.Count(content, in.OldStr); n {
+		case 0:
+			return "", fmt.Errorf("no replacement was performed, old_str did not appear verbatim in %s", path)
+
+		case 1:
+
+		default:
+			return "", fmt.Errorf("no replacement was performed, old_str appears %d times in %s; include more context to make it unique", n, path)
+		}
+
+		content = strings.Replace(content, in.OldStr, in.NewStr, 1)
+
+		if err := c.workspace.Write(ctx, key, path, []byte(content)); err != nil {
+			return "", err
+		}
+
+		return "The file " + path + " has been edited.", nil
+
+	case "insert":
+		path, content, err := c.read(ctx, key, in.Path)
+
+		if err != nil {
+			return "", err
+		}
+
+		if in.InsertLine == nil {
+			return "", errors.New("missing insert_line")
+		}
+
+		text := in.InsertText
+
+		// Earlier versions of the tool passed the text as new_str
+		if text == "" {
+			text = in.NewStr
+		}
+
+		lines, eol := splitLines(content)
+
+		line := *in.InsertLine
+
+		if line < 0 || line > len(lines) {
+			return "", fmt.Errorf("invalid insert_line %d: %s has %d lines", line, path, len(lines))
+		}
+
+		inserted, _ := splitLines(text)
+
+		lines = slices.Insert(lines, line, inserted...)
+
+		if err := c.workspace.Write(ctx, key, path, []byte(joinLines(lines, eol || content == ""))); err != nil {
+			return "", err
+		}
+
+		return "The file " + path + " has been edited.", nil
+
+	default:
+		return "", fmt.Errorf("unsupported command %q; use view, create, str_replace or insert", in.Command)
+	}
+}
+
+// view shows a file with line numbers, or lists the files below a directory
+func (c *Client) view(ctx context.Context, key, p string, viewRange []int) (string, error) {
+	dir := strings.Trim(p, "/.")
+
+	files, err := c.workspace.List(ctx, key)
+
+	if err != nil {
+		return "", err
+	}
+
+	var listing []string
+
+	for _, f := range files {
+		if dir == "" || strings.HasPrefix(f.Path, dir+"/") {
+			listing = append(listing, fmt.Sprintf("%d\t%s", f.Size, f.Path))
+		}
+	}
+
+	if dir == "" || len(listing) > 0 {
+		if len(listing) == 0 {
+			return "The workspace is empty.", nil
+		}
+
+		return "Files in the workspace (size in bytes and path):\n" + strings.Join(listing, "\n"), nil
+	}
+
+	path, content, err := c.read(ctx, key, p)
+
+	if err != nil {
+		return "", err
+	}
+
+	lines, _ := splitLines(content)
+
+	start, end := 1, len(lines)
+
+	if len(viewRange) == 2 {
+		start = viewRange[0]
+
+		if viewRange[1] != -1 {
+			end = viewRange[1]
+		}
+
+		if start < 1 || start > max(len(lines), 1) || end < start || end > len(lines) {
+			return "", fmt.Errorf("invalid view_range %v: %s has %d lines", viewRange, path, len(lines))
+		}
+	}
+
+	var b strings.Builder
+
+	for i := start; i <= end; i++ {
+		fmt.Fprintf(&b, "%6d\t%s\n", i, lines[i-1])
+	}
+
+	return b.String(), nil
+}
+
+func (c *Client) read(ctx context.Context, key, p string) (string, string, error) {
+	path, err := workspace.Clean(p)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := c.workspace.Read(ctx, key, path)
+
+	if errors.Is(err, workspace.ErrNotFound) {
+		return "", "", fmt.Errorf("the file %s does not exist", path)
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return path, string(data), nil
+}
+
+// splitLines splits text into lines and reports whether it ended with a
+// line break
+func splitLines(text string) ([]string, bool) {
+	if text == "" {
+		return nil, false
+	}
+
+	eol := strings.HasSuffix(text, "\n")
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), eol
+}
+
+func joinLines(lines []string, eol bool) string {
+	text := strings.Join(lines, "\n")
+
+	if eol && len(lines) > 0 {
+		text += "\n"
+	}
+
+	return text
+}
@@ -0,0 +1,120 @@
+package editor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/provider/tools/texteditor"
+	"github.com/adrianliechti/wingman/pkg/workspace"
+	"github.com/adrianliechti/wingman/pkg/workspace/mem"
+)
+
+func edit(t *testing.T, c *Client, ctx context.Context, args map[string]any) string {
+	t.Helper()
+
+	result, err := c.Execute(ctx, c.dialect, args)
+
+	if err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+
+	return result.(string)
+}
+
+func TestTextEditor(t *testing.T) {
+	c, _ := New(mem.New())
+
+	ctx := agent.WithSession(context.Background(), "s1")
+
+	edit(t, c, ctx, map[string]any{"command": "create", "path": "/draft.md", "file_text": "# Title\n\nfirst\n"})
+	edit(t, c, ctx, map[string]any{"command": "str_replace", "path": "draft.md", "old_str": "first", "new_str": "second"})
+	edit(t, c, ctx, map[string]any{"command": "insert", "path": "draft.md", "insert_line": 1, "insert_text": "intro"})
+
+	if out := edit(t, c, ctx, map[string]any{"command": "view", "path": "draft.md"}); out != "     1\t# Title\n     2\tintro\n     3\t\n     4\tsecond\n" {
+		t.Fatalf("unexpected view %q", out)
+	}
+
+	if out := edit(t, c, ctx, map[string]any{"command": "view", "path": "draft.md", "view_range": []any{3, -1}}); out != "     3\t\n     4\tsecond\n" {
+		t.Fatalf("unexpected view range %q", out)
+	}
+
+	if out := edit(t, c, ctx, map[string]any{"command": "view", "path": "/"}); !strings.Contains(out, "draft.md") {
+		t.Fatalf("expected the listing to contain the draft, got %q", out)
+	}
+
+	if _, err := c.Execute(ctx, c.dialect, map[string]any{"command": "str_replace", "path": "draft.md", "old_str": "missing", "new_str": "x"}); err == nil {
+		t.Fatal("expected an error for text not in the file")
+	}
+
+	other := context.WithValue(ctx, auth.UserContextKey, "mallory")
+
+	if _, err := c.Execute(other, c.dialect, map[string]any{"command": "view", "path": "draft.md"}); err == nil {
+		t.Fatal("expected sessions of other users to have their own workspace")
+	}
+
+	if _, err := c.Execute(context.Background(), c.dialect, map[string]any{"command": "view", "path": "/"}); err == nil {
+		t.Fatal("expected an error without a session")
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	c, _ := New(mem.New(), WithDialect("apply_patch"))
+
+	if c.dialect != texteditor.NameApplyPatch {
+		t.Fatalf("unexpected dialect %q", c.dialect)
+	}
+
+	ctx := agent.WithSession(context.Background(), "s1")
+
+	edit(t, c, ctx, map[string]any{"type": "create_file", "path": "main.go", "diff": "+package main\n+\n+func a() {\n+\treturn\n+}\n+\n+func b() {\n+\treturn\n+}\n"})
+
+	edit(t, c, ctx, map[string]any{"type": "update_file", "path": "main.go", "diff": "@@ func b() {\n-\treturn\n+\tprintln(\"b\")\n"})
+
+	data, _ := c.workspace.Read(ctx, workspaceKey(ctx), "main.go")
+
+	if expected := "package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\tprintln(\"b\")\n}\n"; string(data) != expected {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	envelope := "*** Begin Patch\n*** Add File: b.txt\n+b\n*** Delete File: main.go\n*** End Patch\n"
+
+	if out := edit(t, c, ctx, map[string]any{"diff": envelope}); out != "Created b.txt\nDeleted main.go" {
+		t.Fatalf("unexpected result %q", out)
+	}
+
+	if _, err := c.Execute(ctx, c.dialect, map[string]any{"type": "update_file", "path": "b.txt", "diff": "@@\n-a\n+c\n"}); err == nil {
+		t.Fatal("expected an error for a hunk not matching the file")
+	}
+}
+
+func TestApplyDiff(t *testing.T) {
+	tests := []struct {
+		content string
+		diff    string
+		result  string
+	}{
+		{"a\nb\nc\n", "@@\n a\n-b\n+B\n c\n", "a\nB\nc\n"},
+		{"a\nb  \nc\n", "@@\n-b\n+B\n", "a\nB\nc\n"},
+		{"x\ny\nx\ny\n", "@@\n x\n-y\n+1\n@@\n x\n-y\n+2\n", "x\n1\nx\n2\n"},
+		{"a\n", "@@\n+b\n", "a\nb\n"},
+	}
+
+	for _, test := range tests {
+		result, err := applyDiff(test.content, test.diff)
+
+		if err != nil {
+			t.Fatalf("%q: %v", test.diff, err)
+		}
+
+		if result != test.result {
+			t.Fatalf("%q: expected %q, got %q", test.diff, test.result, result)
+		}
+	}
+}
+
+func workspaceKey(ctx context.Context) string {
+	return workspace.Key(ctx, agent.Session(ctx))
+}
@@ -0,0 +1,24 @@
+package editor
+
+import (
+	"github.com/adrianliechti/wingman/pkg/provider/tools/texteditor"
+)
+
+type Option func(*Client)
+
+// WithDialect offers the editor as the apply_patch tool instead of the
+// str_replace_based_edit_tool of the text_editor dialect
+func WithDialect(dialect string) Option {
+	return func(c *Client) {
+		switch dialect {
+		case "", "text_editor":
+			c.dialect = texteditor.NameTextEditor
+
+		case "apply_patch":
+			c.dialect = texteditor.NameApplyPatch
+
+		default:
+			c.dialect = dialect
+		}
+	}
+}
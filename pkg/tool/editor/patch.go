@@ -0,0 +1,230 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/provider/tools/texteditor"
+	"github.com/adrianliechti/wingman/pkg/workspace"
+)
+
+// applyPatch runs an apply_patch operation. A raw patch envelope passed as
+// the diff may hold several operations, which are applied in order.
+func (c *Client) applyPatch(ctx context.Context, key string, op texteditor.Operation) (string, error) {
+	ops := []texteditor.Operation{op}
+
+	if op.Type == "" && texteditor.IsEnvelope(op.Diff) {
+		ops = texteditor.ParseEnvelopeOperations(op.Diff)
+	}
+
+	var results []string
+
+	for _, op := range ops {
+		result, err := c.applyOperation(ctx, key, op)
+
+		if err != nil {
+			return "", err
+		}
+
+		results = append(results, result)
+	}
+
+	return strings.Join(results, "\n"), nil
+}
+
+func (c *Client) applyOperation(ctx context.Context, key string, op texteditor.Operation) (string, error) {
+	switch op.Type {
+	case "create_file":
+		path, err := workspace.Clean(op.Path)
+
+		if err != nil {
+			return "", err
+		}
+
+		var lines []string
+
+		for line := range strings.Lines(op.Diff) {
+			if rest, ok := strings.CutPrefix(strings.TrimSuffix(line, "\n"), "+"); ok {
+				lines = append(lines, rest)
+			}
+		}
+
+		if err := c.workspace.Write(ctx, key, path, []byte(joinLines(lines, true))); err != nil {
+			return "", err
+		}
+
+		return "Created " + path, nil
+
+	case "update_file":
+		path, content, err := c.read(ctx, key, op.Path)
+
+		if err != nil {
+			return "", err
+		}
+
+		content, err = applyDiff(content, op.Diff)
+
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+
+		if err := c.workspace.Write(ctx, key, path, []byte(content)); err != nil {
+			return "", err
+		}
+
+		return "Updated " + path, nil
+
+	case "delete_file":
+		path, err := workspace.Clean(op.Path)
+
+		if err != nil {
+			return "", err
+		}
+
+		if err := c.workspace.Delete(ctx, key, path); err != nil {
+			if errors.Is(err, workspace.ErrNotFound) {
+				return "", fmt.Errorf("the file %s does not exist", path)
+			}
+
+			return "", err
+		}
+
+		return "Deleted " + path, nil
+
+	default:
+		return "", fmt.Errorf("unsupported operation %q; use create_file, update_file or delete_file", op.Type)
+	}
+}
+
+type hunk struct {
+	anchor string
+
+	old []string
+	new []string
+}
+
+// parseDiff splits a V4A diff into hunks. Each "@@" line starts a hunk and
+// may name a line to search from, such as the signature of a function.
+func parseDiff(diff string) []hunk {
+	var hunks []hunk
+
+	for line := range strings.Lines(diff) {
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if strings.HasPrefix(line, "***") {
+			// end of file marker or other envelope lines
+			continue
+		}
+
+		if anchor, ok := strings.CutPrefix(line, "@@"); ok {
+			hunks = append(hunks, hunk{anchor: strings.TrimSpace(anchor)})
+			continue
+		}
+
+		if len(hunks) == 0 {
+			hunks = append(hunks, hunk{})
+		}
+
+		h := &hunks[len(hunks)-1]
+
+		switch {
+		case strings.HasPrefix(line, "-"):
+			h.old = append(h.old, line[1:])
+
+		case strings.HasPrefix(line, "+"):
+			h.new = append(h.new, line[1:])
+
+		default:
+			line = strings.TrimPrefix(line, " ")
+
+			h.old = append(h.old, line)
+			h.new = append(h.new, line)
+		}
+	}
+
+	return hunks
+}
+
+// applyDiff applies the hunks of a V4A diff in order. The old lines of each
+// hunk must appear after the previous hunk; lines are compared exactly first
+// and then ignoring trailing whitespace, which models tend to get wrong.
+func applyDiff(content, diff string) (string, error) {
+	lines, eol := splitLines(content)
+
+	cursor := 0
+
+	for i, h := range parseDiff(diff) {
+		start := cursor
+
+		if h.anchor != "" {
+			n := findLines(lines, []string{h.anchor}, start, func(line, anchor string) bool {
+				return strings.TrimSpace(line) == anchor
+			})
+
+			if n < 0 {
+				return "", fmt.Errorf("hunk %d: anchor %q not found", i+1, h.anchor)
+			}
+
+			// the anchor line often repeats as the first context line
+			start = n
+		}
+
+		// A hunk adding lines without any context appends to the file, or
+		// inserts right below its anchor
+		if len(h.old) == 0 {
+			at := len(lines)
+
+			if h.anchor != "" {
+				at = start + 1
+			}
+
+			lines = slices.Insert(lines, at, h.new...)
+			cursor = at + len(h.new)
+
+			continue
+		}
+
+		n := findLines(lines, h.old, start, func(a, b string) bool {
+			return a == b
+		})
+
+		if n < 0 {
+			n = findLines(lines, h.old, start, func(a, b string) bool {
+				return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t")
+			})
+		}
+
+		if n < 0 {
+			return "", fmt.Errorf("hunk %d does not match the file", i+1)
+		}
+
+		lines = slices.Replace(lines, n, n+len(h.old), h.new...)
+		cursor = n + len(h.new)
+	}
+
+	return joinLines(lines, eol || content == ""), nil
+}
+
+// findLines returns the index of the first occurrence of block in lines at
+// or after start, or -1
+func findLines(lines, block []string, start int, equal func(a, b string) bool) int {
+	for i := start; i+len(block) <= len(lines); i++ {
+		match := true
+
+		for j := range block {
+			if !equal(lines[i+j], block[j]) {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,6 +24,9 @@ var (
 
 type Client struct {
 	transport mcp.Transport
+
+	// process is the supervised server of a stdio client
+	process *process
 }
 
 func New(url string, headers map[string]string, exchanger auth.TokenExchanger) (*Client, error) {
@@ -56,6 +60,48 @@ func New(url string, headers map[string]string, exchanger auth.TokenExchanger) (
 	return c, nil
 }
 
+// NewCommand runs the MCP server command with args as a child process and
+// talks to it over stdio. The process gets a minimal environment plus env.
+// The process is started on first use and restarted when it exits; Close
+// stops it.
+func NewCommand(command string, args []string, env map[string]string) (*Client, error) {
+	if command == "" {
+		return nil, errors.New("mcp: missing command")
+	}
+
+	c := &Client{
+		process: newProcess(command, args, env),
+	}
+
+	return c, nil
+}
+
+// Close stops the process of a stdio client
+func (c *Client) Close() error {
+	if c.process == nil {
+		return nil
+	}
+
+	return c.process.Close()
+}
+
+// session returns a session and a function to release it. Calls share the
+// session of a stdio process; HTTP servers get a session per call.
+func (c *Client) session(ctx context.Context) (*mcp.ClientSession, func(), error) {
+	if c.process != nil {
+		session, err := c.process.Session(ctx)
+		return session, func() {}, err
+	}
+
+	session, err := c.createSession(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session, func() { session.Close() }, nil
+}
+
 func (c *Client) createSession(ctx context.Context) (*mcp.ClientSession, error) {
 	impl := &mcp.Implementation{
 		Name:    "wingman",
@@ -71,13 +117,13 @@ func (c *Client) createSession(ctx context.Context) (*mcp.ClientSession, error)
 }
 
 func (c *Client) Tools(ctx context.Context) ([]tool.Tool, error) {
-	session, err := c.createSession(ctx)
+	session, release, err := c.session(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer session.Close()
+	defer release()
 
 	var result []tool.Tool
 
@@ -97,17 +143,42 @@ func (c *Client) Tools(ctx context.Context) ([]tool.Tool, error) {
 		})
 	}
 
+	if list, read := resourceTools(session); list != "" {
+		for _, t := range resourceToolDefinitions(list, read) {
+			if !slices.ContainsFunc(result, func(r tool.Tool) bool { return r.Name == t.Name }) {
+				result = append(result, t)
+			}
+		}
+	}
+
 	return result, nil
 }
 
 func (c *Client) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
-	session, err := c.createSession(ctx)
+	session, release, err := c.session(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer session.Close()
+	defer release()
+
+	if list, read := resourceTools(session); name != "" && (name == list || name == read) {
+		// Tools skips the resource tools a real tool of the server shadows
+		shadowed, err := hasTool(ctx, session, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !shadowed {
+			if name == list {
+				return listResources(ctx, session)
+			}
+
+			return readResource(ctx, session, parameters)
+		}
+	}
 
 	result, err := session.CallTool(ctx, &mcp.CallToolParams{
 		Name:      name,
@@ -134,6 +205,14 @@ func (c *Client) Execute(ctx context.Context, name string, parameters map[string
 // Result implements tool.Resulter so the model sees the MCP content parts
 // (text, images, embedded resources) instead of the JSON-encoded SDK struct.
 func (c *Client) Result(name string, value any) provider.ToolResult {
+	switch v := value.(type) {
+	case string:
+		return provider.ToolResult{Parts: []provider.Part{{Text: v}}}
+
+	case *mcp.ReadResourceResult:
+		return resourceResult(v)
+	}
+
 	result, ok := value.(*mcp.CallToolResult)
 	if !ok {
 		data, _ := json.Marshal(value)
@@ -184,6 +263,27 @@ func (c *Client) Result(name string, value any) provider.ToolResult {
 	return provider.ToolResult{Parts: parts}
 }
 
+func resourceResult(result *mcp.ReadResourceResult) provider.ToolResult {
+	var parts []provider.Part
+
+	for _, content := range result.Contents {
+		if content.Text != "" {
+			parts = append(parts, provider.Part{Text: content.Text})
+			continue
+		}
+
+		if len(content.Blob) > 0 {
+			parts = append(parts, filePart(content.URI, content.MIMEType, content.Blob))
+		}
+	}
+
+	if len(parts) == 0 {
+		parts = append(parts, provider.Part{Text: "(no content)"})
+	}
+
+	return provider.ToolResult{Parts: parts}
+}
+
 // filePart wraps binary content the completers can forward to the model
 // (images, PDFs); other media becomes a text placeholder, since providers
 // reject unsupported content types for the whole request.
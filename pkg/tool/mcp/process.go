@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	processMinBackoff = time.Second
+	processMaxBackoff = time.Minute
+
+	// processStable is how long a process has to run before its earlier
+	// failures are forgotten
+	processStable = time.Minute
+)
+
+// processEnv lists the variables of the environment a process inherits;
+// everything else, like the API keys of the providers, is withheld
+var processEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_ALL", "TZ",
+	"TMPDIR", "TMP", "TEMP",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+	"SYSTEMROOT", "COMSPEC", "PATHEXT", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+}
+
+// process supervises a stdio MCP server. It keeps one session to the process,
+// which concurrent calls share, and restarts the process with backoff when it
+// exits.
+type process struct {
+	command string
+	args    []string
+	env     []string
+
+	// done is closed by Close to cut short a pending start
+	done chan struct{}
+
+	mu      sync.Mutex
+	session *mcp.ClientSession
+	closed  bool
+
+	// starting is closed once the start in progress finished
+	starting chan struct{}
+
+	failures int
+	retryAt  time.Time
+}
+
+func newProcess(command string, args []string, env map[string]string) *process {
+	p := &process{
+		command: command,
+		args:    args,
+
+		done: make(chan struct{}),
+	}
+
+	for _, key := range processEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			p.env = append(p.env, key+"="+value)
+		}
+	}
+
+	for key, value := range env {
+		p.env = append(p.env, key+"="+value)
+	}
+
+	return p
+}
+
+// Session returns the session to the process, starting it if it is not
+// running. Starts after a failure wait for the backoff to pass; the lock is
+// not held meanwhile, so Close never waits for a start.
+func (p *process) Session(ctx context.Context) (*mcp.ClientSession, error) {
+	for {
+		p.mu.Lock()
+
+		if p.closed {
+			p.mu.Unlock()
+			return nil, errors.New("mcp: process closed")
+		}
+
+		if session := p.session; session != nil {
+			p.mu.Unlock()
+			return session, nil
+		}
+
+		// Another call is starting the process already
+		if starting := p.starting; starting != nil {
+			p.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+
+			case <-starting:
+			}
+
+			continue
+		}
+
+		wait := time.Until(p.retryAt)
+
+		starting := make(chan struct{})
+		p.starting = starting
+
+		p.mu.Unlock()
+
+		session, err := p.start(ctx, wait)
+
+		p.mu.Lock()
+
+		p.starting = nil
+		close(starting)
+
+		if err != nil {
+			if ctx.Err() == nil && !p.closed {
+				p.fail()
+			}
+
+			p.mu.Unlock()
+			return nil, err
+		}
+
+		if p.closed {
+			p.mu.Unlock()
+
+			session.Close()
+			return nil, errors.New("mcp: process closed")
+		}
+
+		p.session = session
+		p.mu.Unlock()
+
+		go p.watch(session, time.Now())
+
+		return session, nil
+	}
+}
+
+// start waits for the backoff to pass and starts the process
+func (p *process) start(ctx context.Context, wait time.Duration) (*mcp.ClientSession, error) {
+	if wait > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-p.done:
+			return nil, errors.New("mcp: process closed")
+
+		case <-time.After(wait):
+		}
+	}
+
+	cmd := exec.Command(p.command, p.args...)
+	cmd.Env = p.env
+	cmd.Stderr = os.Stderr
+
+	client := mcp.NewClient(&mcp.Implementation{
+		Name:    "wingman",
+		Version: "1.0.0",
+	}, &mcp.ClientOptions{
+		KeepAlive: time.Second * 30,
+	})
+
+	session, err := client.Connect(ctx, &mcp.CommandTransport{Command: cmd}, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("mcp: starting %s: %w", p.command, err)
+	}
+
+	return session, nil
+}
+
+// watch clears the session once the process exits, so the next call starts
+// it again
+func (p *process) watch(session *mcp.ClientSession, started time.Time) {
+	err := session.Wait()
+
+	// reap the process; a no-op if the session was closed on purpose
+	session.Close()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.session != session {
+		return
+	}
+
+	p.session = nil
+
+	if time.Since(started) > processStable {
+		p.failures = 0
+	}
+
+	p.fail()
+
+	slog.Warn("mcp: process exited", "command", p.command, "error", err, "restart", time.Until(p.retryAt).Round(time.Second))
+}
+
+// fail delays the next start, doubling the delay with each failure in a row
+func (p *process) fail() {
+	backoff := processMinBackoff << min(p.failures, 6)
+
+	p.failures++
+	p.retryAt = time.Now().Add(min(backoff, processMaxBackoff))
+}
+
+// Close stops the process: its stdin is closed and it is terminated if it
+// does not exit in time
+func (p *process) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+
+	if p.session == nil {
+		return nil
+	}
+
+	session := p.session
+	p.session = nil
+
+	return session.Close()
+}
@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestMain lets the test binary act as a stdio MCP server when started by
+// the tests below
+func TestMain(m *testing.M) {
+	if os.Getenv("WINGMAN_TEST_MCP_SERVER") == "1" {
+		serveStdio()
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+func serveStdio() {
+	server := mcp.NewServer(&mcp.Implementation{Name: "stdio", Version: "1.0.0"}, nil)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "pid", Description: "returns the process id"},
+		func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprint(os.Getpid()) + " " + os.Getenv("GREETING") + os.Getenv("WINGMAN_TEST_SECRET")}},
+			}, nil, nil
+		})
+
+	mcp.AddTool(server, &mcp.Tool{Name: "crash", Description: "exits the process"},
+		func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+			os.Exit(1)
+			return nil, nil, nil
+		})
+
+	server.Run(context.Background(), &mcp.StdioTransport{})
+}
+
+func callText(t *testing.T, c *Client, name string) string {
+	t.Helper()
+
+	result, err := c.Execute(t.Context(), name, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resultText(result.(*mcp.CallToolResult))
+}
+
+func TestCommand(t *testing.T) {
+	t.Setenv("WINGMAN_TEST_SECRET", "leaked")
+
+	c, err := NewCommand(os.Args[0], nil, map[string]string{
+		"WINGMAN_TEST_MCP_SERVER": "1",
+		"GREETING":                "hello",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer c.Close()
+
+	tools, err := c.Tools(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tools) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(tools))
+	}
+
+	first := callText(t, c, "pid")
+
+	if !strings.HasSuffix(first, " hello") {
+		t.Fatalf("expected only the configured environment, got %q", first)
+	}
+
+	// concurrent calls share the running process
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Go(func() {
+			result, err := c.Execute(t.Context(), "pid", nil)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if got := resultText(result.(*mcp.CallToolResult)); got != first {
+				t.Errorf("expected %q, got %q", first, got)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	c.Execute(t.Context(), "crash", nil)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+
+	for {
+		result, err := c.Execute(ctx, "pid", nil)
+
+		if err == nil {
+			if got := resultText(result.(*mcp.CallToolResult)); got == first {
+				t.Fatal("expected a new process")
+			}
+
+			break
+		}
+
+		if ctx.Err() != nil {
+			t.Fatalf("expected the process to restart: %v", err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Execute(t.Context(), "pid", nil); err == nil {
+		t.Fatal("expected an error after close")
+	}
+}
+
+func TestCommandCloseDuringBackoff(t *testing.T) {
+	c, err := NewCommand("/nonexistent/mcp-server", nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Execute(t.Context(), "pid", nil); err == nil {
+		t.Fatal("expected the start to fail")
+	}
+
+	// the next start waits for the backoff
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := c.Execute(t.Context(), "pid", nil)
+		result <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	closed := make(chan struct{})
+
+	go func() {
+		c.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected close not to wait for the backoff")
+	}
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Fatal("expected an error after close")
+		}
+
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected the pending start to be cut short")
+	}
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Prompt renders a prompt of the server with arguments, so it can be used as
+// the messages of an agent
+func (c *Client) Prompt(ctx context.Context, name string, arguments map[string]string) ([]provider.Message, error) {
+	session, release, err := c.session(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      name,
+		Arguments: arguments,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", name, err)
+	}
+
+	var messages []provider.Message
+
+	for _, m := range result.Messages {
+		role := provider.MessageRoleUser
+
+		if m.Role == "assistant" {
+			role = provider.MessageRoleAssistant
+		}
+
+		var content provider.Content
+
+		switch v := m.Content.(type) {
+		case *mcp.TextContent:
+			content = provider.TextContent(v.Text)
+
+		case *mcp.EmbeddedResource:
+			if v.Resource == nil {
+				continue
+			}
+
+			if v.Resource.Text != "" {
+				content = provider.TextContent(v.Resource.Text)
+				break
+			}
+
+			content = contentFromPart(filePart(v.Resource.URI, v.Resource.MIMEType, v.Resource.Blob))
+
+		case *mcp.ImageContent:
+			content = contentFromPart(filePart("", v.MIMEType, v.Data))
+
+		default:
+			continue
+		}
+
+		// consecutive parts of the same role form one message
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			messages[n-1].Content = append(messages[n-1].Content, content)
+			continue
+		}
+
+		messages = append(messages, provider.Message{
+			Role:    role,
+			Content: []provider.Content{content},
+		})
+	}
+
+	return messages, nil
+}
+
+func contentFromPart(part provider.Part) provider.Content {
+	if part.File != nil {
+		return provider.FileContent(part.File)
+	}
+
+	return provider.TextContent(part.Text)
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/tool"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resourceTools returns the names of the tools listing and reading the
+// resources of the server, or "" if it has none. They are prefixed with the
+// server name, so agents using several servers get distinct tools.
+func resourceTools(session *mcp.ClientSession) (string, string) {
+	init := session.InitializeResult()
+
+	if init == nil || init.Capabilities == nil || init.Capabilities.Resources == nil {
+		return "", ""
+	}
+
+	prefix := "mcp"
+
+	if init.ServerInfo != nil {
+		if name := toolName(init.ServerInfo.Name); name != "" {
+			prefix = name
+		}
+	}
+
+	return prefix + "_list_resources", prefix + "_read_resource"
+}
+
+// hasTool reports whether the server has a tool of the given name
+func hasTool(ctx context.Context, session *mcp.ClientSession, name string) (bool, error) {
+	for t, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return false, err
+		}
+
+		if t.Name == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func resourceToolDefinitions(list, read string) []tool.Tool {
+	return []tool.Tool{
+		{
+			Name:        list,
+			Description: "List the resources (documents, files) the server provides, with their URIs. URI templates can be filled in and read as well.",
+
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			},
+		},
+		{
+			Name:        read,
+			Description: "Read the contents of a resource of the server by its URI.",
+
+			Parameters: map[string]any{
+				"type": "object",
+
+				"properties": map[string]any{
+					"uri": map[string]any{
+						"type":        "string",
+						"description": "URI of the resource",
+					},
+				},
+
+				"required": []string{"uri"},
+			},
+		},
+	}
+}
+
+func listResources(ctx context.Context, session *mcp.ClientSession) (string, error) {
+	var lines []string
+
+	for r, err := range session.Resources(ctx, nil) {
+		if err != nil {
+			return "", err
+		}
+
+		lines = append(lines, resourceLine(r.URI, r.Name, r.MIMEType, r.Description))
+	}
+
+	for t, err := range session.ResourceTemplates(ctx, nil) {
+		if err != nil {
+			// templates are optional
+			break
+		}
+
+		lines = append(lines, resourceLine(t.URITemplate, t.Name, t.MIMEType, t.Description))
+	}
+
+	if len(lines) == 0 {
+		return "The server provides no resources.", nil
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func resourceLine(uri, name, mimeType, description string) string {
+	line := uri
+
+	if name != "" {
+		line += " (" + name + ")"
+	}
+
+	if mimeType != "" {
+		line += " [" + mimeType + "]"
+	}
+
+	if description != "" {
+		line += ": " + description
+	}
+
+	return line
+}
+
+func readResource(ctx context.Context, session *mcp.ClientSession, parameters map[string]any) (*mcp.ReadResourceResult, error) {
+	uri, _ := parameters["uri"].(string)
+
+	if uri == "" {
+		return nil, errors.New("missing uri")
+	}
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", uri, err)
+	}
+
+	return result, nil
+}
+
+// toolName reduces a server name to the characters valid in tool names
+func toolName(name string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+
+		case r == '-', r == ' ', r == '.', r == '/':
+			b.WriteRune('_')
+		}
+	}
+
+	return strings.Trim(b.String(), "_")
+}
@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func docsServer(t *testing.T) *Client {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "Docs Server", Version: "1.0.0"}, nil)
+
+	server.AddResource(&mcp.Resource{URI: "docs://guide", Name: "guide", MIMEType: "text/markdown"},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "# Guide"}},
+			}, nil
+		})
+
+	server.AddPrompt(&mcp.Prompt{Name: "review", Arguments: []*mcp.PromptArgument{{Name: "language"}}},
+		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{
+				Messages: []*mcp.PromptMessage{
+					{Role: "user", Content: &mcp.TextContent{Text: "Review the " + req.Params.Arguments["language"] + " code."}},
+					{Role: "assistant", Content: &mcp.TextContent{Text: "Paste it."}},
+				},
+			}, nil
+		})
+
+	return connectServer(t, server)
+}
+
+func connectServer(t *testing.T, server *mcp.Server) *Client {
+	t.Helper()
+
+	httpServer := httptest.NewServer(mcp.NewStreamableHTTPHandler(
+		func(r *http.Request) *mcp.Server { return server },
+		&mcp.StreamableHTTPOptions{Stateless: true},
+	))
+
+	t.Cleanup(httpServer.Close)
+
+	c, err := New(httpServer.URL, nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestResourceTools(t *testing.T) {
+	c := docsServer(t)
+
+	tools, err := c.Tools(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tools) != 2 || tools[0].Name != "docs_server_list_resources" || tools[1].Name != "docs_server_read_resource" {
+		t.Fatalf("tools = %+v", tools)
+	}
+
+	list, err := c.Execute(t.Context(), "docs_server_list_resources", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text := c.Result("", list).Parts[0].Text; !strings.HasPrefix(text, "docs://guide (guide) [text/markdown]") {
+		t.Fatalf("list = %q", text)
+	}
+
+	read, err := c.Execute(t.Context(), "docs_server_read_resource", map[string]any{"uri": "docs://guide"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text := c.Result("", read).Parts[0].Text; text != "# Guide" {
+		t.Fatalf("read = %q", text)
+	}
+}
+
+func TestResourceToolShadowedByTool(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "Docs Server", Version: "1.0.0"}, nil)
+
+	server.AddResource(&mcp.Resource{URI: "docs://guide", Name: "guide"},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "# Guide"}},
+			}, nil
+		})
+
+	server.AddTool(&mcp.Tool{Name: "docs_server_list_resources", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "from the server"}},
+			}, nil
+		})
+
+	c := connectServer(t, server)
+
+	tools, err := c.Tools(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tools) != 2 || tools[0].Name != "docs_server_list_resources" || tools[1].Name != "docs_server_read_resource" {
+		t.Fatalf("tools = %+v", tools)
+	}
+
+	list, err := c.Execute(t.Context(), "docs_server_list_resources", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text := c.Result("", list).Parts[0].Text; text != "from the server" {
+		t.Fatalf("list = %q", text)
+	}
+
+	read, err := c.Execute(t.Context(), "docs_server_read_resource", map[string]any{"uri": "docs://guide"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text := c.Result("", read).Parts[0].Text; text != "# Guide" {
+		t.Fatalf("read = %q", text)
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	c := docsServer(t)
+
+	messages, err := c.Prompt(t.Context(), "review", map[string]string{"language": "Go"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(messages) != 2 || messages[0].Role != provider.MessageRoleUser || messages[0].Content[0].Text != "Review the Go code." || messages[1].Role != provider.MessageRoleAssistant {
+		t.Fatalf("messages = %+v", messages)
+	}
+}
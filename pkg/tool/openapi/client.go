@@ -0,0 +1,412 @@
+// Package openapi turns the operations of an OpenAPI 3 document into tools
+// that call the described service over HTTP.
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+const defaultMaxResponseSize = 32 * 1024
+
+var (
+	_ tool.Provider = (*Client)(nil)
+	_ tool.Resulter = (*Client)(nil)
+)
+
+type Client struct {
+	spec string
+
+	client *http.Client
+
+	server  string
+	headers map[string]string
+
+	exchanger auth.TokenExchanger
+
+	// operations allowlists operations by operationId or tool name
+	operations []string
+
+	maxResponseSize int
+
+	mu     sync.Mutex
+	loaded []operation
+	base   string
+}
+
+// New creates a provider for the OpenAPI document at spec, an http(s) URL or
+// a file path. The document is loaded on first use.
+func New(spec string, options ...Option) (*Client, error) {
+	if spec == "" {
+		return nil, errors.New("openapi: missing spec")
+	}
+
+	c := &Client{
+		spec: spec,
+
+		client: http.DefaultClient,
+
+		maxResponseSize: defaultMaxResponseSize,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c, nil
+}
+
+func (c *Client) Tools(ctx context.Context) ([]tool.Tool, error) {
+	operations, _, err := c.load(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var result []tool.Tool
+
+	for _, o := range operations {
+		result = append(result, o.tool)
+	}
+
+	return result, nil
+}
+
+func (c *Client) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	operations, base, err := c.load(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(operations, func(o operation) bool { return o.tool.Name == name })
+
+	if i < 0 {
+		return nil, tool.ErrInvalidTool
+	}
+
+	req, err := c.request(ctx, base, operations[i], parameters)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	text, err := readTruncated(resp.Body, c.maxResponseSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("%s %s: %s: %s", req.Method, operations[i].path, resp.Status, text)
+	}
+
+	return text, nil
+}
+
+// Result passes the response body to the model as text
+func (c *Client) Result(name string, value any) provider.ToolResult {
+	text, _ := value.(string)
+
+	if text == "" {
+		text = "(no content)"
+	}
+
+	return provider.ToolResult{Parts: []provider.Part{{Text: text}}}
+}
+
+// load fetches and parses the document once. A failed load is retried on
+// the next call.
+func (c *Client) load(ctx context.Context) ([]operation, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded != nil {
+		return c.loaded, c.base, nil
+	}
+
+	data, err := c.fetch(ctx)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	operations, server, err := parseSpec(data, c.operations)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(operations) == 0 {
+		return nil, "", errors.New("openapi: no operations in " + c.spec)
+	}
+
+	base := c.server
+
+	if base == "" {
+		base = server
+	}
+
+	// Relative server URLs are relative to the document
+	if u, err := neturl.Parse(c.spec); err == nil && u.IsAbs() {
+		if ref, err := neturl.Parse(base); err == nil {
+			base = u.ResolveReference(ref).String()
+		}
+	}
+
+	if u, err := neturl.Parse(base); err != nil || !u.IsAbs() {
+		return nil, "", errors.New("openapi: missing absolute server url for " + c.spec)
+	}
+
+	c.loaded = operations
+	c.base = strings.TrimRight(base, "/")
+
+	return c.loaded, c.base, nil
+}
+
+func (c *Client) fetch(ctx context.Context) ([]byte, error) {
+	if !isRemote(c.spec) {
+		return os.ReadFile(c.spec)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.spec, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("openapi: failed to load " + c.spec + ": " + resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Client) request(ctx context.Context, base string, o operation, parameters map[string]any) (*http.Request, error) {
+	path := o.path
+	query := neturl.Values{}
+
+	header := http.Header{}
+
+	var cookies []string
+
+	for arg, value := range parameters {
+		p, ok := o.parameters[arg]
+
+		if !ok || value == nil {
+			continue
+		}
+
+		switch p.in {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.name+"}", neturl.PathEscape(formatParameter(value)))
+
+		case "query":
+			if values, ok := value.([]any); ok {
+				for _, v := range values {
+					query.Add(p.name, formatParameter(v))
+				}
+
+				continue
+			}
+
+			query.Set(p.name, formatParameter(value))
+
+		case "header":
+			header.Set(p.name, formatParameter(value))
+
+		case "cookie":
+			cookies = append(cookies, p.name+"="+neturl.QueryEscape(formatParameter(value)))
+		}
+	}
+
+	if strings.Contains(path, "{") {
+		return nil, errors.New("openapi: missing path parameter for " + o.path)
+	}
+
+	url := base + path
+
+	if len(query) > 0 {
+		url += "?" + query.Encode()
+	}
+
+	var body io.Reader
+
+	if value, ok := parameters[bodyParameter]; ok && o.body != "" {
+		if text, ok := value.(string); ok && o.body == "text/plain" {
+			body = strings.NewReader(text)
+		} else {
+			data, err := json.Marshal(value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			body = bytes.NewReader(data)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, o.method, url, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if len(cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", o.body)
+	}
+
+	req.Header.Set("Accept", "application/json, text/plain;q=0.9, */*;q=0.8")
+
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// authorize sets the configured headers and the credential of the token
+// exchanger, which take precedence over parameters of the same name. They
+// are only sent to trusted origins.
+func (c *Client) authorize(req *http.Request) error {
+	if !c.trusts(req.URL) {
+		return nil
+	}
+
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	if c.exchanger == nil {
+		return nil
+	}
+
+	caller, _ := req.Context().Value(auth.TokenContextKey).(string)
+
+	downstream, err := c.exchanger.Token(req.Context(), caller)
+
+	if err != nil {
+		return err
+	}
+
+	if downstream == "" {
+		req.Header.Del("Authorization")
+	} else {
+		req.Header.Set("Authorization", "Bearer "+downstream)
+	}
+
+	return nil
+}
+
+// trusts reports whether credentials may be sent to u: to the origin of a
+// remote document or of the configured server. A remote document is
+// untrusted input, so the servers it names only get them on these origins;
+// a local document is part of the configuration and trusted as a whole.
+func (c *Client) trusts(u *neturl.URL) bool {
+	if !isRemote(c.spec) {
+		return true
+	}
+
+	for _, s := range []string{c.spec, c.server} {
+		if t, err := neturl.Parse(s); err == nil && t.IsAbs() && sameOrigin(t, u) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isRemote(spec string) bool {
+	return strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://")
+}
+
+func sameOrigin(a, b *neturl.URL) bool {
+	port := func(u *neturl.URL) string {
+		if p := u.Port(); p != "" {
+			return p
+		}
+
+		if strings.EqualFold(u.Scheme, "https") {
+			return "443"
+		}
+
+		return "80"
+	}
+
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Hostname(), b.Hostname()) && port(a) == port(b)
+}
+
+func formatParameter(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+
+	case float64, bool, int:
+		return fmt.Sprint(v)
+
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// readTruncated reads up to limit bytes of r and notes how much was cut
+func readTruncated(r io.Reader, limit int) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) <= limit {
+		return string(data), nil
+	}
+
+	rest, _ := io.Copy(io.Discard, r)
+
+	text := strings.ToValidUTF8(string(data[:limit]), "")
+
+	return fmt.Sprintf("%s\n\n[response truncated: showing %d of %d bytes]", text, limit, int64(len(data))+rest), nil
+}
@@ -0,0 +1,325 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+)
+
+const petstore = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: "1"
+servers:
+  - url: /api
+paths:
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      operationId: getPet
+      summary: Get a pet
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+        - name: X-Tenant
+          in: header
+          required: true
+          schema:
+            type: string
+      responses:
+        200:
+          description: ok
+    put:
+      operationId: updatePet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        200:
+          description: ok
+  /pets:
+    get:
+      summary: List pets
+      responses:
+        200:
+          description: ok
+components:
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      description: The pet id
+      schema:
+        type: integer
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          nullable: true
+          example: Rex
+        example:
+          type: string
+        parent:
+          $ref: '#/components/schemas/Pet'
+`
+
+type recorded struct {
+	method string
+	path   string
+	query  string
+
+	tenant string
+	token  string
+
+	body string
+}
+
+func newServer(t *testing.T) (*httptest.Server, *[]recorded) {
+	t.Helper()
+
+	var requests []recorded
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, petstore)
+	})
+
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		requests = append(requests, recorded{
+			method: r.Method,
+			path:   r.URL.Path,
+			query:  r.URL.RawQuery,
+
+			tenant: r.Header.Get("X-Tenant"),
+			token:  r.Header.Get("Authorization"),
+
+			body: string(body),
+		})
+
+		switch r.URL.Path {
+		case "/api/pets":
+			io.WriteString(w, strings.Repeat("x", 100))
+		case "/api/pets/404":
+			http.Error(w, "no such pet", http.StatusNotFound)
+		default:
+			io.WriteString(w, `{"name":"Rex"}`)
+		}
+	})
+
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s, &requests
+}
+
+func TestTools(t *testing.T) {
+	s, _ := newServer(t)
+
+	c, err := New(s.URL + "/openapi.yaml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tools, err := c.Tools(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+
+	if strings.Join(names, ",") != "get_pets,getPet,updatePet" {
+		t.Fatalf("unexpected tools: %v", names)
+	}
+
+	data, _ := json.Marshal(tools[1].Parameters)
+
+	for _, s := range []string{
+		`"petId":{"description":"The pet id","type":"integer"}`,
+		`"X-Tenant":{"type":"string"}`,
+		`"required":["petId","X-Tenant"]`,
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Fatalf("getPet parameters miss %s: %s", s, data)
+		}
+	}
+
+	data, _ = json.Marshal(tools[2].Parameters)
+
+	for _, s := range []string{
+		`"example":{"type":"string"}`,
+		`"name":{"type":"string"}`,
+		`"required":["petId","body"]`,
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Fatalf("updatePet parameters miss %s: %s", s, data)
+		}
+	}
+
+	if strings.Contains(string(data), "$ref") || strings.Contains(string(data), "nullable") {
+		t.Fatalf("updatePet parameters are not plain JSON schema: %s", data)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	s, requests := newServer(t)
+
+	c, err := New(s.URL+"/openapi.yaml",
+		WithOperations("getPet", "updatePet"),
+		WithTokenExchanger(auth.NewStaticExchanger("secret")),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	result, err := c.Execute(ctx, "getPet", map[string]any{"petId": 7.0, "fields": []any{"name", "age"}, "X-Tenant": "acme"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != `{"name":"Rex"}` {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	if _, err := c.Execute(ctx, "updatePet", map[string]any{"petId": 7.0, "body": map[string]any{"name": "Max"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Execute(ctx, "get_pets", nil); err == nil {
+		t.Fatal("expected operations outside the allowlist to be rejected")
+	}
+
+	if _, err := c.Execute(ctx, "getPet", map[string]any{"petId": 404.0}); err == nil || !strings.Contains(err.Error(), "404 Not Found: no such pet") {
+		t.Fatalf("expected the error response, got %v", err)
+	}
+
+	get, put := (*requests)[0], (*requests)[1]
+
+	if get.method != "GET" || get.path != "/api/pets/7" || get.query != "fields=name&fields=age" || get.tenant != "acme" || get.token != "Bearer secret" {
+		t.Fatalf("unexpected request: %+v", get)
+	}
+
+	if put.method != "PUT" || put.path != "/api/pets/7" || put.body != `{"name":"Max"}` {
+		t.Fatalf("unexpected request: %+v", put)
+	}
+}
+
+func TestExecuteTruncates(t *testing.T) {
+	s, _ := newServer(t)
+
+	c, err := New(s.URL+"/openapi.yaml", WithMaxResponseSize(10))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := c.Execute(context.Background(), "get_pets", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "xxxxxxxxxx\n\n[response truncated: showing 10 of 100 bytes]" {
+		t.Fatalf("unexpected result: %q", result)
+	}
+}
+
+func TestExecuteWithholdsCredentialsFromForeignServers(t *testing.T) {
+	var token, key string
+
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, key = r.Header.Get("Authorization"), r.Header.Get("X-Api-Key")
+		io.WriteString(w, "ok")
+	}))
+
+	t.Cleanup(foreign.Close)
+
+	spec := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"openapi": "3.0.3", "servers": [{"url": "`+foreign.URL+`"}], "paths": {"/pets": {"get": {"operationId": "listPets"}}}}`)
+	}))
+
+	t.Cleanup(spec.Close)
+
+	c, err := New(spec.URL+"/openapi.json",
+		WithHeaders(map[string]string{"X-Api-Key": "key"}),
+		WithTokenExchanger(auth.NewStaticExchanger("secret")),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Execute(context.Background(), "listPets", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "" || key != "" {
+		t.Fatalf("expected no credentials for the foreign server, got %q and %q", token, key)
+	}
+
+	// A configured server is trusted
+	c, _ = New(spec.URL+"/openapi.json",
+		WithServer(foreign.URL),
+		WithTokenExchanger(auth.NewStaticExchanger("secret")),
+	)
+
+	if _, err := c.Execute(context.Background(), "listPets", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "Bearer secret" {
+		t.Fatalf("expected the credential for the configured server, got %q", token)
+	}
+}
+
+func TestToolsIgnoresDuplicatesOutsideAllowlist(t *testing.T) {
+	spec := `{"openapi": "3.0.3", "servers": [{"url": "https://example.com"}], "paths": {
+		"/a": {"get": {"operationId": "same"}},
+		"/b": {"get": {"operationId": "same"}},
+		"/c": {"get": {"operationId": "listPets"}}
+	}}`
+
+	if _, _, err := parseSpec([]byte(spec), nil); err == nil {
+		t.Fatal("expected duplicate names to be rejected")
+	}
+
+	operations, _, err := parseSpec([]byte(spec), []string{"listPets"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(operations) != 1 || operations[0].id != "listPets" {
+		t.Fatalf("unexpected operations: %+v", operations)
+	}
+}
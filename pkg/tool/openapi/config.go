@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+)
+
+type Option func(*Client)
+
+func WithClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithServer overrides the server URL of the document
+func WithServer(url string) Option {
+	return func(c *Client) {
+		c.server = url
+	}
+}
+
+// WithHeaders sets headers on every request, including the one loading the
+// document
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		c.headers = headers
+	}
+}
+
+// WithTokenExchanger determines the bearer credential of the requests
+func WithTokenExchanger(exchanger auth.TokenExchanger) Option {
+	return func(c *Client) {
+		c.exchanger = exchanger
+	}
+}
+
+// WithOperations limits the tools to the operations with these
+// operationIds or tool names
+func WithOperations(operations ...string) Option {
+	return func(c *Client) {
+		c.operations = operations
+	}
+}
+
+// WithMaxResponseSize truncates response bodies beyond size bytes
+// (default 32 KiB)
+func WithMaxResponseSize(size int) Option {
+	return func(c *Client) {
+		if size > 0 {
+			c.maxResponseSize = size
+		}
+	}
+}
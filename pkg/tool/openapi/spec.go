@@ -0,0 +1,437 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/tool"
+
+	"go.yaml.in/yaml/v4"
+)
+
+var invalidName = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+var methods = []string{"get", "put", "post", "delete", "patch", "head", "options"}
+
+// maxRefDepth cuts recursive schemas; deeper levels become plain objects
+const maxRefDepth = 8
+
+const bodyParameter = "body"
+
+// operation is an OpenAPI operation turned into a tool
+type operation struct {
+	// id is the operationId, if any
+	id string
+
+	tool tool.Tool
+
+	method string
+	path   string
+
+	// parameters maps tool argument names to request parameters
+	parameters map[string]parameter
+
+	// body is the content type of the request body, if any
+	body string
+}
+
+type parameter struct {
+	name string
+
+	// in is path, query, header or cookie
+	in string
+}
+
+type document struct {
+	root map[string]any
+}
+
+// parseSpec reads an OpenAPI 3 document in JSON or YAML and returns its
+// operations and the URL of its first server. A non-empty allowlist selects
+// operations by operationId or tool name; names only need to be unique among
+// the selected operations.
+func parseSpec(data []byte, allowlist []string) ([]operation, string, error) {
+	var value any
+
+	// YAML is a superset of JSON
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, "", fmt.Errorf("openapi: invalid document: %w", err)
+	}
+
+	root, _ := stringKeys(value).(map[string]any)
+
+	if version, _ := root["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, "", errors.New("openapi: unsupported document, expected OpenAPI 3")
+	}
+
+	d := &document{root: root}
+
+	var server string
+
+	if servers, _ := root["servers"].([]any); len(servers) > 0 {
+		if s, ok := d.resolve(servers[0]).(map[string]any); ok {
+			server = serverURL(s)
+		}
+	}
+
+	paths, _ := root["paths"].(map[string]any)
+
+	var result []operation
+
+	names := map[string]bool{}
+
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		item, _ := d.resolve(paths[path]).(map[string]any)
+
+		if item == nil {
+			continue
+		}
+
+		for _, method := range methods {
+			op, _ := item[method].(map[string]any)
+
+			if op == nil {
+				continue
+			}
+
+			o := d.operation(path, method, item, op)
+
+			if len(allowlist) > 0 && !slices.Contains(allowlist, o.tool.Name) && !slices.Contains(allowlist, o.id) {
+				continue
+			}
+
+			if names[o.tool.Name] {
+				return nil, "", errors.New("openapi: duplicate operation: " + o.tool.Name)
+			}
+
+			names[o.tool.Name] = true
+
+			result = append(result, o)
+		}
+	}
+
+	return result, server, nil
+}
+
+func (d *document) operation(path, method string, item, op map[string]any) operation {
+	o := operation{
+		method: strings.ToUpper(method),
+		path:   path,
+
+		parameters: map[string]parameter{},
+	}
+
+	o.id, _ = op["operationId"].(string)
+
+	name := o.id
+
+	if name == "" {
+		name = method + "_" + strings.Trim(path, "/")
+	}
+
+	name = strings.Trim(invalidName.ReplaceAllString(name, "_"), "_")
+
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	var description []string
+
+	for _, key := range []string{"summary", "description"} {
+		if s, _ := op[key].(string); s != "" {
+			description = append(description, strings.TrimSpace(s))
+		}
+	}
+
+	if len(description) == 0 {
+		description = append(description, o.method+" "+path)
+	}
+
+	properties := map[string]any{}
+
+	var required []string
+
+	// Operation parameters override those of the path item
+	params := map[string]map[string]any{}
+	var order []string
+
+	for _, list := range []any{item["parameters"], op["parameters"]} {
+		values, _ := list.([]any)
+
+		for _, v := range values {
+			p, _ := d.resolve(v).(map[string]any)
+
+			if p == nil {
+				continue
+			}
+
+			name, _ := p["name"].(string)
+			in, _ := p["in"].(string)
+
+			key := in + ":" + name
+
+			if _, ok := params[key]; !ok {
+				order = append(order, key)
+			}
+
+			params[key] = p
+		}
+	}
+
+	for _, key := range order {
+		p := params[key]
+
+		name, _ := p["name"].(string)
+		in, _ := p["in"].(string)
+
+		if name == "" || (in != "path" && in != "query" && in != "header" && in != "cookie") {
+			continue
+		}
+
+		arg := name
+
+		if _, taken := o.parameters[arg]; taken || arg == bodyParameter {
+			arg = in + "_" + name
+		}
+
+		o.parameters[arg] = parameter{name: name, in: in}
+
+		schema, _ := d.schema(p["schema"], 0).(map[string]any)
+
+		if schema == nil {
+			schema = map[string]any{"type": "string"}
+		}
+
+		if s, _ := p["description"].(string); s != "" {
+			schema = maps.Clone(schema)
+			schema["description"] = s
+		}
+
+		properties[arg] = schema
+
+		if r, _ := p["required"].(bool); r || in == "path" {
+			required = append(required, arg)
+		}
+	}
+
+	if body, _ := d.resolve(op["requestBody"]).(map[string]any); body != nil {
+		content, _ := body["content"].(map[string]any)
+
+		contentType := ""
+
+		for _, t := range slices.Sorted(maps.Keys(content)) {
+			if isJSON(t) {
+				contentType = t
+				break
+			}
+		}
+
+		if contentType == "" && content["text/plain"] != nil {
+			contentType = "text/plain"
+		}
+
+		if contentType != "" {
+			media, _ := content[contentType].(map[string]any)
+
+			schema, _ := d.schema(media["schema"], 0).(map[string]any)
+
+			if schema == nil {
+				schema = map[string]any{}
+			}
+
+			if contentType == "text/plain" {
+				schema = map[string]any{"type": "string"}
+			}
+
+			if s, _ := body["description"].(string); s != "" {
+				schema = maps.Clone(schema)
+				schema["description"] = s
+			}
+
+			o.body = contentType
+			properties[bodyParameter] = schema
+
+			if r, _ := body["required"].(bool); r {
+				required = append(required, bodyParameter)
+			}
+		}
+	}
+
+	parameters := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if len(required) > 0 {
+		parameters["required"] = required
+	}
+
+	o.tool = tool.Tool{
+		Name:        name,
+		Description: strings.Join(description, "\n\n"),
+
+		Parameters: tool.NormalizeSchema(parameters),
+	}
+
+	return o
+}
+
+// resolve follows a local $ref to the referenced value
+func (d *document) resolve(value any) any {
+	for range maxRefDepth {
+		m, ok := value.(map[string]any)
+
+		if !ok {
+			return value
+		}
+
+		ref, ok := m["$ref"].(string)
+
+		if !ok {
+			return value
+		}
+
+		value = d.pointer(ref)
+	}
+
+	return nil
+}
+
+// schema returns the schema with all local references inlined. Recursion
+// deeper than maxRefDepth references ends in an unconstrained object.
+func (d *document) schema(value any, depth int) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			if depth >= maxRefDepth {
+				return map[string]any{"type": "object"}
+			}
+
+			return d.schema(d.pointer(ref), depth+1)
+		}
+
+		result := make(map[string]any, len(v))
+
+		for k, item := range v {
+			switch {
+			// OpenAPI additions to JSON schema that models do not need
+			case k == "nullable" || k == "discriminator" || k == "xml" || k == "externalDocs" || k == "example" || strings.HasPrefix(k, "x-"):
+
+			// Keywords that hold no schemas are copied as is
+			case k == "examples" || k == "default" || k == "enum" || k == "const" || k == "required":
+				result[k] = item
+
+			// Keywords that map names to schemas
+			case k == "properties" || k == "patternProperties":
+				named, _ := item.(map[string]any)
+				schemas := make(map[string]any, len(named))
+
+				for name, schema := range named {
+					schemas[name] = d.schema(schema, depth)
+				}
+
+				result[k] = schemas
+
+			default:
+				result[k] = d.schema(item, depth)
+			}
+		}
+
+		return result
+
+	case []any:
+		result := make([]any, len(v))
+
+		for i, item := range v {
+			result[i] = d.schema(item, depth)
+		}
+
+		return result
+
+	default:
+		return value
+	}
+}
+
+// pointer returns the value at a local JSON pointer like
+// #/components/schemas/Pet
+func (d *document) pointer(ref string) any {
+	p, ok := strings.CutPrefix(ref, "#/")
+
+	if !ok {
+		return nil
+	}
+
+	var value any = d.root
+
+	for _, token := range strings.Split(p, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		m, ok := value.(map[string]any)
+
+		if !ok {
+			return nil
+		}
+
+		value = m[token]
+	}
+
+	return value
+}
+
+// serverURL expands the variables of a server object with their defaults
+func serverURL(server map[string]any) string {
+	url, _ := server["url"].(string)
+
+	variables, _ := server["variables"].(map[string]any)
+
+	for name, v := range variables {
+		variable, _ := v.(map[string]any)
+
+		if value, ok := variable["default"].(string); ok {
+			url = strings.ReplaceAll(url, "{"+name+"}", value)
+		}
+	}
+
+	return url
+}
+
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+// stringKeys converts YAML maps with non-string keys, like status codes, so
+// the document encodes as JSON
+func stringKeys(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = stringKeys(item)
+		}
+
+		return v
+
+	case map[any]any:
+		result := make(map[string]any, len(v))
+
+		for k, item := range v {
+			result[fmt.Sprint(k)] = stringKeys(item)
+		}
+
+		return result
+
+	case []any:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+
+		return v
+
+	default:
+		return value
+	}
+}
@@ -0,0 +1,77 @@
+package postprocess
+
+import (
+	"sync"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// maxEntries bounds the cache so a chatty agent can not grow it unbounded
+const maxEntries = 1024
+
+type cache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	result  provider.ToolResult
+	expires time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl: ttl,
+
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *cache) Get(key string) (provider.ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+
+	if !ok {
+		return provider.ToolResult{}, false
+	}
+
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return provider.ToolResult{}, false
+	}
+
+	return entry.result, true
+}
+
+func (c *cache) Set(key string, result provider.ToolResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if len(c.entries) >= maxEntries {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+
+	// Still full of live entries: evict an arbitrary one
+	if len(c.entries) >= maxEntries {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+
+	c.entries[key] = cacheEntry{
+		result:  result,
+		expires: now.Add(c.ttl),
+	}
+}
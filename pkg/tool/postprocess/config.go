@@ -0,0 +1,46 @@
+package postprocess
+
+import (
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/summarizer"
+)
+
+type Option func(*Tool)
+
+// WithMaxCharacters limits the text of a result to at most n characters
+// (runes)
+func WithMaxCharacters(n int) Option {
+	return func(t *Tool) {
+		if n > 0 {
+			t.maxCharacters = n
+		}
+	}
+}
+
+// WithMaxTokens limits the text of a result to an estimated n tokens
+func WithMaxTokens(n int) Option {
+	return func(t *Tool) {
+		if n > 0 {
+			t.maxTokens = n
+		}
+	}
+}
+
+// WithSummarizer condenses oversized results with a summary focused on the
+// tool call instead of cutting them off
+func WithSummarizer(s summarizer.Provider) Option {
+	return func(t *Tool) {
+		t.summarizer = s
+	}
+}
+
+// WithCache reuses the result of a call with the same tool name and
+// arguments for the given duration. Only enable it for deterministic tools.
+func WithCache(ttl time.Duration) Option {
+	return func(t *Tool) {
+		if ttl > 0 {
+			t.cache = newCache(ttl)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package postprocess
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/summarizer"
+	"github.com/adrianliechti/wingman/pkg/tokens"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+var (
+	_ tool.Provider = (*Tool)(nil)
+	_ tool.Resulter = (*Tool)(nil)
+)
+
+// Tool post-processes the results of the tools of a provider: it enforces
+// character and token limits, condenses oversized results with a summarizer
+// and caches results by tool name and arguments.
+type Tool struct {
+	tool tool.Provider
+
+	maxTokens     int
+	maxCharacters int
+
+	summarizer summarizer.Provider
+
+	cache *cache
+
+	// limits holds the MaxCharacters the tools declare themselves
+	limits sync.Map
+}
+
+func New(t tool.Provider, options ...Option) *Tool {
+	p := &Tool{
+		tool: t,
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+func (t *Tool) Tools(ctx context.Context) ([]tool.Tool, error) {
+	tools, err := t.tool.Tools(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range tools {
+		if d.MaxCharacters > 0 {
+			t.limits.Store(d.Name, d.MaxCharacters)
+		}
+	}
+
+	return tools, nil
+}
+
+// Execute returns the processed provider.ToolResult of the call
+func (t *Tool) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	key, cacheable := t.cacheKey(ctx, name, parameters)
+
+	if cacheable {
+		if result, ok := t.cache.Get(key); ok {
+			// Callers may append to the parts of a result
+			result.Parts = slices.Clone(result.Parts)
+			return result, nil
+		}
+	}
+
+	value, err := t.tool.Execute(ctx, name, parameters)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := t.process(ctx, name, parameters, t.render(name, value))
+
+	if cacheable && !result.IsError {
+		t.cache.Set(key, result)
+		result.Parts = slices.Clone(result.Parts)
+	}
+
+	return result, nil
+}
+
+// Result implements tool.Resulter for the results returned by Execute
+func (t *Tool) Result(name string, value any) provider.ToolResult {
+	if result, ok := value.(provider.ToolResult); ok {
+		return result
+	}
+
+	return t.render(name, value)
+}
+
+func (t *Tool) render(name string, value any) provider.ToolResult {
+	if r, ok := t.tool.(tool.Resulter); ok {
+		return r.Result(name, value)
+	}
+
+	if text, ok := value.(string); ok {
+		return provider.ToolResult{
+			Parts: []provider.Part{{Text: text}},
+		}
+	}
+
+	data, _ := json.Marshal(value)
+
+	return provider.ToolResult{
+		Parts: []provider.Part{{Text: string(data)}},
+	}
+}
+
+func (t *Tool) process(ctx context.Context, name string, parameters map[string]any, result provider.ToolResult) provider.ToolResult {
+	var texts []string
+	var files []provider.Part
+
+	for _, p := range result.Parts {
+		if p.File != nil {
+			files = append(files, p)
+			continue
+		}
+
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+
+	text := strings.Join(texts, "\n\n")
+
+	limit := t.characterLimit(name, text)
+	total := utf8.RuneCountInString(text)
+
+	if limit <= 0 || total <= limit {
+		return result
+	}
+
+	text = truncate(text, limit) + fmt.Sprintf("\n\n[Truncated: showing %d of %d characters.]", limit, total)
+
+	if t.summarizer != nil {
+		summary, err := t.summarize(ctx, name, parameters, strings.Join(texts, "\n\n"))
+
+		if err == nil {
+			text = truncate(summary, limit) + fmt.Sprintf("\n\n[Summarized: the full result had %d characters.]", total)
+		} else {
+			// A cut off result beats none
+			slog.Warn("tool result summarization failed", "tool", name, "error", err)
+		}
+	}
+
+	result.Parts = append([]provider.Part{{Text: text}}, files...)
+
+	return result
+}
+
+// characterLimit combines the configured limits and the limit the tool
+// declares into a single limit in characters
+func (t *Tool) characterLimit(name, text string) int {
+	limit := t.maxCharacters
+
+	if v, ok := t.limits.Load(name); ok {
+		if n := v.(int); limit <= 0 || n < limit {
+			limit = n
+		}
+	}
+
+	if t.maxTokens > 0 {
+		if count := tokens.Text("", text); count > t.maxTokens {
+			n := utf8.RuneCountInString(text) * t.maxTokens / count
+
+			if limit <= 0 || n < limit {
+				limit = n
+			}
+		}
+	}
+
+	return limit
+}
+
+func (t *Tool) summarize(ctx context.Context, name string, parameters map[string]any, text string) (string, error) {
+	args, _ := json.Marshal(parameters)
+
+	summary, err := t.summarizer.Summarize(ctx, text, &summarizer.SummarizeOptions{
+		Instructions: fmt.Sprintf("The text is the result of a call of the tool %q with the arguments %s. Keep everything the call was looking for.", name, args),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return summary.Text, nil
+}
+
+func (t *Tool) cacheKey(ctx context.Context, name string, parameters map[string]any) (string, bool) {
+	if t.cache == nil {
+		return "", false
+	}
+
+	args, err := json.Marshal(parameters)
+
+	if err != nil {
+		return "", false
+	}
+
+	// Results may depend on who calls, never share them across users
+	user, _ := ctx.Value(auth.UserContextKey).(string)
+
+	return user + "\x00" + name + "\x00" + string(args), true
+}
+
+// truncate cuts text to at most limit characters (runes)
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	return string([]rune(text)[:limit])
+}
@@ -0,0 +1,204 @@
+package postprocess
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/summarizer"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+type stubTool struct {
+	text  string
+	limit int
+
+	calls int
+}
+
+func (t *stubTool) Tools(ctx context.Context) ([]tool.Tool, error) {
+	return []tool.Tool{{Name: "fetch", MaxCharacters: t.limit}}, nil
+}
+
+func (t *stubTool) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	t.calls++
+	return t.text, nil
+}
+
+type stubResulterTool struct {
+	stubTool
+}
+
+func (t *stubResulterTool) Result(name string, value any) provider.ToolResult {
+	return provider.ToolResult{
+		Parts: []provider.Part{
+			{Text: "rendered: " + value.(string)},
+			{File: &provider.File{Name: "chart.png", ContentType: "image/png"}},
+		},
+	}
+}
+
+type stubSummarizer struct {
+	err error
+
+	text    string
+	options *summarizer.SummarizeOptions
+}
+
+func (s *stubSummarizer) Summarize(ctx context.Context, text string, options *summarizer.SummarizeOptions) (*summarizer.Summary, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	s.text = text
+	s.options = options
+
+	return &summarizer.Summary{Text: "the gist"}, nil
+}
+
+func execute(t *testing.T, p tool.Provider, ctx context.Context, parameters map[string]any) provider.ToolResult {
+	t.Helper()
+
+	value, err := p.Execute(ctx, "fetch", parameters)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p.(tool.Resulter).Result("fetch", value)
+}
+
+func TestToolTruncatesResults(t *testing.T) {
+	p := New(&stubTool{text: strings.Repeat("ä", 100)}, WithMaxCharacters(10))
+
+	result := execute(t, p, context.Background(), nil)
+
+	if len(result.Parts) != 1 {
+		t.Fatalf("expected 1 part, got %d", len(result.Parts))
+	}
+
+	text := result.Parts[0].Text
+
+	if !strings.HasPrefix(text, strings.Repeat("ä", 10)+"\n\n") || strings.HasPrefix(text, strings.Repeat("ä", 11)) {
+		t.Fatalf("expected 10 characters, got %q", text)
+	}
+
+	if !strings.Contains(text, "showing 10 of 100 characters") {
+		t.Fatalf("expected a truncation notice, got %q", text)
+	}
+}
+
+func TestToolKeepsSmallResults(t *testing.T) {
+	p := New(&stubTool{text: "short"}, WithMaxCharacters(10), WithMaxTokens(10))
+
+	result := execute(t, p, context.Background(), nil)
+
+	if result.Parts[0].Text != "short" {
+		t.Fatalf("expected the result unchanged, got %q", result.Parts[0].Text)
+	}
+}
+
+func TestToolLimitsTokens(t *testing.T) {
+	p := New(&stubTool{text: strings.Repeat("lorem ipsum dolor sit amet ", 1000)}, WithMaxTokens(100))
+
+	result := execute(t, p, context.Background(), nil)
+
+	text, _, _ := strings.Cut(result.Parts[0].Text, "\n\n[Truncated")
+
+	if len(text) == 0 || len(text) > 1000 {
+		t.Fatalf("expected about 100 tokens, got %d characters", len(text))
+	}
+}
+
+func TestToolAppliesDeclaredLimit(t *testing.T) {
+	p := New(&stubTool{text: strings.Repeat("x", 100), limit: 20}, WithMaxCharacters(50))
+
+	if _, err := p.Tools(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	result := execute(t, p, context.Background(), nil)
+
+	if !strings.Contains(result.Parts[0].Text, "showing 20 of 100 characters") {
+		t.Fatalf("expected the declared limit, got %q", result.Parts[0].Text)
+	}
+}
+
+func TestToolSummarizesOversizedResults(t *testing.T) {
+	s := &stubSummarizer{}
+	p := New(&stubResulterTool{stubTool{text: strings.Repeat("x", 100)}}, WithMaxCharacters(50), WithSummarizer(s))
+
+	result := execute(t, p, context.Background(), map[string]any{"url": "https://example.com"})
+
+	if s.text != "rendered: "+strings.Repeat("x", 100) {
+		t.Fatalf("expected the full result to be summarized, got %q", s.text)
+	}
+
+	if !strings.Contains(s.options.Instructions, `"fetch"`) || !strings.Contains(s.options.Instructions, "https://example.com") {
+		t.Fatalf("expected the call in the instructions, got %q", s.options.Instructions)
+	}
+
+	if !strings.HasPrefix(result.Parts[0].Text, "the gist\n\n[Summarized") {
+		t.Fatalf("expected the summary, got %q", result.Parts[0].Text)
+	}
+
+	if len(result.Parts) != 2 || result.Parts[1].File == nil {
+		t.Fatal("expected the file to be kept")
+	}
+}
+
+func TestToolTruncatesWhenSummarizationFails(t *testing.T) {
+	s := &stubSummarizer{err: errors.New("unavailable")}
+	p := New(&stubTool{text: strings.Repeat("x", 100)}, WithMaxCharacters(50), WithSummarizer(s))
+
+	result := execute(t, p, context.Background(), nil)
+
+	if !strings.Contains(result.Parts[0].Text, "showing 50 of 100 characters") {
+		t.Fatalf("expected a truncated result, got %q", result.Parts[0].Text)
+	}
+}
+
+func TestToolCachesResults(t *testing.T) {
+	s := &stubTool{text: "ok"}
+	p := New(s, WithCache(time.Minute))
+
+	ctx := context.Background()
+
+	execute(t, p, ctx, map[string]any{"a": 1, "b": 2})
+	execute(t, p, ctx, map[string]any{"b": 2, "a": 1})
+
+	if s.calls != 1 {
+		t.Fatalf("expected 1 call, got %d", s.calls)
+	}
+
+	execute(t, p, ctx, map[string]any{"a": 2})
+
+	if s.calls != 2 {
+		t.Fatalf("expected other arguments to miss the cache, got %d calls", s.calls)
+	}
+
+	execute(t, p, context.WithValue(ctx, auth.UserContextKey, "alice"), map[string]any{"a": 1, "b": 2})
+
+	if s.calls != 3 {
+		t.Fatalf("expected other users to miss the cache, got %d calls", s.calls)
+	}
+}
+
+func TestToolCacheExpires(t *testing.T) {
+	s := &stubTool{text: "ok"}
+	p := New(s, WithCache(time.Millisecond))
+
+	execute(t, p, context.Background(), nil)
+
+	time.Sleep(5 * time.Millisecond)
+
+	execute(t, p, context.Background(), nil)
+
+	if s.calls != 2 {
+		t.Fatalf("expected the entry to expire, got %d calls", s.calls)
+	}
+}
@@ -0,0 +1,23 @@
+package tool
+
+import (
+	"context"
+)
+
+// ProgressReporter receives status messages of a running tool call
+type ProgressReporter func(message string)
+
+type progressKey struct{}
+
+// WithProgress lets the tool called with ctx report its progress
+func WithProgress(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressKey{}, reporter)
+}
+
+// ReportProgress reports a status message of the tool call of ctx, if the
+// caller follows its progress
+func ReportProgress(ctx context.Context, message string) {
+	if reporter, ok := ctx.Value(progressKey{}).(ProgressReporter); ok && reporter != nil {
+		reporter(message)
+	}
+}
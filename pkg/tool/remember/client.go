@@ -0,0 +1,137 @@
+// Package remember gives agents the remember, recall and forget tools over
+// the memories of the authenticated user.
+package remember
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/memory"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+const (
+	RememberToolName = "remember"
+	RecallToolName   = "recall"
+	ForgetToolName   = "forget"
+)
+
+var _ tool.Provider = (*Client)(nil)
+
+var errNoUser = errors.New("memory is only available to authenticated users")
+
+type Client struct {
+	provider memory.Provider
+
+	limit int
+}
+
+func New(p memory.Provider, options ...Option) (*Client, error) {
+	if p == nil {
+		return nil, errors.New("remember: missing memory provider")
+	}
+
+	c := &Client{
+		provider: p,
+		limit:    5,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c, nil
+}
+
+func (c *Client) Tools(ctx context.Context) ([]tool.Tool, error) {
+	return []tool.Tool{
+		{
+			Name:        RememberToolName,
+			Description: "Store a fact about the user that stays available in future conversations, such as preferences, ongoing projects or personal details they shared. Write one self-contained fact per call.",
+
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"content": map[string]any{
+						"type":        "string",
+						"description": "The fact to remember, phrased so it makes sense without this conversation.",
+					},
+				},
+				"required": []string{"content"},
+			},
+		},
+		{
+			Name:        RecallToolName,
+			Description: "Search the facts remembered about the user in earlier conversations. Returns each memory with its id.",
+
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "What to look for. Leave empty to get the most recent memories.",
+					},
+				},
+			},
+		},
+		{
+			Name:        ForgetToolName,
+			Description: "Delete a remembered fact that is wrong, outdated or that the user asked to forget. Use recall first to find its id.",
+
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id": map[string]any{
+						"type":        "string",
+						"description": "The id of the memory to delete.",
+					},
+				},
+				"required": []string{"id"},
+			},
+		},
+	}, nil
+}
+
+func (c *Client) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	user, ok := memory.User(ctx)
+
+	if !ok {
+		return nil, errNoUser
+	}
+
+	switch name {
+	case RememberToolName:
+		content, _ := parameters["content"].(string)
+		content = strings.TrimSpace(content)
+
+		if content == "" {
+			return nil, errors.New("remember: missing content parameter")
+		}
+
+		return c.provider.Remember(ctx, user, content)
+
+	case RecallToolName:
+		query, _ := parameters["query"].(string)
+
+		return c.provider.Recall(ctx, user, query, &memory.RecallOptions{
+			Limit: c.limit,
+		})
+
+	case ForgetToolName:
+		id, _ := parameters["id"].(string)
+
+		if id == "" {
+			return nil, errors.New("remember: missing id parameter")
+		}
+
+		if err := c.provider.Forget(ctx, user, id); err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"forgotten": id}, nil
+
+	default:
+		return nil, tool.ErrInvalidTool
+	}
+}
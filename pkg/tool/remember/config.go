@@ -0,0 +1,12 @@
+package remember
+
+type Option func(*Client)
+
+// WithLimit sets the number of memories recall returns by default
+func WithLimit(limit int) Option {
+	return func(c *Client) {
+		if limit > 0 {
+			c.limit = limit
+		}
+	}
+}
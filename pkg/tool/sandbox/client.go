@@ -0,0 +1,402 @@
+// Package sandbox runs shell commands on the server, for callers that cannot
+// execute the shell tool themselves. Every session gets its own workspace
+// directory, which is all of the host filesystem commands see besides a
+// read-only runtime; commands run under CPU, memory, process, file size and
+// time limits and, unless enabled, without network access.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/auth"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/provider/tools/shell"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+const (
+	defaultName = "sandbox"
+
+	defaultTimeout   = time.Minute
+	defaultCPUTime   = 30 * time.Second
+	defaultMemory    = 512 << 20
+	defaultProcesses = 128
+	defaultFileSize  = 64 << 20
+	defaultMaxOutput = 16 * 1024
+
+	defaultIdleTimeout = time.Hour
+
+	// rootDir is the directory below the sandbox root that the root
+	// filesystem of a command is assembled in
+	rootDir = ".root"
+)
+
+var (
+	_ tool.Provider = (*Client)(nil)
+	_ tool.Resulter = (*Client)(nil)
+)
+
+type Client struct {
+	name string
+
+	root string
+
+	timeout time.Duration
+	cpuTime time.Duration
+	memory  int64
+
+	processes int
+	fileSize  int64
+
+	network bool
+
+	maxOutput int
+
+	idleTimeout time.Duration
+
+	mu         sync.Mutex
+	workspaces map[string]time.Time
+}
+
+// New creates a sandbox keeping its workspaces below root, by default a
+// directory in the system's temporary directory. It fails where commands
+// can not be isolated.
+func New(root string, options ...Option) (*Client, error) {
+	if root == "" {
+		root = filepath.Join(os.TempDir(), "wingman-sandbox")
+	}
+
+	c := &Client{
+		name: defaultName,
+
+		root: root,
+
+		timeout: defaultTimeout,
+		cpuTime: defaultCPUTime,
+		memory:  defaultMemory,
+
+		processes: defaultProcesses,
+		fileSize:  defaultFileSize,
+
+		maxOutput: defaultMaxOutput,
+
+		idleTimeout: defaultIdleTimeout,
+
+		workspaces: make(map[string]time.Time),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		return nil, errors.New("sandbox: bash not found")
+	}
+
+	if !isolationSupported {
+		return nil, errors.New("sandbox: isolation is not supported on this platform")
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, rootDir), 0700); err != nil {
+		return nil, err
+	}
+
+	if err := c.adopt(); err != nil {
+		return nil, err
+	}
+
+	// Commands must never run unisolated, e.g. where user namespaces are
+	// disabled
+	if err := c.probe(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// adopt takes over the workspaces a previous process left below the root,
+// to expire them like its own, and removes its temporary directories. Other
+// entries are left alone.
+func (c *Client) adopt() error {
+	entries, err := os.ReadDir(c.root)
+
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		name := e.Name()
+
+		if strings.HasPrefix(name, "tmp-") {
+			os.RemoveAll(filepath.Join(c.root, name))
+			continue
+		}
+
+		if _, err := hex.DecodeString(name); err != nil || len(name) != 32 {
+			continue
+		}
+
+		if info, err := e.Info(); err == nil {
+			c.workspaces[name] = info.ModTime()
+		}
+	}
+
+	c.sweep()
+
+	return nil
+}
+
+// probe runs a command to check that commands can be isolated
+func (c *Client) probe() error {
+	dir, err := os.MkdirTemp(c.root, "tmp-")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(dir)
+
+	if output := c.run(context.Background(), dir, "true", c.timeout); output != "" {
+		return errors.New("sandbox: isolation is not available: " + output)
+	}
+
+	return nil
+}
+
+// Name is the name of the sandbox tool
+func (c *Client) Name() string {
+	return c.name
+}
+
+func (c *Client) Tools(ctx context.Context) ([]tool.Tool, error) {
+	return []tool.Tool{
+		{
+			Name:        c.name,
+			Description: description,
+
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"commands": map[string]any{
+						"type":        "array",
+						"items":       map[string]any{"type": "string"},
+						"description": "Shell commands to run in order, each in a new bash process.",
+					},
+					"timeout_ms": map[string]any{
+						"type":        "integer",
+						"description": "Maximum wall-clock time per command in milliseconds.",
+					},
+				},
+				"required": []string{"commands"},
+			},
+		},
+	}, nil
+}
+
+func (c *Client) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	if name != c.name {
+		return nil, tool.ErrInvalidTool
+	}
+
+	// Arguments of any shell dialect are accepted
+	args, _ := json.Marshal(parameters)
+
+	commands := shell.Commands(string(args))
+
+	if len(commands) == 0 {
+		return nil, errors.New("missing commands")
+	}
+
+	timeout := c.timeout
+
+	if ms, ok := parameters["timeout_ms"].(float64); ok && ms > 0 {
+		timeout = min(timeout, time.Duration(ms)*time.Millisecond)
+	}
+
+	dir, release, err := c.workspace(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	if len(commands) == 1 {
+		return c.run(ctx, dir, commands[0], timeout), nil
+	}
+
+	var outputs []string
+
+	for _, command := range commands {
+		output := strings.TrimRight(c.run(ctx, dir, command, timeout), "\n")
+		outputs = append(outputs, strings.TrimRight("$ "+command+"\n"+output, "\n"))
+	}
+
+	return strings.Join(outputs, "\n\n"), nil
+}
+
+// Result passes the command output to the model as text
+func (c *Client) Result(name string, value any) provider.ToolResult {
+	text, _ := value.(string)
+
+	if text == "" {
+		text = "(no output)"
+	}
+
+	return provider.ToolResult{Parts: []provider.Part{{Text: text}}}
+}
+
+// run runs a command in dir and returns its combined output, noting a
+// non-zero exit code or a timeout
+func (c *Client) run(ctx context.Context, dir, command string, timeout time.Duration) string {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Limits apply to the shell and everything it starts; ulimit -v and -f
+	// take KiB. Processes count per user namespace, so per command.
+	script := fmt.Sprintf("ulimit -t %d -v %d -u %d -f %d || exit 126\n%s", int(c.cpuTime.Seconds()), c.memory/1024, c.processes, c.fileSize/1024, command)
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", script)
+	cmd.Dir = dir
+
+	cmd.Env = []string{
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME=/workspace",
+		"TMPDIR=/tmp",
+		"LANG=C.UTF-8",
+	}
+
+	output := &limitedBuffer{limit: c.maxOutput}
+
+	cmd.Stdin = nil
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	isolate(cmd, filepath.Join(c.root, rootDir), dir, c.network)
+
+	// Processes left behind holding the output open are not waited for
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+
+	text := output.String()
+
+	var exitErr *exec.ExitError
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		text += "\n(command timed out)"
+
+	case errors.As(err, &exitErr):
+		text += fmt.Sprintf("\n(exit code %d)", exitErr.ExitCode())
+
+	case err != nil:
+		text += "\n(" + err.Error() + ")"
+	}
+
+	return strings.TrimPrefix(text, "\n")
+}
+
+// workspace returns the directory of the session of ctx. Calls without a
+// session get a directory that is removed once they are done.
+func (c *Client) workspace(ctx context.Context) (string, func(), error) {
+	session := agent.Session(ctx)
+
+	if session == "" {
+		dir, err := os.MkdirTemp(c.root, "tmp-")
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		return dir, func() { os.RemoveAll(dir) }, nil
+	}
+
+	// Sessions of different users never share a workspace
+	user, _ := ctx.Value(auth.UserContextKey).(string)
+
+	hash := sha256.Sum256([]byte(user + "\x00" + session))
+	key := hex.EncodeToString(hash[:16])
+
+	dir := filepath.Join(c.root, key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sweep()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", nil, err
+	}
+
+	c.workspaces[key] = time.Now()
+
+	return dir, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.workspaces[key] = time.Now()
+	}, nil
+}
+
+// sweep removes the workspaces idle for longer than the idle timeout
+func (c *Client) sweep() {
+	for key, used := range c.workspaces {
+		if time.Since(used) < c.idleTimeout {
+			continue
+		}
+
+		os.RemoveAll(filepath.Join(c.root, key))
+		delete(c.workspaces, key)
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it and counts the
+// rest
+type limitedBuffer struct {
+	limit int
+
+	buf   bytes.Buffer
+	total int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(room, len(p))])
+	}
+
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	text := strings.ToValidUTF8(b.buf.String(), "")
+
+	if b.total > b.limit {
+		text += fmt.Sprintf("\n\n[output truncated: showing %d of %d bytes]", b.limit, b.total)
+	}
+
+	return text
+}
+
+const description = `Run shell commands in a sandboxed Linux workspace and return their combined stdout and stderr output. ` +
+	`Files in the workspace are kept between calls of the same conversation; shell state such as the working directory or environment variables is not. ` +
+	`Commands run under CPU, memory and time limits, and may not have network access.`
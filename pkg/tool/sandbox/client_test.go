@@ -0,0 +1,181 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/auth"
+)
+
+func newClient(t *testing.T, options ...Option) *Client {
+	t.Helper()
+
+	c, err := New(t.TempDir(), options...)
+
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// user namespaces may be disabled on the host
+	if out, _ := c.Execute(context.Background(), c.Name(), map[string]any{"commands": []any{"true"}}); out != "" {
+		t.Skipf("sandbox unavailable: %v", out)
+	}
+
+	return c
+}
+
+func run(t *testing.T, c *Client, ctx context.Context, commands ...string) string {
+	t.Helper()
+
+	var args []any
+
+	for _, command := range commands {
+		args = append(args, command)
+	}
+
+	result, err := c.Execute(ctx, c.Name(), map[string]any{"commands": args})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return result.(string)
+}
+
+func TestWorkspace(t *testing.T) {
+	c := newClient(t)
+
+	ctx := agent.WithSession(context.Background(), "s1")
+
+	run(t, c, ctx, "echo hello > note.txt")
+
+	if out := run(t, c, ctx, "cat note.txt"); out != "hello\n" {
+		t.Fatalf("expected the file to persist in the session, got %q", out)
+	}
+
+	other := context.WithValue(ctx, auth.UserContextKey, "mallory")
+
+	if out := run(t, c, other, "cat note.txt"); !strings.Contains(out, "(exit code 1)") {
+		t.Fatalf("expected workspaces of other users to be separate, got %q", out)
+	}
+
+	if out := run(t, c, context.Background(), "ls"); out != "" {
+		t.Fatalf("expected an empty workspace without a session, got %q", out)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	c := newClient(t, WithMaxOutput(10), WithTimeout(500*time.Millisecond))
+
+	ctx := context.Background()
+
+	if out := run(t, c, ctx, "echo one", "exit 3"); out != "$ echo one\none\n\n$ exit 3\n(exit code 3)" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	if out := run(t, c, ctx, "seq 1 100"); !strings.HasSuffix(out, "[output truncated: showing 10 of 292 bytes]") {
+		t.Fatalf("expected the output to be truncated, got %q", out)
+	}
+
+	start := time.Now()
+
+	if out := run(t, c, ctx, "sleep 10 & sleep 10"); !strings.HasSuffix(out, "(command timed out)") || time.Since(start) > 5*time.Second {
+		t.Fatalf("expected the command to time out, got %q", out)
+	}
+
+	if out := run(t, c, ctx, "cat /proc/net/dev | grep -v lo: | tail -n +3"); out != "" {
+		t.Fatalf("expected no network devices but loopback, got %q", out)
+	}
+
+	if _, err := c.Execute(ctx, "other", nil); err == nil {
+		t.Fatal("expected unknown tools to be rejected")
+	}
+}
+
+func TestFilesystemIsolation(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret.txt")
+
+	if err := os.WriteFile(secret, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, network := range []bool{false, true} {
+		var options []Option
+
+		if network {
+			options = append(options, WithNetwork())
+		}
+
+		c := newClient(t, options...)
+
+		ctx := agent.WithSession(context.Background(), "s1")
+
+		run(t, c, agent.WithSession(ctx, "s2"), "echo other > note.txt")
+
+		if out := run(t, c, ctx, "cat "+secret); !strings.Contains(out, "(exit code 1)") {
+			t.Fatalf("expected host files to be hidden, got %q", out)
+		}
+
+		if out := run(t, c, ctx, "ls "+c.root); !strings.Contains(out, "(exit code 2)") {
+			t.Fatalf("expected other workspaces to be hidden, got %q", out)
+		}
+
+		if out := run(t, c, ctx, "touch /usr/file"); !strings.Contains(out, "(exit code 1)") {
+			t.Fatalf("expected the runtime to be read-only, got %q", out)
+		}
+
+		if out := run(t, c, ctx, "echo hello > /tmp/a && cat /tmp/a && pwd"); out != "hello\n/workspace\n" {
+			t.Fatalf("expected a writable /tmp and workspace, got %q", out)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	c := newClient(t, WithProcesses(16), WithFileSize(1<<20))
+
+	ctx := context.Background()
+
+	if out := run(t, c, ctx, "ulimit -u -f"); !strings.Contains(out, "16\n") || !strings.Contains(out, "1024\n") {
+		t.Fatalf("expected process and file size limits, got %q", out)
+	}
+
+	if out := run(t, c, ctx, "head -c 2M /dev/zero > big"); !strings.Contains(out, "exit code") {
+		t.Fatalf("expected writing beyond the file size to fail, got %q", out)
+	}
+}
+
+func TestAdopt(t *testing.T) {
+	root := t.TempDir()
+
+	stale := filepath.Join(root, strings.Repeat("a", 32))
+	fresh := filepath.Join(root, strings.Repeat("b", 32))
+	temp := filepath.Join(root, "tmp-1")
+	other := filepath.Join(root, "other")
+
+	for _, dir := range []string{stale, fresh, temp, other} {
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(root); err != nil {
+		t.Skip(err)
+	}
+
+	for dir, kept := range map[string]bool{stale: false, temp: false, fresh: true, other: true} {
+		if _, err := os.Stat(dir); (err == nil) != kept {
+			t.Errorf("%s: expected kept=%v, got %v", filepath.Base(dir), kept, err)
+		}
+	}
+}
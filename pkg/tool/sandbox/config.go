@@ -0,0 +1,90 @@
+package sandbox
+
+import (
+	"time"
+)
+
+type Option func(*Client)
+
+// WithName sets the name of the tool (default sandbox)
+func WithName(name string) Option {
+	return func(c *Client) {
+		if name != "" {
+			c.name = name
+		}
+	}
+}
+
+// WithTimeout limits the wall-clock time of a command (default 1 minute)
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// WithCPUTime limits the CPU time of a command (default 30 seconds)
+func WithCPUTime(cpu time.Duration) Option {
+	return func(c *Client) {
+		if cpu >= time.Second {
+			c.cpuTime = cpu
+		}
+	}
+}
+
+// WithMemory limits the virtual memory of each process of a command to size
+// bytes (default 512 MiB)
+func WithMemory(size int64) Option {
+	return func(c *Client) {
+		if size > 0 {
+			c.memory = size
+		}
+	}
+}
+
+// WithProcesses limits the number of processes a command runs at once
+// (default 128)
+func WithProcesses(count int) Option {
+	return func(c *Client) {
+		if count > 0 {
+			c.processes = count
+		}
+	}
+}
+
+// WithFileSize limits the size of each file a command writes to size bytes
+// (default 64 MiB)
+func WithFileSize(size int64) Option {
+	return func(c *Client) {
+		if size >= 1024 {
+			c.fileSize = size
+		}
+	}
+}
+
+// WithNetwork lets commands access the network
+func WithNetwork() Option {
+	return func(c *Client) {
+		c.network = true
+	}
+}
+
+// WithMaxOutput truncates the output of a command beyond size bytes
+// (default 16 KiB)
+func WithMaxOutput(size int) Option {
+	return func(c *Client) {
+		if size > 0 {
+			c.maxOutput = size
+		}
+	}
+}
+
+// WithIdleTimeout removes workspaces not used for this long (default 1 hour)
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.idleTimeout = timeout
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+const isolationSupported = true
+
+// initName is the argv[0] the sandbox runs its own binary with, to set up
+// the filesystem of a command in its namespaces before running it
+const initName = "wingman-sandbox-init"
+
+// runtimePaths are mounted read-only into the root of a command, as far as
+// they exist on the host
+var runtimePaths = []string{
+	"/usr",
+	"/bin",
+	"/sbin",
+	"/lib",
+	"/lib32",
+	"/lib64",
+	"/libx32",
+
+	"/etc/alternatives",
+	"/etc/ca-certificates",
+	"/etc/ssl",
+	"/etc/ld.so.cache",
+	"/etc/ld.so.conf",
+	"/etc/ld.so.conf.d",
+	"/etc/localtime",
+	"/etc/passwd",
+	"/etc/group",
+	"/etc/nsswitch.conf",
+	"/etc/hosts",
+	"/etc/resolv.conf",
+}
+
+// devices are bound from the host into the /dev of a command
+var devices = []string{"null", "zero", "full", "random", "urandom"}
+
+// lockedFlags are the mount flags a remount in a user namespace must keep
+var lockedFlags = []struct {
+	statfs int64
+	mount  uintptr
+}{
+	{0x2, syscall.MS_NOSUID},
+	{0x4, syscall.MS_NODEV},
+	{0x8, syscall.MS_NOEXEC},
+	{0x400, syscall.MS_NOATIME},
+	{0x800, syscall.MS_NODIRATIME},
+	{0x1000, syscall.MS_RELATIME},
+}
+
+func init() {
+	if len(os.Args) == 0 || os.Args[0] != initName {
+		return
+	}
+
+	err := setup(os.Args[1:])
+
+	fmt.Fprintln(os.Stderr, "sandbox:", err)
+	os.Exit(126)
+}
+
+// isolate runs the command in its own process group, so a timeout stops
+// everything it started, and in new user, mount and PID namespaces. Its root
+// only holds the workspace dir, a read-only runtime and a private /tmp.
+// Without network the command also gets a network namespace that only has
+// a loopback device.
+func isolate(cmd *exec.Cmd, root, dir string, network bool) {
+	cmd.Path = "/proc/self/exe"
+	cmd.Args = append([]string{initName, root, dir}, cmd.Args...)
+
+	attr := &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+	}
+
+	if !network {
+		attr.Cloneflags |= syscall.CLONE_NEWNET
+	}
+
+	cmd.SysProcAttr = attr
+
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// setup assembles the root of a command on a tmpfs at root, pivots into it
+// and runs the command. It only returns on failure.
+func setup(args []string) error {
+	if len(args) < 3 {
+		return errors.New("invalid arguments")
+	}
+
+	root, dir, command := args[0], args[1], args[2:]
+
+	// Mounts stay private to the namespace of the command
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("private mounts: %w", err)
+	}
+
+	if err := syscall.Mount("tmpfs", root, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=0755"); err != nil {
+		return fmt.Errorf("root: %w", err)
+	}
+
+	for _, p := range runtimePaths {
+		if err := bindRuntime(root, p); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+	}
+
+	if err := bind(dir, filepath.Join(root, "workspace")); err != nil {
+		return fmt.Errorf("workspace: %w", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "tmp"), 0755); err != nil {
+		return err
+	}
+
+	if err := syscall.Mount("tmpfs", filepath.Join(root, "tmp"), "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=1777,size=64m"); err != nil {
+		return fmt.Errorf("/tmp: %w", err)
+	}
+
+	for _, name := range devices {
+		if err := bind(filepath.Join("/dev", name), filepath.Join(root, "dev", name)); err != nil {
+			return fmt.Errorf("/dev/%s: %w", name, err)
+		}
+	}
+
+	// Hosts masking parts of their /proc refuse a new one; commands run
+	// without it then
+	if err := os.Mkdir(filepath.Join(root, "proc"), 0555); err == nil {
+		syscall.Mount("proc", filepath.Join(root, "proc"), "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+	}
+
+	if err := os.Chdir(root); err != nil {
+		return err
+	}
+
+	// Stack the new root on the old one and detach the old one, so no path
+	// leads back to the host filesystem
+	if err := syscall.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("pivot root: %w", err)
+	}
+
+	if err := syscall.Unmount(".", syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("detach host root: %w", err)
+	}
+
+	if err := syscall.Mount("", "/", "", syscall.MS_REMOUNT|syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("read-only root: %w", err)
+	}
+
+	if err := os.Chdir("/workspace"); err != nil {
+		return err
+	}
+
+	path, err := exec.LookPath(command[0])
+
+	if err != nil {
+		return err
+	}
+
+	return syscall.Exec(path, command, os.Environ())
+}
+
+// bindRuntime mounts the host path p read-only below root. Relative symlinks
+// of directories, such as /bin to usr/bin on merged systems, are kept as
+// links; other links are followed.
+func bindRuntime(root, p string) error {
+	info, err := os.Lstat(p)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(p)
+
+		if err != nil {
+			return err
+		}
+
+		if stat, err := os.Stat(p); err != nil {
+			return nil
+		} else if stat.IsDir() && !filepath.IsAbs(link) {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(root, p)), 0755); err != nil {
+				return err
+			}
+
+			return os.Symlink(link, filepath.Join(root, p))
+		}
+	}
+
+	target := filepath.Join(root, p)
+
+	if err := bind(p, target); err != nil {
+		return err
+	}
+
+	return readOnly(target)
+}
+
+// bind mounts the host path source at target, creating the mount point
+func bind(source, target string) error {
+	info, err := os.Stat(source)
+
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		err = os.MkdirAll(target, 0755)
+	} else {
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+
+		if err == nil {
+			err = os.WriteFile(target, nil, 0644)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return syscall.Mount(source, target, "", syscall.MS_BIND|syscall.MS_REC, "")
+}
+
+// readOnly remounts the bind mount at target read-only, keeping the flags of
+// the host mount that a user namespace can not drop
+func readOnly(target string) error {
+	var st syscall.Statfs_t
+
+	if err := syscall.Statfs(target, &st); err != nil {
+		return err
+	}
+
+	flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY)
+
+	for _, f := range lockedFlags {
+		if int64(st.Flags)&f.statfs != 0 {
+			flags |= f.mount
+		}
+	}
+
+	return syscall.Mount("", target, "", flags, "")
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"os/exec"
+)
+
+// Isolation relies on Linux namespaces
+const isolationSupported = false
+
+func isolate(cmd *exec.Cmd, root, dir string, network bool) {
+}
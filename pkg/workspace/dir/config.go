@@ -0,0 +1,26 @@
+package dir
+
+import (
+	"time"
+)
+
+type Option func(*Store)
+
+// WithIdleTimeout removes workspaces not used for this long (default 24
+// hours)
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(s *Store) {
+		if timeout > 0 {
+			s.idleTimeout = timeout
+		}
+	}
+}
+
+// WithMaxSize limits the files of a workspace to size bytes (default 64 MiB)
+func WithMaxSize(size int64) Option {
+	return func(s *Store) {
+		if size > 0 {
+			s.maxSize = size
+		}
+	}
+}
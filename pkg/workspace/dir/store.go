@@ -0,0 +1,271 @@
+// Package dir keeps workspaces in a local directory, one subdirectory per
+// workspace key. The modification time of a subdirectory tracks when its
+// workspace was last used.
+package dir
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/workspace"
+)
+
+var _ workspace.Provider = (*Store)(nil)
+
+type Store struct {
+	root string
+
+	idleTimeout time.Duration
+
+	maxSize int64
+
+	// mu serializes writes, so concurrent writes can not exceed the quota
+	// and sweeps never remove a workspace being written
+	mu sync.Mutex
+}
+
+// New keeps the workspaces below root, creating it if needed
+func New(root string, options ...Option) (*Store, error) {
+	if root == "" {
+		return nil, errors.New("workspace: missing path")
+	}
+
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		root: root,
+
+		idleTimeout: workspace.DefaultIdleTimeout,
+
+		maxSize: workspace.DefaultMaxSize,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s, nil
+}
+
+func (s *Store) List(ctx context.Context, key string) ([]workspace.File, error) {
+	dir, err := s.dir(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	touch(dir)
+
+	var result []workspace.File
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(dir, p)
+
+		result = append(result, workspace.File{
+			Path: filepath.ToSlash(rel),
+			Size: info.Size(),
+
+			Modified: info.ModTime().UTC(),
+		})
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *Store) Read(ctx context.Context, key, path string) ([]byte, error) {
+	p, err := s.path(key, path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	touch(filepath.Join(s.root, key))
+
+	data, err := os.ReadFile(p)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, workspace.ErrNotFound
+	}
+
+	return data, err
+}
+
+func (s *Store) Write(ctx context.Context, key, path string, data []byte) error {
+	p, err := s.path(key, path)
+
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+
+	dir, _ := s.dir(key)
+
+	size, err := usage(dir, p)
+
+	if err != nil {
+		return err
+	}
+
+	if size+int64(len(data)) > s.maxSize {
+		return workspace.ErrQuotaExceeded
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+
+	touch(dir)
+
+	// Write and rename, so readers never see a partial file
+	f, err := os.CreateTemp(filepath.Dir(p), ".write-*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), p)
+}
+
+func (s *Store) Delete(ctx context.Context, key, path string) error {
+	p, err := s.path(key, path)
+
+	if err != nil {
+		return err
+	}
+
+	touch(filepath.Join(s.root, key))
+
+	err = os.Remove(p)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return workspace.ErrNotFound
+	}
+
+	return err
+}
+
+func (s *Store) dir(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(key) || filepath.Base(key) != key {
+		return "", errors.New("workspace: invalid key")
+	}
+
+	return filepath.Join(s.root, key), nil
+}
+
+func (s *Store) path(key, path string) (string, error) {
+	dir, err := s.dir(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	path, err = workspace.Clean(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(path)), nil
+}
+
+// sweep removes the workspaces idle for longer than the idle timeout
+func (s *Store) sweep() {
+	entries, err := os.ReadDir(s.root)
+
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		info, err := e.Info()
+
+		if err != nil || time.Since(info.ModTime()) < s.idleTimeout {
+			continue
+		}
+
+		os.RemoveAll(filepath.Join(s.root, e.Name()))
+	}
+}
+
+// usage returns the size of the files in dir, except the file at skip
+func usage(dir, skip string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+
+			return err
+		}
+
+		if p == skip || !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+
+		return nil
+	})
+
+	return size, err
+}
+
+// touch marks the workspace directory as used
+func touch(dir string) {
+	now := time.Now()
+	os.Chtimes(dir, now, now)
+}
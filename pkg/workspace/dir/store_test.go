@@ -0,0 +1,108 @@
+package dir
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/workspace"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := New(t.TempDir())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if files, err := s.List(ctx, "k1"); err != nil || len(files) != 0 {
+		t.Fatalf("expected an empty workspace, got %v (%v)", files, err)
+	}
+
+	if err := s.Write(ctx, "k1", "notes/a.md", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := s.Read(ctx, "k1", "/notes/a.md"); err != nil || string(data) != "hello" {
+		t.Fatalf("unexpected content %q (%v)", data, err)
+	}
+
+	files, _ := s.List(ctx, "k1")
+
+	if len(files) != 1 || files[0].Path != "notes/a.md" || files[0].Size != 5 {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	if _, err := s.Read(ctx, "k2", "notes/a.md"); !errors.Is(err, workspace.ErrNotFound) {
+		t.Fatalf("expected workspaces to be separate, got %v", err)
+	}
+
+	if err := s.Write(ctx, "../k1", "a.md", nil); err == nil {
+		t.Fatal("expected an error for an invalid key")
+	}
+
+	if err := s.Delete(ctx, "k1", "notes/a.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Delete(ctx, "k1", "notes/a.md"); !errors.Is(err, workspace.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestStoreQuota(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := New(t.TempDir(), WithMaxSize(10))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Write(ctx, "k1", "a.md", []byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Write(ctx, "k1", "b.md", []byte("123")); !errors.Is(err, workspace.ErrQuotaExceeded) {
+		t.Fatalf("expected the quota to be exceeded, got %v", err)
+	}
+
+	if err := s.Write(ctx, "k1", "a.md", []byte("1234567890")); err != nil {
+		t.Fatalf("expected a file to be replaced within the quota, got %v", err)
+	}
+
+	if err := s.Write(ctx, "k2", "b.md", []byte("123")); err != nil {
+		t.Fatalf("expected a quota per workspace, got %v", err)
+	}
+}
+
+func TestStoreRemovesIdleWorkspaces(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := New(t.TempDir(), WithIdleTimeout(10*time.Millisecond))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Write(ctx, "k1", "a.md", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := s.Write(ctx, "k2", "a.md", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Read(ctx, "k1", "a.md"); !errors.Is(err, workspace.ErrNotFound) {
+		t.Fatalf("expected the idle workspace to be removed, got %v", err)
+	}
+
+	if _, err := s.Read(ctx, "k2", "a.md"); err != nil {
+		t.Fatalf("expected the used workspace to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package mem
+
+import (
+	"time"
+)
+
+// defaultMaxTotalSize bounds the memory the files of all workspaces take
+const defaultMaxTotalSize = 1 << 30
+
+type Option func(*Store)
+
+// WithIdleTimeout removes workspaces not used for this long (default 24
+// hours)
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(s *Store) {
+		if timeout > 0 {
+			s.idleTimeout = timeout
+		}
+	}
+}
+
+// WithMaxSize limits the files of a workspace to size bytes (default 64 MiB)
+func WithMaxSize(size int64) Option {
+	return func(s *Store) {
+		if size > 0 {
+			s.maxSize = size
+		}
+	}
+}
+
+// WithMaxTotalSize limits the files of all workspaces to size bytes (default
+// 1 GiB)
+func WithMaxTotalSize(size int64) Option {
+	return func(s *Store) {
+		if size > 0 {
+			s.maxTotalSize = size
+		}
+	}
+}
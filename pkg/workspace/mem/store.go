@@ -0,0 +1,194 @@
+// Package mem keeps workspaces in memory; they are lost on restart
+package mem
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/workspace"
+)
+
+var _ workspace.Provider = (*Store)(nil)
+
+type Store struct {
+	idleTimeout time.Duration
+
+	maxSize      int64
+	maxTotalSize int64
+
+	mu         sync.Mutex
+	workspaces map[string]*space
+
+	// size is the size of the files of all workspaces
+	size int64
+}
+
+type space struct {
+	files map[string]file
+	size  int64
+
+	used time.Time
+}
+
+type file struct {
+	data     []byte
+	modified time.Time
+}
+
+func New(options ...Option) *Store {
+	s := &Store{
+		idleTimeout: workspace.DefaultIdleTimeout,
+
+		maxSize:      workspace.DefaultMaxSize,
+		maxTotalSize: defaultMaxTotalSize,
+
+		workspaces: make(map[string]*space),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+func (s *Store) List(ctx context.Context, key string) ([]workspace.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []workspace.File
+
+	for p, f := range s.space(key, false).files {
+		result = append(result, workspace.File{
+			Path: p,
+			Size: int64(len(f.data)),
+
+			Modified: f.modified,
+		})
+	}
+
+	slices.SortFunc(result, func(a, b workspace.File) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return result, nil
+}
+
+func (s *Store) Read(ctx context.Context, key, path string) ([]byte, error) {
+	path, err := workspace.Clean(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.space(key, false).files[path]
+
+	if !ok {
+		return nil, workspace.ErrNotFound
+	}
+
+	return slices.Clone(f.data), nil
+}
+
+func (s *Store) Write(ctx context.Context, key, path string, data []byte) error {
+	path, err := workspace.Clean(path)
+
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.space(key, true)
+
+	grow := int64(len(data)) - int64(len(w.files[path].data))
+
+	if w.size+grow > s.maxSize || s.size+grow > s.maxTotalSize {
+		if len(w.files) == 0 {
+			delete(s.workspaces, key)
+		}
+
+		return workspace.ErrQuotaExceeded
+	}
+
+	w.files[path] = file{
+		data:     slices.Clone(data),
+		modified: time.Now().UTC(),
+	}
+
+	w.size += grow
+	s.size += grow
+
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, key, path string) error {
+	path, err := workspace.Clean(path)
+
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.space(key, false)
+
+	f, ok := w.files[path]
+
+	if !ok {
+		return workspace.ErrNotFound
+	}
+
+	delete(w.files, path)
+
+	w.size -= int64(len(f.data))
+	s.size -= int64(len(f.data))
+
+	if len(w.files) == 0 {
+		delete(s.workspaces, key)
+	}
+
+	return nil
+}
+
+// space returns the workspace of key, marking it as used. Unless create is
+// set, a missing workspace is returned empty without being stored.
+func (s *Store) space(key string, create bool) *space {
+	s.sweep()
+
+	w, ok := s.workspaces[key]
+
+	if !ok {
+		w = &space{
+			files: make(map[string]file),
+		}
+
+		if create {
+			s.workspaces[key] = w
+		}
+	}
+
+	w.used = time.Now()
+
+	return w
+}
+
+// sweep removes the workspaces idle for longer than the idle timeout
+func (s *Store) sweep() {
+	for key, w := range s.workspaces {
+		if time.Since(w.used) < s.idleTimeout {
+			continue
+		}
+
+		s.size -= w.size
+		delete(s.workspaces, key)
+	}
+}
@@ -0,0 +1,60 @@
+package mem
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/workspace"
+)
+
+func TestStoreQuota(t *testing.T) {
+	ctx := context.Background()
+
+	s := New(WithMaxSize(10), WithMaxTotalSize(15))
+
+	if err := s.Write(ctx, "k1", "a.md", []byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Write(ctx, "k1", "b.md", []byte("123")); !errors.Is(err, workspace.ErrQuotaExceeded) {
+		t.Fatalf("expected the quota to be exceeded, got %v", err)
+	}
+
+	if err := s.Write(ctx, "k1", "a.md", []byte("1234567890")); err != nil {
+		t.Fatalf("expected a file to be replaced within the quota, got %v", err)
+	}
+
+	if err := s.Write(ctx, "k2", "b.md", []byte("123456")); !errors.Is(err, workspace.ErrQuotaExceeded) {
+		t.Fatalf("expected the total quota to be exceeded, got %v", err)
+	}
+
+	if err := s.Delete(ctx, "k1", "a.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Write(ctx, "k2", "b.md", []byte("123456")); err != nil {
+		t.Fatalf("expected deleted files to free the quota, got %v", err)
+	}
+}
+
+func TestStoreRemovesIdleWorkspaces(t *testing.T) {
+	ctx := context.Background()
+
+	s := New(WithIdleTimeout(10*time.Millisecond), WithMaxTotalSize(10))
+
+	if err := s.Write(ctx, "k1", "a.md", []byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := s.Write(ctx, "k2", "a.md", []byte("12345678")); err != nil {
+		t.Fatalf("expected the idle workspace to free the quota, got %v", err)
+	}
+
+	if _, err := s.Read(ctx, "k1", "a.md"); !errors.Is(err, workspace.ErrNotFound) {
+		t.Fatalf("expected the idle workspace to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package workspace stores the files agents work on across the turns of a
+// session, such as the drafts of a text editor tool.
+package workspace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/adrianliechti/wingman/pkg/auth"
+)
+
+var (
+	ErrNotFound    = errors.New("file not found")
+	ErrInvalidPath = errors.New("invalid path")
+
+	ErrQuotaExceeded = errors.New("workspace quota exceeded")
+)
+
+const (
+	// DefaultIdleTimeout is how long stores keep workspaces not used
+	DefaultIdleTimeout = 24 * time.Hour
+
+	// DefaultMaxSize limits the size of the files of a workspace
+	DefaultMaxSize = 64 << 20
+)
+
+// Provider stores files per workspace. Workspaces are addressed by key, see
+// Key; paths are cleaned with Clean. Stores remove idle workspaces and fail
+// writes beyond their quota with ErrQuotaExceeded.
+type Provider interface {
+	List(ctx context.Context, key string) ([]File, error)
+
+	Read(ctx context.Context, key, path string) ([]byte, error)
+	Write(ctx context.Context, key, path string, data []byte) error
+
+	Delete(ctx context.Context, key, path string) error
+}
+
+type File struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+
+	Modified time.Time `json:"modified"`
+}
+
+// Key returns the workspace key of a session of the authenticated user, so
+// sessions of different users never share a workspace
+func Key(ctx context.Context, session string) string {
+	user, _ := ctx.Value(auth.UserContextKey).(string)
+
+	hash := sha256.Sum256([]byte(user + "\x00" + session))
+
+	return hex.EncodeToString(hash[:16])
+}
+
+// Clean returns path relative to the workspace root. Leading slashes are
+// dropped, so /notes/a.md and notes/a.md are the same file; paths leaving
+// the root are invalid.
+func Clean(p string) (string, error) {
+	p = strings.ReplaceAll(p, "\\", "/")
+
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return "", ErrInvalidPath
+		}
+	}
+
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+
+	if p == "" {
+		return "", ErrInvalidPath
+	}
+
+	return p, nil
+}
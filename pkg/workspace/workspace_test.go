@@ -0,0 +1,26 @@
+package workspace
+
+import (
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	tests := map[string]string{
+		"a.md":          "a.md",
+		"/notes/a.md":   "notes/a.md",
+		"notes//./a.md": "notes/a.md",
+		"notes\\a.md":   "notes/a.md",
+	}
+
+	for input, expected := range tests {
+		if p, err := Clean(input); err != nil || p != expected {
+			t.Fatalf("%q: expected %q, got %q (%v)", input, expected, p, err)
+		}
+	}
+
+	for _, input := range []string{"", "/", "../a.md", "notes/../../a.md"} {
+		if _, err := Clean(input); err == nil {
+			t.Fatalf("%q: expected an invalid path", input)
+		}
+	}
+}
@@ -495,19 +495,9 @@ func (s *StreamingAccumulator) Complete() error {
 	}
 
 	// Send message_delta with stop_reason and usage
-	stopDetails := (*StopDetails)(nil)
-	if s.stopReason == StopReasonRefusal {
-		stopDetails = &StopDetails{Type: "refusal"}
-
-		if result.StopDetails != nil {
-			stopDetails.Category = result.StopDetails.Category
-			stopDetails.Explanation = result.StopDetails.Explanation
-		}
-	}
-
 	messageDelta := &MessageDelta{
 		StopReason:  s.stopReason,
-		StopDetails: stopDetails,
+		StopDetails: toStopDetails(result, s.stopReason),
 	}
 
 	if s.stopReason == StopReasonStopSequence {
@@ -506,6 +506,10 @@ func toStopReason(completion *provider.Completion) StopReason {
 		return StopReasonRefusal
 	case provider.StopReasonContextExceeded:
 		return StopReasonModelContextWindowExceeded
+	case provider.StopReasonAgentLimit:
+		return StopReasonAgentLimit
+	case provider.StopReasonSchemaMismatch:
+		return StopReasonSchemaMismatch
 	}
 
 	switch completion.Status {
@@ -530,6 +534,32 @@ func toStopReason(completion *provider.Completion) StopReason {
 	return StopReasonEndTurn
 }
 
+// toStopDetails returns the stop details of a refusal, an agent limit or
+// structured output checked against its schema, or nil
+func toStopDetails(completion *provider.Completion, reason StopReason) *StopDetails {
+	details := completion.StopDetails
+
+	switch {
+	case reason == StopReasonRefusal, reason == StopReasonAgentLimit, reason == StopReasonSchemaMismatch:
+	case details != nil && details.Type == "schema":
+	default:
+		return nil
+	}
+
+	result := &StopDetails{Type: string(reason)}
+
+	if details != nil {
+		if details.Type != "" && reason != StopReasonRefusal {
+			result.Type = details.Type
+		}
+
+		result.Category = details.Category
+		result.Explanation = details.Explanation
+	}
+
+	return result
+}
+
 func generateMessageID() string {
 	return fmt.Sprintf("msg_%s", generateID(24))
 }
@@ -1,13 +1,18 @@
 package anthropic
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 
+	"github.com/adrianliechti/wingman/pkg/agent"
 	"github.com/adrianliechti/wingman/pkg/policy"
 	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/router/classifier"
+	"github.com/adrianliechti/wingman/server/openai/shared"
 )
 
 func thinkingEnabled(options *provider.CompleteOptions) bool {
@@ -45,6 +50,9 @@ func (h *Handler) handleMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Server-side tools of agents keep their files in the session
+	r = shared.WithSession(w, r)
+
 	system, err := parseSystemContent(req.System)
 
 	if err != nil {
@@ -239,7 +247,12 @@ func validateMessageRequest(req MessageRequest) error {
 func (h *Handler) handleMessagesComplete(w http.ResponseWriter, r *http.Request, req MessageRequest, completer provider.Completer, messages []provider.Message, options *provider.CompleteOptions) {
 	acc := provider.CompletionAccumulator{}
 
-	for completion, err := range completer.Complete(r.Context(), messages, options) {
+	messageID := generateMessageID()
+
+	// Feedback on routed requests refers to the id the client receives
+	ctx := classifier.WithResponseID(r.Context(), messageID)
+
+	for completion, err := range completer.Complete(ctx, messages, options) {
 		if err != nil {
 			writeError(w, http.StatusBadRequest, err)
 			return
@@ -251,7 +264,7 @@ func (h *Handler) handleMessagesComplete(w http.ResponseWriter, r *http.Request,
 	completion := acc.Result()
 
 	result := Message{
-		ID: generateMessageID(),
+		ID: messageID,
 
 		Type: "message",
 		Role: "assistant",
@@ -293,14 +306,7 @@ func (h *Handler) handleMessagesComplete(w http.ResponseWriter, r *http.Request,
 			result.StopSequence = &completion.StopSequence
 		}
 
-		if reason == StopReasonRefusal {
-			result.StopDetails = &StopDetails{Type: "refusal"}
-
-			if completion.StopDetails != nil {
-				result.StopDetails.Category = completion.StopDetails.Category
-				result.StopDetails.Explanation = completion.StopDetails.Explanation
-			}
-		}
+		result.StopDetails = toStopDetails(completion, reason)
 	}
 
 	writeJson(w, result)
@@ -386,7 +392,22 @@ func (h *Handler) handleMessagesStream(w http.ResponseWriter, r *http.Request, r
 
 	accumulator.ThinkingEnabled = thinkingEnabled(options)
 
-	for completion, err := range completer.Complete(r.Context(), messages, options) {
+	// Agents streaming tool progress report it between content events as
+	// custom events; parallel tool calls report concurrently
+	var progressMu sync.Mutex
+
+	ctx := agent.WithProgressReporter(r.Context(), func(ctx context.Context, p agent.ToolProgress) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+
+		sendHeaders()
+		shared.WriteToolProgressEvent(w, p)
+	})
+
+	// Feedback on routed requests refers to the id the client receives
+	ctx = classifier.WithResponseID(ctx, messageID)
+
+	for completion, err := range completer.Complete(ctx, messages, options) {
 		if err != nil {
 			if !headersSent {
 				writeError(w, http.StatusBadRequest, err)
@@ -0,0 +1,141 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/config"
+	"github.com/adrianliechti/wingman/pkg/policy/noop"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+const stopTestModel = "stop-test-model"
+
+// stopCompleter streams a short answer and ends with the given completion.
+type stopCompleter struct {
+	final provider.Completion
+}
+
+func (c stopCompleter) Complete(_ context.Context, _ []provider.Message, _ *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		if !yield(&provider.Completion{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{provider.TextContent("so far")},
+			},
+		}, nil) {
+			return
+		}
+
+		final := c.final
+		yield(&final, nil)
+	}
+}
+
+func postStop(t *testing.T, final provider.Completion, stream bool) string {
+	t.Helper()
+
+	cfg := &config.Config{Policy: noop.New()}
+	cfg.RegisterCompleter(stopTestModel, stopCompleter{final: final})
+
+	mode := "false"
+	if stream {
+		mode = "true"
+	}
+
+	body := []byte(`{"model": "` + stopTestModel + `", "max_tokens": 100, "stream": ` + mode + `, "messages": [{"role": "user", "content": "x"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/messages", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	New(cfg).handleMessages(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	return rec.Body.String()
+}
+
+func TestAgentLimitStopReason(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonAgentLimit,
+		StopDetails: &provider.StopDetails{
+			Type:        "agent_limit",
+			Category:    "timeout",
+			Explanation: "the agent ran out of time",
+		},
+	}
+
+	want := `"stop_reason":"agent_limit","stop_details":{"type":"agent_limit","category":"timeout","explanation":"the agent ran out of time"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
+
+func TestMaxTokensStopReason(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonMaxTokens,
+	}
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, `"stop_reason":"max_tokens","stop_details":null`) {
+			t.Fatalf("stream=%v: expected max_tokens without stop details in\n%s", stream, body)
+		}
+	}
+}
+
+func TestSchemaMismatchStopReason(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonSchemaMismatch,
+		StopDetails: &provider.StopDetails{
+			Type:        "schema",
+			Category:    "invalid",
+			Explanation: "missing property name",
+		},
+	}
+
+	want := `"stop_reason":"schema_mismatch","stop_details":{"type":"schema","category":"invalid","explanation":"missing property name"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
+
+func TestSchemaRepairedStopDetails(t *testing.T) {
+	final := provider.Completion{
+		StopReason: provider.StopReasonEndTurn,
+		StopDetails: &provider.StopDetails{
+			Type:     "schema",
+			Category: "repaired",
+		},
+	}
+
+	want := `"stop_reason":"end_turn","stop_details":{"type":"schema","category":"repaired"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
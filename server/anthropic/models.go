@@ -212,11 +212,18 @@ const (
 	StopReasonCompaction                 StopReason = "compaction"
 	StopReasonRefusal                    StopReason = "refusal"
 	StopReasonModelContextWindowExceeded StopReason = "model_context_window_exceeded"
+
+	// StopReasonAgentLimit marks an agent run ended by one of its limits
+	StopReasonAgentLimit StopReason = "agent_limit"
+
+	// StopReasonSchemaMismatch marks structured output that did not match the
+	// requested schema
+	StopReasonSchemaMismatch StopReason = "schema_mismatch"
 )
 
 type StopDetails struct {
-	Type        string `json:"type"`               // "refusal"
-	Category    string `json:"category,omitempty"` // e.g. "cyber"
+	Type        string `json:"type"`               // "refusal", "agent_limit", "schema"
+	Category    string `json:"category,omitempty"` // e.g. "cyber", "max_turns", "repaired"
 	Explanation string `json:"explanation,omitempty"`
 }
 
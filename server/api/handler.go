@@ -24,6 +24,18 @@ func New(cfg *config.Config) *Handler {
 func (h *Handler) Attach(r chi.Router) {
 	r.Get("/token", h.handleToken)
 
+	r.Post("/feedback", h.handleFeedback)
+	r.Get("/feedback/{model}", h.handleFeedbackReport)
+
+	r.Get("/memories/{memory}/{user}", h.handleMemories)
+	r.Delete("/memories/{memory}/{user}", h.handleMemoriesPurge)
+	r.Delete("/memories/{memory}/{user}/{id}", h.handleMemoryForget)
+
+	r.Get("/workspaces/{workspace}/{session}/files", h.handleWorkspaceFiles)
+	r.Get("/workspaces/{workspace}/{session}/files/*", h.handleWorkspaceFileGet)
+	r.Put("/workspaces/{workspace}/{session}/files/*", h.handleWorkspaceFilePut)
+	r.Delete("/workspaces/{workspace}/{session}/files/*", h.handleWorkspaceFileDelete)
+
 	r.Post("/extract", h.handleExtract)
 	r.Post("/render", h.handleRender)
 
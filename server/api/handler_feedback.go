@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/adrianliechti/wingman/pkg/policy"
+	"github.com/adrianliechti/wingman/pkg/router/classifier"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type FeedbackRequest struct {
+	// Model optionally names the classifier router that served the response.
+	// Without it every classifier is asked for the response id.
+	Model string `json:"model,omitempty"`
+
+	ID string `json:"id"`
+
+	Rating  float64 `json:"rating,omitempty"`
+	Outcome string  `json:"outcome,omitempty"`
+}
+
+type FeedbackResponse struct {
+	Model string `json:"model"`
+	ID    string `json:"id"`
+}
+
+type FeedbackReport struct {
+	Model string `json:"model"`
+
+	Tiers      []FeedbackTier      `json:"tiers"`
+	Candidates []FeedbackCandidate `json:"candidates"`
+}
+
+type FeedbackTier struct {
+	Tier string `json:"tier"`
+
+	Good int `json:"good"`
+	Bad  int `json:"bad"`
+
+	Accuracy float64 `json:"accuracy"`
+}
+
+type FeedbackCandidate struct {
+	Model string `json:"model"`
+
+	MaxDifficulty int     `json:"max_difficulty"`
+	Threshold     float64 `json:"threshold"`
+
+	Good int `json:"good"`
+	Bad  int `json:"bad"`
+
+	Accuracy float64 `json:"accuracy"`
+}
+
+func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	var req FeedbackRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.ID == "" {
+		writeError(w, http.StatusBadRequest, errors.New("id is required"))
+		return
+	}
+
+	models := h.Classifiers()
+
+	if req.Model != "" {
+		models = []string{req.Model}
+	}
+
+	feedback := classifier.Feedback{
+		Rating:  req.Rating,
+		Outcome: req.Outcome,
+	}
+
+	for _, model := range models {
+		c, err := h.Classifier(model)
+
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := h.Policy.Verify(r.Context(), policy.ResourceModel, model, policy.ActionAccess); err != nil {
+			continue
+		}
+
+		err = c.Feedback(r.Context(), req.ID, feedback)
+
+		if errors.Is(err, classifier.ErrDecisionNotFound) {
+			continue
+		}
+
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeJson(w, FeedbackResponse{
+			Model: model,
+			ID:    req.ID,
+		})
+
+		return
+	}
+
+	writeError(w, http.StatusNotFound, classifier.ErrDecisionNotFound)
+}
+
+func (h *Handler) handleFeedbackReport(w http.ResponseWriter, r *http.Request) {
+	model := chi.URLParam(r, "model")
+
+	c, err := h.Classifier(model)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := h.Policy.Verify(r.Context(), policy.ResourceModel, model, policy.ActionAccess); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	report := c.Report()
+
+	result := FeedbackReport{
+		Model: model,
+
+		Tiers:      []FeedbackTier{},
+		Candidates: []FeedbackCandidate{},
+	}
+
+	for _, t := range report.Tiers {
+		result.Tiers = append(result.Tiers, FeedbackTier{
+			Tier: string(t.Tier),
+
+			Good: t.Good,
+			Bad:  t.Bad,
+
+			Accuracy: t.Accuracy,
+		})
+	}
+
+	for _, c := range report.Candidates {
+		result.Candidates = append(result.Candidates, FeedbackCandidate{
+			Model: c.Model,
+
+			MaxDifficulty: c.MaxDifficulty,
+			Threshold:     c.Threshold,
+
+			Good: c.Good,
+			Bad:  c.Bad,
+
+			Accuracy: c.Accuracy,
+		})
+	}
+
+	writeJson(w, result)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adrianliechti/wingman/pkg/memory"
+	"github.com/adrianliechti/wingman/pkg/policy"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type MemoryList struct {
+	User string `json:"user"`
+
+	Memories []memory.Memory `json:"memories"`
+}
+
+func (h *Handler) handleMemories(w http.ResponseWriter, r *http.Request) {
+	p, user, ok := h.memoryStore(w, r)
+
+	if !ok {
+		return
+	}
+
+	memories, err := p.List(r.Context(), user)
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJson(w, MemoryList{
+		User: user,
+
+		Memories: memories,
+	})
+}
+
+func (h *Handler) handleMemoriesPurge(w http.ResponseWriter, r *http.Request) {
+	p, user, ok := h.memoryStore(w, r)
+
+	if !ok {
+		return
+	}
+
+	if err := p.Purge(r.Context(), user); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleMemoryForget(w http.ResponseWriter, r *http.Request) {
+	p, user, ok := h.memoryStore(w, r)
+
+	if !ok {
+		return
+	}
+
+	err := p.Forget(r.Context(), user, chi.URLParam(r, "id"))
+
+	if errors.Is(err, memory.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// memoryStore resolves the store and user of the request. Authenticated
+// users may manage their own memories; those of other users need a store
+// configured as admin and access to it granted by the policy.
+func (h *Handler) memoryStore(w http.ResponseWriter, r *http.Request) (memory.Provider, string, bool) {
+	id := chi.URLParam(r, "memory")
+	user := chi.URLParam(r, "user")
+
+	p, err := h.Memory(id)
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return nil, "", false
+	}
+
+	caller, ok := memory.User(r.Context())
+
+	if !ok {
+		writeError(w, http.StatusUnauthorized, errors.New("authentication required"))
+		return nil, "", false
+	}
+
+	if caller != user {
+		if !h.MemoryAdmin(id) {
+			writeError(w, http.StatusForbidden, policy.ErrAccessDenied)
+			return nil, "", false
+		}
+
+		if err := h.Policy.Verify(r.Context(), policy.ResourceMemory, id, policy.ActionAccess); err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return nil, "", false
+		}
+	}
+
+	return p, user, true
+}
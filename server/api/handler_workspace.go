@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/adrianliechti/wingman/pkg/workspace"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxWorkspaceUpload limits the size of files uploaded to a workspace
+const maxWorkspaceUpload = 32 << 20
+
+type WorkspaceFileList struct {
+	Session string `json:"session"`
+
+	Files []workspace.File `json:"files"`
+}
+
+func (h *Handler) handleWorkspaceFiles(w http.ResponseWriter, r *http.Request) {
+	p, key, ok := h.workspaceStore(w, r)
+
+	if !ok {
+		return
+	}
+
+	files, err := p.List(r.Context(), key)
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJson(w, WorkspaceFileList{
+		Session: chi.URLParam(r, "session"),
+
+		Files: files,
+	})
+}
+
+func (h *Handler) handleWorkspaceFileGet(w http.ResponseWriter, r *http.Request) {
+	p, key, ok := h.workspaceStore(w, r)
+
+	if !ok {
+		return
+	}
+
+	name := chi.URLParam(r, "*")
+
+	data, err := p.Read(r.Context(), key, name)
+
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
+}
+
+func (h *Handler) handleWorkspaceFilePut(w http.ResponseWriter, r *http.Request) {
+	p, key, ok := h.workspaceStore(w, r)
+
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWorkspaceUpload))
+
+	if err != nil {
+		var maxErr *http.MaxBytesError
+
+		if errors.As(err, &maxErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := p.Write(r.Context(), key, chi.URLParam(r, "*"), data); err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleWorkspaceFileDelete(w http.ResponseWriter, r *http.Request) {
+	p, key, ok := h.workspaceStore(w, r)
+
+	if !ok {
+		return
+	}
+
+	if err := p.Delete(r.Context(), key, chi.URLParam(r, "*")); err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// workspaceStore resolves the store and workspace key of the request. The
+// key is derived from the caller and the session, so callers only ever reach
+// the workspaces of their own sessions.
+func (h *Handler) workspaceStore(w http.ResponseWriter, r *http.Request) (workspace.Provider, string, bool) {
+	p, err := h.Workspace(chi.URLParam(r, "workspace"))
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return nil, "", false
+	}
+
+	session := chi.URLParam(r, "session")
+
+	return p, workspace.Key(r.Context(), session), true
+}
+
+func writeWorkspaceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, workspace.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+
+	case errors.Is(err, workspace.ErrInvalidPath):
+		writeError(w, http.StatusBadRequest, err)
+
+	case errors.Is(err, workspace.ErrQuotaExceeded):
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/adrianliechti/wingman/pkg/policy"
 	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/server/openai/shared"
 )
 
 func (h *Handler) handleGenerateContent(w http.ResponseWriter, r *http.Request) {
@@ -19,6 +20,9 @@ func (h *Handler) handleGenerateContent(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Server-side tools of agents keep their files in the session
+	r = shared.WithSession(w, r)
+
 	acc := provider.CompletionAccumulator{}
 
 	for completion, err := range completer.Complete(r.Context(), messages, options) {
@@ -69,6 +73,9 @@ func (h *Handler) handleStreamGenerateContent(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Server-side tools of agents keep their files in the session
+	r = shared.WithSession(w, r)
+
 	// The genai SDK always uses ?alt=sse for streaming
 	// We support both SSE and JSON array formats for compatibility
 	useSSE := r.URL.Query().Get("alt") == "sse"
@@ -68,6 +68,11 @@ func NewStreamingAccumulator(model string, handler StreamEventHandler) *Streamin
 	}
 }
 
+// ID returns the id of chunks without an upstream one
+func (s *StreamingAccumulator) ID() string {
+	return s.id
+}
+
 // Add processes a completion chunk and emits appropriate events
 func (s *StreamingAccumulator) Add(c provider.Completion) error {
 	s.accumulator.Add(c)
@@ -187,13 +192,11 @@ func (s *StreamingAccumulator) Add(c provider.Completion) error {
 func (s *StreamingAccumulator) Complete(includeUsage bool) error {
 	result := s.accumulator.Result()
 
-	// Truncation and content-filter override any earlier tool_calls reason —
-	// the upstream finish_reason flows through Completion.Status.
-	switch result.Status {
-	case provider.CompletionStatusIncomplete:
-		s.finishReason = FinishReasonLength
-	case provider.CompletionStatusRefused:
-		s.finishReason = FinishReasonContentFilter
+	// Truncation, content-filter and agent limits override any earlier
+	// tool_calls reason — the upstream finish_reason flows through
+	// Completion.Status.
+	if reason := toFinishReason(result, false); reason != FinishReasonStop {
+		s.finishReason = reason
 	}
 
 	// Calls that streamed no argument bytes must still deliver parseable
@@ -250,6 +253,7 @@ func (s *StreamingAccumulator) Complete(includeUsage bool) error {
 				{
 					Delta:        &ChatCompletionMessage{},
 					FinishReason: &s.finishReason,
+					StopDetails:  toStopDetails(result, s.finishReason),
 				},
 			},
 		}
@@ -250,3 +250,53 @@ func oaiToolCalls(content []provider.Content) []ToolCall {
 
 	return result
 }
+
+// toFinishReason maps the status and stop reason of a completion to a finish
+// reason; calls reports whether the message holds tool calls
+func toFinishReason(completion *provider.Completion, calls bool) FinishReason {
+	switch completion.StopReason {
+	case provider.StopReasonAgentLimit:
+		return FinishReasonAgentLimit
+	case provider.StopReasonSchemaMismatch:
+		return FinishReasonSchemaMismatch
+	}
+
+	switch completion.Status {
+	case provider.CompletionStatusIncomplete:
+		return FinishReasonLength
+	case provider.CompletionStatusRefused:
+		return FinishReasonContentFilter
+	}
+
+	if calls {
+		return FinishReasonToolCalls
+	}
+
+	return FinishReasonStop
+}
+
+// toStopDetails returns the stop details of finish reasons OpenAI does not
+// know and of structured output checked against its schema, or nil
+func toStopDetails(completion *provider.Completion, reason FinishReason) *StopDetails {
+	details := completion.StopDetails
+
+	switch {
+	case reason == FinishReasonAgentLimit, reason == FinishReasonSchemaMismatch:
+	case details != nil && details.Type == "schema":
+	default:
+		return nil
+	}
+
+	result := &StopDetails{Type: string(reason)}
+
+	if details != nil {
+		if details.Type != "" {
+			result.Type = details.Type
+		}
+
+		result.Category = details.Category
+		result.Explanation = details.Explanation
+	}
+
+	return result
+}
@@ -1,12 +1,17 @@
 package chat
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/adrianliechti/wingman/pkg/agent"
 	"github.com/adrianliechti/wingman/pkg/policy"
 	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/router/classifier"
+	"github.com/adrianliechti/wingman/server/openai/shared"
 
 	"github.com/google/uuid"
 )
@@ -31,6 +36,9 @@ func (h *Handler) handleChatCompletion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Server-side tools of agents keep their files in the session
+	r = shared.WithSession(w, r)
+
 	messages, err := toMessages(req.Messages)
 
 	if err != nil {
@@ -170,7 +178,12 @@ func toCompleteOptions(req ChatCompletionRequest, tools []provider.Tool) *provid
 func (h *Handler) handleChatCompletionComplete(w http.ResponseWriter, r *http.Request, req ChatCompletionRequest, completer provider.Completer, messages []provider.Message, options *provider.CompleteOptions) {
 	acc := provider.CompletionAccumulator{}
 
-	for completion, err := range completer.Complete(r.Context(), messages, options) {
+	// Without an upstream id the completion gets its own, which feedback on
+	// routed requests refers to
+	generatedID := "chatcmpl-" + uuid.NewString()
+	ctx := classifier.WithResponseID(r.Context(), generatedID)
+
+	for completion, err := range completer.Complete(ctx, messages, options) {
 		if err != nil {
 			writeError(w, http.StatusBadGateway, err)
 			return
@@ -201,7 +214,7 @@ func (h *Handler) handleChatCompletionComplete(w http.ResponseWriter, r *http.Re
 	}
 
 	if result.ID == "" {
-		result.ID = "chatcmpl-" + uuid.NewString()
+		result.ID = generatedID
 	}
 
 	if completion.Message != nil {
@@ -223,22 +236,13 @@ func (h *Handler) handleChatCompletionComplete(w http.ResponseWriter, r *http.Re
 			message.ToolCalls = calls
 		}
 
-		reason := FinishReasonStop
-		switch completion.Status {
-		case provider.CompletionStatusIncomplete:
-			reason = FinishReasonLength
-		case provider.CompletionStatusRefused:
-			reason = FinishReasonContentFilter
-		default:
-			if len(calls) > 0 {
-				reason = FinishReasonToolCalls
-			}
-		}
+		reason := toFinishReason(completion, len(calls) > 0)
 
 		result.Choices = []ChatCompletionChoice{
 			{
 				Message:      message,
 				FinishReason: &reason,
+				StopDetails:  toStopDetails(completion, reason),
 			},
 		}
 	}
@@ -308,7 +312,21 @@ func (h *Handler) handleChatCompletionStream(w http.ResponseWriter, r *http.Requ
 		return nil
 	})
 
-	for c, err := range completer.Complete(r.Context(), messages, options) {
+	// Agents streaming tool progress report it between chunks as custom
+	// events; parallel tool calls report concurrently
+	var progressMu sync.Mutex
+
+	ctx := agent.WithProgressReporter(r.Context(), func(ctx context.Context, p agent.ToolProgress) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+
+		sendHeaders()
+		shared.WriteToolProgressEvent(w, p)
+	})
+
+	ctx = classifier.WithResponseID(ctx, accumulator.ID())
+
+	for c, err := range completer.Complete(ctx, messages, options) {
 		if err != nil {
 			if !headersSent {
 				writeError(w, http.StatusBadGateway, err)
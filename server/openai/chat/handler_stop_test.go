@@ -0,0 +1,141 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/config"
+	"github.com/adrianliechti/wingman/pkg/policy/noop"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+const stopTestModel = "stop-test-model"
+
+// stopCompleter streams a short answer and ends with the given completion.
+type stopCompleter struct {
+	final provider.Completion
+}
+
+func (c stopCompleter) Complete(_ context.Context, _ []provider.Message, _ *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		if !yield(&provider.Completion{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{provider.TextContent("so far")},
+			},
+		}, nil) {
+			return
+		}
+
+		final := c.final
+		yield(&final, nil)
+	}
+}
+
+func postStop(t *testing.T, final provider.Completion, stream bool) string {
+	t.Helper()
+
+	cfg := &config.Config{Policy: noop.New()}
+	cfg.RegisterCompleter(stopTestModel, stopCompleter{final: final})
+
+	mode := "false"
+	if stream {
+		mode = "true"
+	}
+
+	body := []byte(`{"model": "` + stopTestModel + `", "stream": ` + mode + `, "messages": [{"role": "user", "content": "x"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/chat/completions", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	New(cfg).handleChatCompletion(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	return rec.Body.String()
+}
+
+func TestAgentLimitFinishReason(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonAgentLimit,
+		StopDetails: &provider.StopDetails{
+			Type:        "agent_limit",
+			Category:    "tool_loop",
+			Explanation: "the agent repeated a tool call",
+		},
+	}
+
+	want := `"finish_reason":"agent_limit","stop_details":{"type":"agent_limit","category":"tool_loop","explanation":"the agent repeated a tool call"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
+
+func TestMaxTokensFinishReason(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonMaxTokens,
+	}
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, `"finish_reason":"length"`) || strings.Contains(body, "stop_details") {
+			t.Fatalf("stream=%v: expected length without stop details in\n%s", stream, body)
+		}
+	}
+}
+
+func TestSchemaMismatchFinishReason(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonSchemaMismatch,
+		StopDetails: &provider.StopDetails{
+			Type:        "schema",
+			Category:    "invalid",
+			Explanation: "missing property name",
+		},
+	}
+
+	want := `"finish_reason":"schema_mismatch","stop_details":{"type":"schema","category":"invalid","explanation":"missing property name"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
+
+func TestSchemaRepairedStopDetails(t *testing.T) {
+	final := provider.Completion{
+		StopReason: provider.StopReasonEndTurn,
+		StopDetails: &provider.StopDetails{
+			Type:     "schema",
+			Category: "repaired",
+		},
+	}
+
+	want := `"finish_reason":"stop","stop_details":{"type":"schema","category":"repaired"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
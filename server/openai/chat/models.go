@@ -32,6 +32,13 @@ var (
 
 	FinishReasonToolCalls     FinishReason = "tool_calls"
 	FinishReasonContentFilter FinishReason = "content_filter"
+
+	// FinishReasonAgentLimit marks an agent run ended by one of its limits
+	FinishReasonAgentLimit FinishReason = "agent_limit"
+
+	// FinishReasonSchemaMismatch marks structured output that did not match
+	// the requested schema
+	FinishReasonSchemaMismatch FinishReason = "schema_mismatch"
 )
 
 // https://platform.openai.com/docs/api-reference/chat/create
@@ -138,6 +145,15 @@ type ChatCompletionChoice struct {
 	Message *ChatCompletionMessage `json:"message,omitempty"`
 
 	FinishReason *FinishReason `json:"finish_reason"`
+	StopDetails  *StopDetails  `json:"stop_details,omitempty"`
+}
+
+// StopDetails explains a finish reason beyond the OpenAI ones, such as the
+// limit that ended an agent run, or how output matched its schema
+type StopDetails struct {
+	Type        string `json:"type"`               // "agent_limit", "schema"
+	Category    string `json:"category,omitempty"` // e.g. "max_turns", "repaired"
+	Explanation string `json:"explanation,omitempty"`
 }
 
 // https://platform.openai.com/docs/api-reference/chat/object
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/adrianliechti/wingman/pkg/agent"
 	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/google/uuid"
 )
@@ -55,6 +56,11 @@ const (
 	// Compaction events
 	StreamEventCompactionItemAdded StreamEventType = "compaction_item.added"
 	StreamEventCompactionItemDone  StreamEventType = "compaction_item.done"
+
+	// Hosted tool call events, for tool calls agents run on their own
+	StreamEventHostedToolCallAdded    StreamEventType = "hosted_tool_call.added"
+	StreamEventHostedToolCallProgress StreamEventType = "hosted_tool_call.progress"
+	StreamEventHostedToolCallDone     StreamEventType = "hosted_tool_call.done"
 )
 
 // StreamEvent represents a streaming event with its data
@@ -75,6 +81,7 @@ type StreamEvent struct {
 	ToolCallName      string
 	ToolCallNamespace string
 	ToolCallExecution string
+	ToolCallKind      provider.ToolKind
 	Arguments         string
 	OutputIndex       int
 
@@ -95,6 +102,9 @@ type StreamEvent struct {
 	CompactionContent          string
 	CompactionEncryptedContent string
 
+	// For hosted tool call events
+	ToolProgress *agent.ToolProgress
+
 	// For error events
 	Error error
 
@@ -137,6 +147,9 @@ type StreamingAccumulator struct {
 	status provider.CompletionStatus
 	usage  *provider.Usage
 
+	stopReason  provider.StopReason
+	stopDetails *provider.StopDetails
+
 	// Track state for event emission
 	started            bool
 	hasOutputItem      bool // True if we emitted output_item.added for message
@@ -177,6 +190,9 @@ type StreamingAccumulator struct {
 
 	compactions  []provider.Compaction
 	contentOrder []streamContentRef
+
+	// hostedCalls maps the ID of a hosted tool call to its output index
+	hostedCalls map[string]int
 }
 
 type streamContentKind int
@@ -197,6 +213,7 @@ func NewStreamingAccumulator(handler StreamEventHandler) *StreamingAccumulator {
 	return &StreamingAccumulator{
 		handler:      handler,
 		toolCallByID: make(map[string]int),
+		hostedCalls:  make(map[string]int),
 	}
 }
 
@@ -350,12 +367,17 @@ func (s *StreamingAccumulator) ensureToolCallStarted(callID string, toolCall pro
 		tc.Execution = toolCall.Execution
 	}
 
+	if toolCall.Kind != "" {
+		tc.Kind = toolCall.Kind
+	}
+
 	return s.emitEvent(StreamEvent{
 		Type:              StreamEventFunctionCallAdded,
 		ToolCallID:        callID,
 		ToolCallName:      tc.Name,
 		ToolCallNamespace: tc.Namespace,
 		ToolCallExecution: tc.Execution,
+		ToolCallKind:      tc.Kind,
 		OutputIndex:       outputIndex,
 	})
 }
@@ -389,6 +411,7 @@ func (s *StreamingAccumulator) closeToolCall(callID string) error {
 			ToolCallID:        tc.ID,
 			ToolCallName:      tc.Name,
 			ToolCallNamespace: tc.Namespace,
+			ToolCallKind:      tc.Kind,
 			Delta:             tc.Arguments,
 			OutputIndex:       tc.OutputIndex,
 		}); err != nil {
@@ -403,6 +426,7 @@ func (s *StreamingAccumulator) closeToolCall(callID string) error {
 			ToolCallName:      tc.Name,
 			ToolCallNamespace: tc.Namespace,
 			ToolCallExecution: tc.Execution,
+			ToolCallKind:      tc.Kind,
 			Arguments:         tc.Arguments,
 			OutputIndex:       tc.OutputIndex,
 		}); err != nil {
@@ -416,6 +440,7 @@ func (s *StreamingAccumulator) closeToolCall(callID string) error {
 		ToolCallName:      tc.Name,
 		ToolCallNamespace: tc.Namespace,
 		ToolCallExecution: tc.Execution,
+		ToolCallKind:      tc.Kind,
 		Arguments:         tc.Arguments,
 		OutputIndex:       tc.OutputIndex,
 		Incomplete:        incomplete,
@@ -720,6 +745,12 @@ func (s *StreamingAccumulator) Add(c provider.Completion) error {
 	if c.Status != "" {
 		s.status = c.Status
 	}
+	if c.StopReason != "" {
+		s.stopReason = c.StopReason
+	}
+	if c.StopDetails != nil {
+		s.stopDetails = c.StopDetails
+	}
 	if c.Usage != nil {
 		mergeUsage(&s.usage, c.Usage)
 	}
@@ -982,6 +1013,7 @@ func (s *StreamingAccumulator) Add(c provider.Completion) error {
 					ToolCallID:        currentID,
 					ToolCallName:      entry.Name,
 					ToolCallNamespace: entry.Namespace,
+					ToolCallKind:      entry.Kind,
 					Delta:             tc.Arguments,
 					OutputIndex:       outputIndex,
 				}); err != nil {
@@ -995,6 +1027,50 @@ func (s *StreamingAccumulator) Add(c provider.Completion) error {
 	return nil
 }
 
+// HostedToolCall reports the progress of a tool call the server runs on its
+// own, such as those of agents. The first report of a call adds its output
+// item, later in-progress reports with a message are passed on as progress
+// and a completed or failed report closes it.
+func (s *StreamingAccumulator) HostedToolCall(p agent.ToolProgress) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	index, ok := s.hostedCalls[p.ID]
+
+	if !ok {
+		index = s.reserveOutputIndex()
+		s.hostedCalls[p.ID] = index
+
+		if err := s.emitEvent(StreamEvent{
+			Type:         StreamEventHostedToolCallAdded,
+			OutputIndex:  index,
+			ToolProgress: &p,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if p.Status == agent.ToolStatusInProgress {
+		// Later reports carry the progress messages of a running call
+		if ok && p.Output != "" {
+			return s.emitEvent(StreamEvent{
+				Type:         StreamEventHostedToolCallProgress,
+				OutputIndex:  index,
+				ToolProgress: &p,
+			})
+		}
+
+		return nil
+	}
+
+	return s.emitEvent(StreamEvent{
+		Type:         StreamEventHostedToolCallDone,
+		OutputIndex:  index,
+		ToolProgress: &p,
+	})
+}
+
 // Complete signals that streaming is done and emits final events.
 func (s *StreamingAccumulator) Complete() error {
 	if err := s.start(); err != nil {
@@ -1176,6 +1252,9 @@ func (s *StreamingAccumulator) Result() *provider.Completion {
 		Status: s.status,
 		Usage:  s.usage,
 
+		StopReason:  s.stopReason,
+		StopDetails: s.stopDetails,
+
 		Message: &provider.Message{
 			Role:    provider.MessageRoleAssistant,
 			Content: content,
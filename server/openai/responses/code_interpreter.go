@@ -0,0 +1,136 @@
+package responses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/agent/react"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/provider/tools/shell"
+	"github.com/adrianliechti/wingman/pkg/tool"
+	"github.com/adrianliechti/wingman/server/openai/shared"
+
+	"github.com/google/uuid"
+)
+
+// codeInterpreter runs the code_interpreter tool of a request in the
+// server's sandbox. The container of the request names the sandbox session,
+// so later requests naming the same container share its workspace.
+type codeInterpreter struct {
+	tool tool.Provider
+	name string
+
+	container string
+
+	// outputs includes the command output in the call items
+	outputs bool
+}
+
+// codeInterpreter returns the interpreter for a request with a
+// code_interpreter tool, or nil
+func (h *Handler) codeInterpreter(ctx context.Context, req ResponsesRequest) (*codeInterpreter, error) {
+	i := slices.IndexFunc(req.Tools, func(t Tool) bool { return t.Type == ToolTypeCodeInterpreter })
+
+	if i < 0 {
+		return nil, nil
+	}
+
+	if h.CodeInterpreter == nil {
+		return nil, &shared.Error{
+			Param:   fmt.Sprintf("tools[%d].type", i),
+			Message: "The code_interpreter tool is not enabled on this server.",
+		}
+	}
+
+	tools, err := h.CodeInterpreter.Tools(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tools) != 1 {
+		return nil, errors.New("code interpreter must provide a single tool")
+	}
+
+	container, _ := req.Tools[i].Container.(string)
+
+	if container == "" {
+		container = "cntr_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	}
+
+	return &codeInterpreter{
+		tool: h.CodeInterpreter,
+		name: tools[0].Name,
+
+		container: container,
+
+		outputs: slices.Contains(req.Include, "code_interpreter_call.outputs"),
+	}, nil
+}
+
+// wrap runs the model in a loop that executes its sandbox calls and reports
+// them as hosted tool calls, in the session of the container
+func (ci *codeInterpreter) wrap(ctx context.Context, model string, completer provider.Completer) (context.Context, provider.Completer, error) {
+	loop, err := react.New(model,
+		react.WithCompleter(completer),
+		react.WithTools(ci.tool),
+		react.WithToolProgress(),
+	)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return agent.WithSession(ctx, ci.container), loop, nil
+}
+
+// handles reports whether the progress is of a sandbox call of the model
+// itself, rather than of an agent it talks to
+func (ci *codeInterpreter) handles(p agent.ToolProgress) bool {
+	return ci != nil && p.Depth == 0 && p.Name == ci.name
+}
+
+func (ci *codeInterpreter) item(p agent.ToolProgress) *CodeInterpreterCallItem {
+	code := strings.Join(shell.Commands(p.Arguments), "\n")
+
+	item := &CodeInterpreterCallItem{
+		ID:          "ci_" + p.ID,
+		Type:        string(ResponseOutputTypeCodeInterpreterCall),
+		Status:      string(p.Status),
+		ContainerID: ci.container,
+		Code:        &code,
+	}
+
+	if !ci.outputs {
+		return item
+	}
+
+	switch p.Status {
+	case agent.ToolStatusCompleted:
+		item.Outputs = []CodeInterpreterOutput{{Type: "logs", Logs: p.Output}}
+
+	case agent.ToolStatusFailed:
+		item.Outputs = []CodeInterpreterOutput{{Type: "logs", Logs: p.Error}}
+	}
+
+	return item
+}
+
+// hostedOutput renders a finished tool call the server ran on its own
+func (ci *codeInterpreter) hostedOutput(p agent.ToolProgress) ResponseOutput {
+	if ci.handles(p) {
+		return ResponseOutput{
+			Type:                    ResponseOutputTypeCodeInterpreterCall,
+			CodeInterpreterCallItem: ci.item(p),
+		}
+	}
+
+	return ResponseOutput{
+		Type:        ResponseOutputTypeMCPCall,
+		MCPCallItem: toolProgressToMCPCall(p),
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/adrianliechti/wingman/pkg/agent"
 	"github.com/adrianliechti/wingman/pkg/provider"
 	"github.com/adrianliechti/wingman/pkg/provider/tools/computeruse"
 	"github.com/adrianliechti/wingman/pkg/provider/tools/shell"
@@ -452,6 +453,43 @@ func toMessages(items []InputItem, instructions string) ([]provider.Message, err
 				Payload:   []byte(output.Tools),
 			}))
 
+		case InputItemTypeMCPApprovalRequest:
+			if item.InputMCPApprovalRequest == nil {
+				continue
+			}
+
+			flushResults()
+
+			request := item.InputMCPApprovalRequest
+
+			pendingCalls = append(pendingCalls, provider.ToolCallContent(provider.ToolCall{
+				ID:        request.ID,
+				Kind:      provider.ToolKindApproval,
+				Name:      request.Name,
+				Arguments: request.Arguments,
+			}))
+
+		case InputItemTypeMCPApprovalResponse:
+			if item.InputMCPApprovalResponse == nil {
+				continue
+			}
+
+			flushCalls()
+
+			response := item.InputMCPApprovalResponse
+
+			approval := provider.ToolResult{
+				ID:      response.ApprovalRequestID,
+				Kind:    provider.ToolKindApproval,
+				IsError: !response.Approve,
+			}
+
+			if response.Reason != "" {
+				approval.Parts = []provider.Part{{Text: response.Reason}}
+			}
+
+			pendingResults = append(pendingResults, provider.ToolResultContent(approval))
+
 		case InputItemTypeCompactionTrigger:
 			// positional marker, surfaced via CompleteOptions.CompactionOptions
 		}
@@ -657,6 +695,11 @@ func toTools(tools []Tool) ([]provider.Tool, error) {
 				Tools:       children,
 			})
 
+		case ToolTypeCodeInterpreter:
+			// code_interpreter runs in the server's sandbox; the handler
+			// adds its tool when enabled
+			continue
+
 		case ToolTypeWebSearch:
 			// web_search is hosted by OpenAI and is unavailable on BYOK
 			// backends such as Azure. Codex advertises it even when using a
@@ -905,6 +948,54 @@ func toolCallToShellCall(call provider.ToolCall, status string, itemType Respons
 	return item
 }
 
+// defaultServerLabel labels tools the server runs on its own behalf
+const defaultServerLabel = "wingman"
+
+// toolCallToApprovalRequest renders a call held back for approval. The call
+// id doubles as the item id, so the client's approval_request_id maps back
+// to the call.
+func toolCallToApprovalRequest(call provider.ToolCall) *MCPApprovalRequestItem {
+	label := call.Namespace
+
+	if label == "" {
+		label = defaultServerLabel
+	}
+
+	return &MCPApprovalRequestItem{
+		ID:          call.ID,
+		Type:        "mcp_approval_request",
+		ServerLabel: label,
+		Name:        call.Name,
+		Arguments:   call.Arguments,
+	}
+}
+
+// toolProgressToMCPCall renders a tool call an agent ran on its own
+func toolProgressToMCPCall(p agent.ToolProgress) *MCPCallItem {
+	item := &MCPCallItem{
+		ID:          p.ID,
+		Type:        "mcp_call",
+		ServerLabel: defaultServerLabel,
+		Name:        p.Name,
+		Arguments:   p.Arguments,
+		Status:      string(p.Status),
+	}
+
+	if item.Arguments == "" {
+		item.Arguments = "{}"
+	}
+
+	switch p.Status {
+	case agent.ToolStatusCompleted:
+		item.Output = &p.Output
+
+	case agent.ToolStatusFailed:
+		item.Error = &p.Error
+	}
+
+	return item
+}
+
 func toolCallToToolSearchCall(call provider.ToolCall, status string) *ToolSearchCallItem {
 	item := &ToolSearchCallItem{
 		ID:        "tsc_" + call.ID,
@@ -15,11 +15,15 @@ import (
 
 type Handler struct {
 	*config.Config
+
+	sessions *sessionChain
 }
 
 func New(cfg *config.Config) *Handler {
 	h := &Handler{
 		Config: cfg,
+
+		sessions: newSessionChain(),
 	}
 
 	return h
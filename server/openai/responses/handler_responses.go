@@ -1,15 +1,19 @@
 package responses
 
 import (
+	"context"
 	"encoding/json"
 	"maps"
 	"net/http"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/adrianliechti/wingman/pkg/agent"
 	"github.com/adrianliechti/wingman/pkg/policy"
 	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/router/classifier"
 	"github.com/adrianliechti/wingman/server/openai/shared"
 
 	"github.com/google/uuid"
@@ -35,6 +39,15 @@ func (h *Handler) handleResponses(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The conversation, or the response the request continues, names the
+	// session of server-side tools, such as the workspace of a text editor,
+	// unless the client set one
+	if id := h.session(req); id != "" && agent.Session(r.Context()) == "" {
+		r = r.WithContext(agent.WithSession(r.Context(), id))
+	}
+
+	r = shared.WithSession(w, r)
+
 	messages, err := toMessages(req.Input.Items, req.Instructions)
 
 	if err != nil {
@@ -50,6 +63,25 @@ func (h *Handler) handleResponses(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	interpreter, err := h.codeInterpreter(r.Context(), req)
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if interpreter != nil {
+		ctx, loop, err := interpreter.wrap(r.Context(), req.Model, completer)
+
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		r = r.WithContext(ctx)
+		completer = loop
+	}
+
 	options := &provider.CompleteOptions{
 		Tools:       tools,
 		ToolOptions: toToolOptions(req.ToolChoice),
@@ -184,9 +216,9 @@ func (h *Handler) handleResponses(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.Stream {
-		h.handleResponsesStream(w, r, req, completer, messages, options)
+		h.handleResponsesStream(w, r, req, completer, messages, options, interpreter)
 	} else {
-		h.handleResponsesComplete(w, r, req, completer, messages, options)
+		h.handleResponsesComplete(w, r, req, completer, messages, options, interpreter)
 	}
 }
 
@@ -201,6 +233,43 @@ func responseStatus(status provider.CompletionStatus) string {
 	}
 }
 
+// incompleteDetails returns why an incomplete completion stopped short, or
+// nil to report the default max_output_tokens
+func incompleteDetails(completion *provider.Completion) *IncompleteDetails {
+	if completion == nil {
+		return nil
+	}
+
+	switch completion.StopReason {
+	case provider.StopReasonAgentLimit, provider.StopReasonSchemaMismatch:
+	default:
+		return nil
+	}
+
+	result := &IncompleteDetails{Reason: string(completion.StopReason)}
+
+	if completion.StopDetails != nil {
+		result.Category = completion.StopDetails.Category
+		result.Explanation = completion.StopDetails.Explanation
+	}
+
+	return result
+}
+
+// stopDetails returns how the structured output of a completed completion
+// matched its schema, or nil
+func stopDetails(completion *provider.Completion) *StopDetails {
+	if completion == nil || completion.StopDetails == nil || completion.StopDetails.Type != "schema" {
+		return nil
+	}
+
+	return &StopDetails{
+		Type:        completion.StopDetails.Type,
+		Category:    completion.StopDetails.Category,
+		Explanation: completion.StopDetails.Explanation,
+	}
+}
+
 func itemStatus(incomplete bool) string {
 	if incomplete {
 		return "incomplete"
@@ -395,6 +464,16 @@ func (o responseOutputOptions) kindOf(name string) provider.ToolKind {
 	return outputKind(name, o.Tools)
 }
 
+// callKind is the output kind of a call. Approval requests keep their kind
+// regardless of the declared tools.
+func (o responseOutputOptions) callKind(kind provider.ToolKind, name string) provider.ToolKind {
+	if kind == provider.ToolKindApproval {
+		return kind
+	}
+
+	return o.kindOf(name)
+}
+
 func functionCallItem(call provider.ToolCall, status string) *FunctionCallOutputItem {
 	return &FunctionCallOutputItem{
 		ID:        "fc_" + call.ID,
@@ -507,7 +586,13 @@ func responseOutputs(message *provider.Message, messageID, status string, opts r
 		if content.ToolCall != nil {
 			call := *content.ToolCall
 
-			switch opts.kindOf(call.Name) {
+			switch opts.callKind(call.Kind, call.Name) {
+			case provider.ToolKindApproval:
+				output = append(output, ResponseOutput{
+					Type:                   ResponseOutputTypeMCPApprovalRequest,
+					MCPApprovalRequestItem: toolCallToApprovalRequest(call),
+				})
+
 			case provider.ToolKindCustom:
 				output = append(output, ResponseOutput{
 					Type:               ResponseOutputTypeCustomToolCall,
@@ -573,6 +658,38 @@ func responseOutputs(message *provider.Message, messageID, status string, opts r
 	return output
 }
 
+// hostedOutput is a tool call an agent ran on its own, at the output index
+// reserved for it
+type hostedOutput struct {
+	index  int
+	output ResponseOutput
+}
+
+// withHosted places the tool calls agents ran on their own at their output
+// index and fills the remaining slots with the outputs in order
+func withHosted(hosted []hostedOutput, outputs []ResponseOutput) []ResponseOutput {
+	if len(hosted) == 0 {
+		return outputs
+	}
+
+	hosted = slices.SortedFunc(slices.Values(hosted), func(a, b hostedOutput) int {
+		return a.index - b.index
+	})
+
+	result := make([]ResponseOutput, 0, len(hosted)+len(outputs))
+
+	for _, h := range hosted {
+		for len(result) < h.index && len(outputs) > 0 {
+			result = append(result, outputs[0])
+			outputs = outputs[1:]
+		}
+
+		result = append(result, h.output)
+	}
+
+	return append(result, outputs...)
+}
+
 // setOutputStatus overrides the status of whichever item variant is set.
 func setOutputStatus(o *ResponseOutput, status string) {
 	switch {
@@ -595,7 +712,21 @@ func setOutputStatus(o *ResponseOutput, status string) {
 	}
 }
 
-func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request, req ResponsesRequest, completer provider.Completer, messages []provider.Message, options *provider.CompleteOptions) {
+// session returns the session of the conversation or of the previous
+// response of the request, if known
+func (h *Handler) session(req ResponsesRequest) string {
+	if id := req.ConversationID(); id != "" {
+		return id
+	}
+
+	if session, ok := h.sessions.get(req.PreviousResponseID); ok {
+		return session
+	}
+
+	return ""
+}
+
+func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request, req ResponsesRequest, completer provider.Completer, messages []provider.Message, options *provider.CompleteOptions, interpreter *codeInterpreter) {
 	headersSent := false
 
 	sendHeaders := func() {
@@ -640,6 +771,10 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 		return resp
 	}
 
+	// hosted holds the finished tool calls agents ran on their own; they
+	// keep their streamed output index in the final response
+	var hosted []hostedOutput
+
 	// Create streaming accumulator with event handler
 	accumulator := NewStreamingAccumulator(func(event StreamEvent) error {
 		switch event.Type {
@@ -771,7 +906,19 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 			})
 
 		case StreamEventFunctionCallAdded:
-			switch outputOpts.kindOf(event.ToolCallName) {
+			switch outputOpts.callKind(event.ToolCallKind, event.ToolCallName) {
+			case provider.ToolKindApproval:
+				return writeEvent(w, "response.output_item.added", MCPApprovalRequestOutputItemAddedEvent{
+					Type:           "response.output_item.added",
+					SequenceNumber: nextSeq(),
+					OutputIndex:    event.OutputIndex,
+					Item: toolCallToApprovalRequest(provider.ToolCall{
+						ID:        event.ToolCallID,
+						Name:      event.ToolCallName,
+						Namespace: event.ToolCallNamespace,
+					}),
+				})
+
 			case provider.ToolKindCustom:
 				return writeEvent(w, "response.output_item.added", CustomToolCallOutputItemAddedEvent{
 					Type:           "response.output_item.added",
@@ -874,7 +1021,7 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 			}
 
 		case StreamEventFunctionCallArgumentsDelta:
-			switch outputOpts.kindOf(event.ToolCallName) {
+			switch outputOpts.callKind(event.ToolCallKind, event.ToolCallName) {
 			case provider.ToolKindCustom:
 				if isApplyPatchToolCall(provider.ToolCall{Name: event.ToolCallName, Namespace: event.ToolCallNamespace}) {
 					return nil
@@ -888,7 +1035,7 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 					Delta:          event.Delta,
 				})
 
-			case provider.ToolKindTextEditor, provider.ToolKindComputer, provider.ToolKindShell, provider.ToolKindToolSearch:
+			case provider.ToolKindTextEditor, provider.ToolKindComputer, provider.ToolKindShell, provider.ToolKindToolSearch, provider.ToolKindApproval:
 				return nil
 
 			default:
@@ -902,7 +1049,7 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 			}
 
 		case StreamEventFunctionCallArgumentsDone:
-			switch outputOpts.kindOf(event.ToolCallName) {
+			switch outputOpts.callKind(event.ToolCallKind, event.ToolCallName) {
 			case provider.ToolKindCustom:
 				call := provider.ToolCall{
 					ID:        event.ToolCallID,
@@ -920,7 +1067,7 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 					Input:          input,
 				})
 
-			case provider.ToolKindTextEditor, provider.ToolKindComputer, provider.ToolKindShell, provider.ToolKindToolSearch:
+			case provider.ToolKindTextEditor, provider.ToolKindComputer, provider.ToolKindShell, provider.ToolKindToolSearch, provider.ToolKindApproval:
 				return nil
 
 			default:
@@ -945,7 +1092,15 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 
 			status := itemStatus(event.Incomplete)
 
-			switch outputOpts.kindOf(event.ToolCallName) {
+			switch outputOpts.callKind(event.ToolCallKind, event.ToolCallName) {
+			case provider.ToolKindApproval:
+				return writeEvent(w, "response.output_item.done", MCPApprovalRequestOutputItemDoneEvent{
+					Type:           "response.output_item.done",
+					SequenceNumber: nextSeq(),
+					OutputIndex:    event.OutputIndex,
+					Item:           toolCallToApprovalRequest(call),
+				})
+
 			case provider.ToolKindCustom:
 				return writeEvent(w, "response.output_item.done", CustomToolCallOutputItemDoneEvent{
 					Type:           "response.output_item.done",
@@ -1226,6 +1381,133 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 				},
 			})
 
+		case StreamEventHostedToolCallAdded:
+			if interpreter.handles(*event.ToolProgress) {
+				item := interpreter.item(*event.ToolProgress)
+				item.Status = "in_progress"
+				item.Outputs = nil
+
+				if err := writeEvent(w, "response.output_item.added", CodeInterpreterCallOutputItemAddedEvent{
+					Type:           "response.output_item.added",
+					SequenceNumber: nextSeq(),
+					OutputIndex:    event.OutputIndex,
+					Item:           item,
+				}); err != nil {
+					return err
+				}
+
+				if err := writeEvent(w, "response.code_interpreter_call.in_progress", CodeInterpreterCallStatusEvent{
+					Type:           "response.code_interpreter_call.in_progress",
+					SequenceNumber: nextSeq(),
+					OutputIndex:    event.OutputIndex,
+					ItemID:         item.ID,
+				}); err != nil {
+					return err
+				}
+
+				if err := writeEvent(w, "response.code_interpreter_call_code.done", CodeInterpreterCallCodeDoneEvent{
+					Type:           "response.code_interpreter_call_code.done",
+					SequenceNumber: nextSeq(),
+					OutputIndex:    event.OutputIndex,
+					ItemID:         item.ID,
+					Code:           *item.Code,
+				}); err != nil {
+					return err
+				}
+
+				return writeEvent(w, "response.code_interpreter_call.interpreting", CodeInterpreterCallStatusEvent{
+					Type:           "response.code_interpreter_call.interpreting",
+					SequenceNumber: nextSeq(),
+					OutputIndex:    event.OutputIndex,
+					ItemID:         item.ID,
+				})
+			}
+
+			item := toolProgressToMCPCall(*event.ToolProgress)
+			item.Status = "in_progress"
+			item.Output, item.Error = nil, nil
+
+			if err := writeEvent(w, "response.output_item.added", MCPCallOutputItemAddedEvent{
+				Type:           "response.output_item.added",
+				SequenceNumber: nextSeq(),
+				OutputIndex:    event.OutputIndex,
+				Item:           item,
+			}); err != nil {
+				return err
+			}
+
+			return writeEvent(w, "response.mcp_call.in_progress", MCPCallStatusEvent{
+				Type:           "response.mcp_call.in_progress",
+				SequenceNumber: nextSeq(),
+				OutputIndex:    event.OutputIndex,
+				ItemID:         item.ID,
+			})
+
+		case StreamEventHostedToolCallProgress:
+			// The Responses API has no event for progress messages of a
+			// call, so they use the custom event of the other APIs
+			return shared.WriteToolProgressEvent(w, *event.ToolProgress)
+
+		case StreamEventHostedToolCallDone:
+			if interpreter.handles(*event.ToolProgress) {
+				output := interpreter.hostedOutput(*event.ToolProgress)
+				item := output.CodeInterpreterCallItem
+
+				hosted = append(hosted, hostedOutput{event.OutputIndex, output})
+
+				// A failed run has no status event of its own
+				if item.Status == string(agent.ToolStatusCompleted) {
+					if err := writeEvent(w, "response.code_interpreter_call.completed", CodeInterpreterCallStatusEvent{
+						Type:           "response.code_interpreter_call.completed",
+						SequenceNumber: nextSeq(),
+						OutputIndex:    event.OutputIndex,
+						ItemID:         item.ID,
+					}); err != nil {
+						return err
+					}
+				}
+
+				return writeEvent(w, "response.output_item.done", CodeInterpreterCallOutputItemDoneEvent{
+					Type:           "response.output_item.done",
+					SequenceNumber: nextSeq(),
+					OutputIndex:    event.OutputIndex,
+					Item:           item,
+				})
+			}
+
+			item := toolProgressToMCPCall(*event.ToolProgress)
+
+			hosted = append(hosted, hostedOutput{event.OutputIndex, ResponseOutput{
+				Type:        ResponseOutputTypeMCPCall,
+				MCPCallItem: item,
+			}})
+
+			if err := writeEvent(w, "response.mcp_call_arguments.done", MCPCallArgumentsDoneEvent{
+				Type:           "response.mcp_call_arguments.done",
+				SequenceNumber: nextSeq(),
+				OutputIndex:    event.OutputIndex,
+				ItemID:         item.ID,
+				Arguments:      item.Arguments,
+			}); err != nil {
+				return err
+			}
+
+			if err := writeEvent(w, "response.mcp_call."+item.Status, MCPCallStatusEvent{
+				Type:           "response.mcp_call." + item.Status,
+				SequenceNumber: nextSeq(),
+				OutputIndex:    event.OutputIndex,
+				ItemID:         item.ID,
+			}); err != nil {
+				return err
+			}
+
+			return writeEvent(w, "response.output_item.done", MCPCallOutputItemDoneEvent{
+				Type:           "response.output_item.done",
+				SequenceNumber: nextSeq(),
+				OutputIndex:    event.OutputIndex,
+				Item:           item,
+			})
+
 		case StreamEventResponseCompleted:
 			now := time.Now().Unix()
 			response := &Response{
@@ -1234,8 +1516,10 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 				CompletedAt: &now,
 				Status:      "completed",
 				Model:       responseModel(event.Completion, req.Model),
-				Output:      responseOutputs(event.Completion.Message, messageID, "completed", outputOpts),
+				Output:      withHosted(hosted, responseOutputs(event.Completion.Message, messageID, "completed", outputOpts)),
 				Usage:       responseUsage(event.Completion.Usage),
+
+				StopDetails: stopDetails(event.Completion),
 			}
 			responseDefaults(response, req)
 
@@ -1251,8 +1535,10 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 				CreatedAt: createdAt,
 				Status:    "incomplete",
 				Model:     responseModel(event.Completion, req.Model),
-				Output:    responseOutputs(event.Completion.Message, messageID, "incomplete", outputOpts),
+				Output:    withHosted(hosted, responseOutputs(event.Completion.Message, messageID, "incomplete", outputOpts)),
 				Usage:     responseUsage(event.Completion.Usage),
+
+				IncompleteDetails: incompleteDetails(event.Completion),
 			}
 			responseDefaults(response, req)
 
@@ -1303,8 +1589,25 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 
 	failed := false
 
+	// Agents streaming tool progress report it between completion chunks;
+	// parallel tool calls report concurrently
+	var progressMu sync.Mutex
+
+	ctx := agent.WithProgressReporter(r.Context(), func(ctx context.Context, p agent.ToolProgress) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+
+		sendHeaders()
+		accumulator.HostedToolCall(p)
+	})
+
+	// Feedback on routed requests refers to the id the client receives
+	ctx = classifier.WithResponseID(ctx, responseID)
+
+	h.sessions.put(responseID, agent.Session(ctx))
+
 	// Iterate over completions from the provider
-	for completion, err := range completer.Complete(r.Context(), messages, options) {
+	for completion, err := range completer.Complete(ctx, messages, options) {
 		if err != nil {
 			if !headersSent {
 				writeError(w, http.StatusBadGateway, err)
@@ -1337,10 +1640,30 @@ func (h *Handler) handleResponsesStream(w http.ResponseWriter, r *http.Request,
 	http.NewResponseController(w).Flush()
 }
 
-func (h *Handler) handleResponsesComplete(w http.ResponseWriter, r *http.Request, req ResponsesRequest, completer provider.Completer, messages []provider.Message, options *provider.CompleteOptions) {
+func (h *Handler) handleResponsesComplete(w http.ResponseWriter, r *http.Request, req ResponsesRequest, completer provider.Completer, messages []provider.Message, options *provider.CompleteOptions, interpreter *codeInterpreter) {
 	acc := provider.CompletionAccumulator{}
 
-	for c, err := range completer.Complete(r.Context(), messages, options) {
+	var progressMu sync.Mutex
+	var hosted []hostedOutput
+
+	ctx := agent.WithProgressReporter(r.Context(), func(ctx context.Context, p agent.ToolProgress) {
+		if p.Status == agent.ToolStatusInProgress {
+			return
+		}
+
+		progressMu.Lock()
+		defer progressMu.Unlock()
+
+		// Without streamed indices, the calls lead the output
+		hosted = append(hosted, hostedOutput{len(hosted), interpreter.hostedOutput(p)})
+	})
+
+	// Without an upstream id the response gets its own, which feedback on
+	// routed requests refers to
+	generatedID := "resp_" + uuid.NewString()
+	ctx = classifier.WithResponseID(ctx, generatedID)
+
+	for c, err := range completer.Complete(ctx, messages, options) {
 		if err != nil {
 			writeError(w, http.StatusBadGateway, err)
 			return
@@ -1354,9 +1677,11 @@ func (h *Handler) handleResponsesComplete(w http.ResponseWriter, r *http.Request
 	responseID := completion.ID
 
 	if responseID == "" {
-		responseID = "resp_" + uuid.NewString()
+		responseID = generatedID
 	}
 
+	h.sessions.put(responseID, agent.Session(ctx))
+
 	now := time.Now().Unix()
 
 	result := Response{
@@ -1364,16 +1689,21 @@ func (h *Handler) handleResponsesComplete(w http.ResponseWriter, r *http.Request
 		CreatedAt: now,
 		Status:    responseStatus(completion.Status),
 		Model:     responseModel(completion, req.Model),
-		Output: responseOutputs(completion.Message, "msg_"+uuid.NewString(), responseStatus(completion.Status), responseOutputOptions{
+		Output: withHosted(hosted, responseOutputs(completion.Message, "msg_"+uuid.NewString(), responseStatus(completion.Status), responseOutputOptions{
 			IncludeSummary:   options.ReasoningOptions != nil && options.ReasoningOptions.IncludeSummary,
 			IncludeReasoning: reasoningRequested(req),
 			Tools:            req.Tools,
-		}),
+		})),
 		Usage: responseUsage(completion.Usage),
 	}
 
 	if result.Status == "completed" {
 		result.CompletedAt = &now
+		result.StopDetails = stopDetails(completion)
+	}
+
+	if result.Status == "incomplete" {
+		result.IncompleteDetails = incompleteDetails(completion)
 	}
 
 	responseDefaults(&result, req)
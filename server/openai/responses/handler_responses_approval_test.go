@@ -0,0 +1,108 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/config"
+	"github.com/adrianliechti/wingman/pkg/policy/noop"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// TestApprovalRequestRoundTrip verifies that a call held back for approval
+// surfaces as an mcp_approval_request item, and that the client's echoed
+// request and mcp_approval_response reach the provider as approval kinds.
+func TestApprovalRequestRoundTrip(t *testing.T) {
+	const modelID = "approval-model"
+
+	completer := &toolSearchCompleter{
+		t: t,
+		reply: provider.ToolCall{
+			ID:        "call_ap_2",
+			Kind:      provider.ToolKindApproval,
+			Name:      "create_issue",
+			Arguments: `{"title":"Second"}`,
+		},
+	}
+
+	cfg := &config.Config{Policy: noop.New()}
+	cfg.RegisterCompleter(modelID, completer)
+
+	body := func(stream bool) []byte {
+		return []byte(`{
+			"model": "` + modelID + `",
+			"stream": ` + map[bool]string{true: "true", false: "false"}[stream] + `,
+			"input": [
+				{"type": "message", "role": "user", "content": "file two bugs"},
+				{"type": "mcp_approval_request", "id": "call_ap_1", "server_label": "wingman", "name": "create_issue", "arguments": "{\"title\":\"First\"}"},
+				{"type": "mcp_approval_response", "approval_request_id": "call_ap_1", "approve": false, "reason": "duplicate"}
+			]
+		}`)
+	}
+
+	t.Run("non-streaming", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/responses", bytes.NewReader(body(false)))
+		rec := httptest.NewRecorder()
+
+		New(cfg).handleResponses(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		if len(completer.gotMessages) != 3 {
+			t.Fatalf("expected 3 messages, got %+v", completer.gotMessages)
+		}
+
+		call := completer.gotMessages[1].Content[0].ToolCall
+
+		if call == nil || call.Kind != provider.ToolKindApproval || call.ID != "call_ap_1" || call.Name != "create_issue" {
+			t.Fatalf("approval request not converted: %+v", call)
+		}
+
+		result := completer.gotMessages[2].Content[0].ToolResult
+
+		if result == nil || result.Kind != provider.ToolKindApproval || !result.IsError || result.Parts[0].Text != "duplicate" {
+			t.Fatalf("approval response not converted: %+v", result)
+		}
+
+		var resp struct {
+			Output []map[string]any `json:"output"`
+		}
+
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal response: %v\n%s", err, rec.Body.String())
+		}
+
+		if len(resp.Output) != 1 {
+			t.Fatalf("expected 1 output item, got %+v", resp.Output)
+		}
+
+		item := resp.Output[0]
+
+		if item["type"] != "mcp_approval_request" || item["id"] != "call_ap_2" || item["name"] != "create_issue" || item["arguments"] != `{"title":"Second"}` {
+			t.Fatalf("unexpected approval request item: %+v", item)
+		}
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/responses", bytes.NewReader(body(true)))
+		rec := httptest.NewRecorder()
+
+		New(cfg).handleResponses(rec, req)
+
+		out := rec.Body.String()
+
+		if strings.Contains(out, "function_call") {
+			t.Fatalf("approval request streamed as a function call:\n%s", out)
+		}
+
+		if strings.Count(out, `"type":"mcp_approval_request"`) < 2 {
+			t.Fatalf("expected added and done events for the approval request:\n%s", out)
+		}
+	})
+}
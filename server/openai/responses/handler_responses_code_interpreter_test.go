@@ -0,0 +1,160 @@
+package responses
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/config"
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/policy/noop"
+	"github.com/adrianliechti/wingman/pkg/provider"
+	"github.com/adrianliechti/wingman/pkg/tool"
+)
+
+// interpreterCompleter runs a command in the sandbox, then answers with its
+// output
+type interpreterCompleter struct{}
+
+func (interpreterCompleter) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		last := messages[len(messages)-1]
+
+		if len(last.Content) > 0 && last.Content[0].ToolResult != nil {
+			yield(&provider.Completion{
+				Status:  provider.CompletionStatusCompleted,
+				Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{provider.TextContent("It printed " + last.Content[0].ToolResult.Parts[0].Text)}},
+			}, nil)
+
+			return
+		}
+
+		yield(&provider.Completion{
+			Status: provider.CompletionStatusCompleted,
+			Message: &provider.Message{Role: provider.MessageRoleAssistant, Content: []provider.Content{
+				{ToolCall: &provider.ToolCall{ID: "call_1", Name: "sandbox", Arguments: `{"commands":["echo hello"]}`}},
+			}},
+		}, nil)
+	}
+}
+
+// fakeSandbox echoes the session it runs in
+type fakeSandbox struct{}
+
+func (fakeSandbox) Tools(ctx context.Context) ([]tool.Tool, error) {
+	return []tool.Tool{{Name: "sandbox"}}, nil
+}
+
+func (fakeSandbox) Execute(ctx context.Context, name string, parameters map[string]any) (any, error) {
+	return "hello from " + agent.Session(ctx), nil
+}
+
+func (fakeSandbox) Result(name string, value any) provider.ToolResult {
+	return provider.ToolResult{Parts: []provider.Part{{Text: value.(string)}}}
+}
+
+func TestCodeInterpreter(t *testing.T) {
+	const modelID = "interpreter-model"
+
+	cfg := &config.Config{Policy: noop.New(), CodeInterpreter: fakeSandbox{}}
+	cfg.RegisterCompleter(modelID, interpreterCompleter{})
+
+	request := func(cfg *config.Config, stream bool) *httptest.ResponseRecorder {
+		body := `{"model": "` + modelID + `", "input": "say hello", "include": ["code_interpreter_call.outputs"], "tools": [{"type": "code_interpreter", "container": "cntr_1"}], "stream": ` + map[bool]string{true: "true", false: "false"}[stream] + `}`
+
+		req := httptest.NewRequest(http.MethodPost, "/responses", bytes.NewReader([]byte(body)))
+		rec := httptest.NewRecorder()
+
+		New(cfg).handleResponses(rec, req)
+
+		return rec
+	}
+
+	t.Run("non-streaming", func(t *testing.T) {
+		rec := request(cfg, false)
+
+		var resp struct {
+			Output []map[string]any `json:"output"`
+		}
+
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal response: %v\n%s", err, rec.Body.String())
+		}
+
+		if len(resp.Output) != 2 {
+			t.Fatalf("expected the call and the message, got %+v", resp.Output)
+		}
+
+		call := resp.Output[0]
+
+		if call["type"] != "code_interpreter_call" || call["id"] != "ci_call_1" || call["status"] != "completed" || call["container_id"] != "cntr_1" || call["code"] != "echo hello" {
+			t.Fatalf("unexpected code_interpreter_call item: %+v", call)
+		}
+
+		outputs, _ := call["outputs"].([]any)
+
+		if len(outputs) != 1 || outputs[0].(map[string]any)["logs"] != "hello from cntr_1" {
+			t.Fatalf("expected the logs of the container's session, got %+v", call["outputs"])
+		}
+
+		if !strings.Contains(rec.Body.String(), "It printed hello from cntr_1") {
+			t.Fatalf("expected the answer to use the output, got %s", rec.Body.String())
+		}
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		var events []string
+
+		for _, line := range strings.Split(request(cfg, true).Body.String(), "\n") {
+			data, ok := strings.CutPrefix(line, "data: ")
+
+			if !ok {
+				continue
+			}
+
+			var event map[string]any
+
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("invalid event %q: %v", data, err)
+			}
+
+			typ, _ := event["type"].(string)
+
+			if item, ok := event["item"].(map[string]any); ok {
+				typ += ":" + item["type"].(string) + ":" + item["status"].(string)
+			}
+
+			events = append(events, typ)
+		}
+
+		want := []string{
+			"response.created",
+			"response.in_progress",
+			"response.output_item.added:code_interpreter_call:in_progress",
+			"response.code_interpreter_call.in_progress",
+			"response.code_interpreter_call_code.done",
+			"response.code_interpreter_call.interpreting",
+			"response.code_interpreter_call.completed",
+			"response.output_item.done:code_interpreter_call:completed",
+			"response.output_item.added:message:in_progress",
+		}
+
+		if len(events) < len(want) || strings.Join(events[:len(want)], ",") != strings.Join(want, ",") {
+			t.Fatalf("unexpected events:\n%s", strings.Join(events, "\n"))
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := &config.Config{Policy: noop.New()}
+		disabled.RegisterCompleter(modelID, interpreterCompleter{})
+
+		if rec := request(disabled, false); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "tools[0].type") {
+			t.Fatalf("expected the request to be rejected, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package responses
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/config"
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/policy/noop"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// progressCompleter reports one tool call the way a react agent streaming
+// tool progress does, then answers.
+type progressCompleter struct{}
+
+func (progressCompleter) Complete(ctx context.Context, messages []provider.Message, options *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		if report := agent.Progress(ctx); report != nil {
+			report(ctx, agent.ToolProgress{ID: "call_1", Name: "web_search", Status: agent.ToolStatusInProgress, Arguments: `{"query":"go"}`})
+			report(ctx, agent.ToolProgress{ID: "call_1", Name: "web_search", Status: agent.ToolStatusInProgress, Arguments: `{"query":"go"}`, Output: "searching"})
+			report(ctx, agent.ToolProgress{ID: "call_1", Name: "web_search", Status: agent.ToolStatusCompleted, Arguments: `{"query":"go"}`, Output: "Go is a language"})
+		}
+
+		yield(&provider.Completion{
+			ID:     "resp_progress",
+			Status: provider.CompletionStatusCompleted,
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{provider.TextContent("Go is a language.")},
+			},
+		}, nil)
+	}
+}
+
+func TestHostedToolProgress(t *testing.T) {
+	const modelID = "progress-agent"
+
+	cfg := &config.Config{Policy: noop.New()}
+	cfg.RegisterCompleter(modelID, progressCompleter{})
+
+	request := func(stream bool) *httptest.ResponseRecorder {
+		body := `{"model": "` + modelID + `", "input": "what is go?", "stream": ` + map[bool]string{true: "true", false: "false"}[stream] + `}`
+
+		req := httptest.NewRequest(http.MethodPost, "/responses", bytes.NewReader([]byte(body)))
+		rec := httptest.NewRecorder()
+
+		New(cfg).handleResponses(rec, req)
+
+		return rec
+	}
+
+	t.Run("non-streaming", func(t *testing.T) {
+		rec := request(false)
+
+		var resp struct {
+			Output []map[string]any `json:"output"`
+		}
+
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unmarshal response: %v\n%s", err, rec.Body.String())
+		}
+
+		if len(resp.Output) != 2 {
+			t.Fatalf("expected the tool call and the message, got %+v", resp.Output)
+		}
+
+		call := resp.Output[0]
+
+		if call["type"] != "mcp_call" || call["id"] != "call_1" || call["status"] != "completed" || call["output"] != "Go is a language" {
+			t.Fatalf("unexpected mcp_call item: %+v", call)
+		}
+
+		if resp.Output[1]["type"] != "message" {
+			t.Fatalf("expected the message second, got %+v", resp.Output[1])
+		}
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		body := request(true).Body.String()
+
+		var events []string
+		var completed map[string]any
+
+		for _, line := range strings.Split(body, "\n") {
+			data, ok := strings.CutPrefix(line, "data: ")
+
+			if !ok {
+				continue
+			}
+
+			var event map[string]any
+
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("invalid event %q: %v", data, err)
+			}
+
+			typ, _ := event["type"].(string)
+
+			if item, ok := event["item"].(map[string]any); ok {
+				typ += ":" + item["type"].(string) + ":" + item["status"].(string)
+			}
+
+			events = append(events, typ)
+
+			if typ == "response.completed" {
+				completed = event["response"].(map[string]any)
+			}
+		}
+
+		want := []string{
+			"response.created",
+			"response.in_progress",
+			"response.output_item.added:mcp_call:in_progress",
+			"response.mcp_call.in_progress",
+			"tool_progress",
+			"response.mcp_call_arguments.done",
+			"response.mcp_call.completed",
+			"response.output_item.done:mcp_call:completed",
+			"response.output_item.added:message:in_progress",
+		}
+
+		if len(events) < len(want) || strings.Join(events[:len(want)], ",") != strings.Join(want, ",") {
+			t.Fatalf("unexpected events:\n%s", strings.Join(events, "\n"))
+		}
+
+		output, _ := completed["output"].([]any)
+
+		if len(output) != 2 || output[0].(map[string]any)["type"] != "mcp_call" {
+			t.Fatalf("expected the tool call in the completed response, got %+v", output)
+		}
+	})
+}
+
+func TestWithHostedKeepsOutputIndex(t *testing.T) {
+	reasoning := ResponseOutput{Type: ResponseOutputTypeReasoning}
+	message := ResponseOutput{Type: ResponseOutputTypeMessage}
+
+	call := ResponseOutput{Type: ResponseOutputTypeMCPCall, MCPCallItem: &MCPCallItem{ID: "call_1"}}
+	late := ResponseOutput{Type: ResponseOutputTypeMCPCall, MCPCallItem: &MCPCallItem{ID: "call_2"}}
+
+	output := withHosted([]hostedOutput{{3, late}, {1, call}}, []ResponseOutput{reasoning, message})
+
+	var types []string
+
+	for _, o := range output {
+		types = append(types, string(o.Type))
+	}
+
+	if want := []string{"reasoning", "mcp_call", "message", "mcp_call"}; strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, types)
+	}
+
+	if output[1].MCPCallItem.ID != "call_1" || output[3].MCPCallItem.ID != "call_2" {
+		t.Fatalf("unexpected order: %+v", output)
+	}
+}
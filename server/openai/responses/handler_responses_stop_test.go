@@ -0,0 +1,144 @@
+package responses
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adrianliechti/wingman/config"
+	"github.com/adrianliechti/wingman/pkg/policy/noop"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+const stopTestModel = "stop-test-model"
+
+// stopCompleter streams a short answer and ends with the given completion.
+type stopCompleter struct {
+	final provider.Completion
+}
+
+func (c stopCompleter) Complete(_ context.Context, _ []provider.Message, _ *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	return func(yield func(*provider.Completion, error) bool) {
+		if !yield(&provider.Completion{
+			Message: &provider.Message{
+				Role:    provider.MessageRoleAssistant,
+				Content: []provider.Content{provider.TextContent("so far")},
+			},
+		}, nil) {
+			return
+		}
+
+		final := c.final
+		yield(&final, nil)
+	}
+}
+
+func postStop(t *testing.T, final provider.Completion, stream bool) string {
+	t.Helper()
+
+	cfg := &config.Config{Policy: noop.New()}
+	cfg.RegisterCompleter(stopTestModel, stopCompleter{final: final})
+
+	mode := "false"
+	if stream {
+		mode = "true"
+	}
+
+	body := []byte(`{"model": "` + stopTestModel + `", "stream": ` + mode + `, "input": "x"}`)
+	req := httptest.NewRequest(http.MethodPost, "/responses", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	New(cfg).handleResponses(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	return rec.Body.String()
+}
+
+func TestAgentLimitIncompleteDetails(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonAgentLimit,
+		StopDetails: &provider.StopDetails{
+			Type:        "agent_limit",
+			Category:    "max_turns",
+			Explanation: "the agent reached its limit of 3 turns",
+		},
+	}
+
+	want := `"incomplete_details":{"reason":"agent_limit","category":"max_turns","explanation":"the agent reached its limit of 3 turns"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
+
+func TestMaxTokensIncompleteDetails(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonMaxTokens,
+	}
+
+	want := `"incomplete_details":{"reason":"max_output_tokens"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
+
+func TestSchemaMismatchIncompleteDetails(t *testing.T) {
+	final := provider.Completion{
+		Status:     provider.CompletionStatusIncomplete,
+		StopReason: provider.StopReasonSchemaMismatch,
+		StopDetails: &provider.StopDetails{
+			Type:        "schema",
+			Category:    "invalid",
+			Explanation: "missing property name",
+		},
+	}
+
+	want := `"incomplete_details":{"reason":"schema_mismatch","category":"invalid","explanation":"missing property name"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing %s in\n%s", stream, want, body)
+		}
+	}
+}
+
+func TestSchemaRepairedStopDetails(t *testing.T) {
+	final := provider.Completion{
+		StopReason: provider.StopReasonEndTurn,
+		StopDetails: &provider.StopDetails{
+			Type:        "schema",
+			Category:    "repaired",
+			Explanation: "output matched the schema after 2 attempts",
+		},
+	}
+
+	want := `"stop_details":{"type":"schema","category":"repaired","explanation":"output matched the schema after 2 attempts"}`
+
+	for _, stream := range []bool{false, true} {
+		body := postStop(t, final, stream)
+
+		if !strings.Contains(body, `"status":"completed"`) || !strings.Contains(body, want) {
+			t.Fatalf("stream=%v: missing completed status with %s in\n%s", stream, want, body)
+		}
+	}
+}
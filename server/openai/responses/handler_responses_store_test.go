@@ -45,9 +45,9 @@ func postResponses(t *testing.T, h *Handler, body string) *httptest.ResponseReco
 	return rec
 }
 
-// Store and previous_response_id are unknown to wingman — silently accepted
-// and dropped by json.Unmarshal. The response always carries store=false as
-// the "we don't persist" signal.
+// Store is unknown to wingman and previous_response_id only continues the
+// session of server-side tools — both are silently accepted. The response
+// always carries store=false as the "we don't persist" signal.
 
 func TestStoreTrueAcceptedAndResponseEchoesStoreFalse(t *testing.T) {
 	h := newStoreHandler(t)
@@ -33,6 +33,27 @@ type ResponsesRequest struct {
 	ParallelToolCalls *bool       `json:"parallel_tool_calls,omitempty"`
 
 	Truncation string `json:"truncation,omitempty"`
+
+	// Conversation is a conversation id or an object with an id
+	Conversation any `json:"conversation,omitempty"`
+
+	// PreviousResponseID only continues the session of server-side tools;
+	// responses are not stored
+	PreviousResponseID string `json:"previous_response_id,omitempty"`
+}
+
+// ConversationID returns the id of the conversation of the request, or ""
+func (r ResponsesRequest) ConversationID() string {
+	switch c := r.Conversation.(type) {
+	case string:
+		return c
+
+	case map[string]any:
+		id, _ := c["id"].(string)
+		return id
+	}
+
+	return ""
 }
 
 // ContextManagementConfig represents a context management entry
@@ -99,6 +120,8 @@ const (
 	ToolTypeNamespace  ToolType = "namespace"
 	ToolTypeToolSearch ToolType = "tool_search"
 	ToolTypeWebSearch  ToolType = "web_search"
+
+	ToolTypeCodeInterpreter ToolType = "code_interpreter"
 )
 
 // Tool represents a tool in the request
@@ -129,6 +152,9 @@ type Tool struct {
 	DisplayWidth  int    `json:"display_width,omitempty"`
 	DisplayHeight int    `json:"display_height,omitempty"`
 	Environment   string `json:"environment,omitempty"` // "browser", "ubuntu", "windows", "mac"
+
+	// For code_interpreter tools: a container id, or {"type": "auto"}
+	Container any `json:"container,omitempty"`
 }
 
 type ToolChoice struct {
@@ -299,6 +325,8 @@ const (
 	InputItemTypeToolSearchCall       InputItemType = "tool_search_call"
 	InputItemTypeToolSearchOutput     InputItemType = "tool_search_output"
 	InputItemTypeCompactionTrigger    InputItemType = "compaction_trigger"
+	InputItemTypeMCPApprovalRequest   InputItemType = "mcp_approval_request"
+	InputItemTypeMCPApprovalResponse  InputItemType = "mcp_approval_response"
 )
 
 type ResponsesInput struct {
@@ -356,6 +384,12 @@ type InputItem struct {
 
 	// For tool_search_output type
 	*InputToolSearchOutput
+
+	// For mcp_approval_request type
+	*InputMCPApprovalRequest
+
+	// For mcp_approval_response type
+	*InputMCPApprovalResponse
 }
 
 // InputAdditionalTools makes tools available from this point in the input.
@@ -467,6 +501,24 @@ type InputToolSearchOutput struct {
 	Tools     json.RawMessage `json:"tools,omitempty"`
 }
 
+// InputMCPApprovalRequest represents a prior mcp_approval_request echoed
+// back by the client
+type InputMCPApprovalRequest struct {
+	ID          string `json:"id"`
+	ServerLabel string `json:"server_label,omitempty"`
+	Name        string `json:"name"`
+	Arguments   string `json:"arguments,omitempty"`
+}
+
+// InputMCPApprovalResponse carries the client's decision on an
+// mcp_approval_request
+type InputMCPApprovalResponse struct {
+	ID                string `json:"id,omitempty"`
+	ApprovalRequestID string `json:"approval_request_id"`
+	Approve           bool   `json:"approve"`
+	Reason            string `json:"reason,omitempty"`
+}
+
 // InputCustomToolCall represents a custom (freeform grammar) tool call in the
 // input. Codex registers apply_patch as a custom tool, so its prior calls come
 // back as custom_tool_call items whose Input is the raw patch envelope.
@@ -735,6 +787,20 @@ func (ri *ResponsesInput) UnmarshalJSON(data []byte) error {
 			}
 			item.InputToolSearchOutput = &tso
 
+		case InputItemTypeMCPApprovalRequest:
+			var ar InputMCPApprovalRequest
+			if err := json.Unmarshal(raw, &ar); err != nil {
+				return err
+			}
+			item.InputMCPApprovalRequest = &ar
+
+		case InputItemTypeMCPApprovalResponse:
+			var ar InputMCPApprovalResponse
+			if err := json.Unmarshal(raw, &ar); err != nil {
+				return err
+			}
+			item.InputMCPApprovalResponse = &ar
+
 		case InputItemTypeCompactionTrigger:
 			// bare marker item (Codex remote compaction); carries no payload
 
@@ -878,6 +944,10 @@ type Response struct {
 	Error             *ResponseError     `json:"error"`
 	IncompleteDetails *IncompleteDetails `json:"incomplete_details"`
 
+	// StopDetails tells how structured output of a completed response
+	// matched its schema; not part of the OpenAI API
+	StopDetails *StopDetails `json:"stop_details,omitempty"`
+
 	Instructions       *string `json:"instructions"`
 	MaxOutputTokens    *int    `json:"max_output_tokens"`
 	MaxToolCalls       *int    `json:"max_tool_calls"`
@@ -948,7 +1018,17 @@ type ResponseError struct {
 
 // IncompleteDetails explains why a response stopped short
 type IncompleteDetails struct {
-	Reason string `json:"reason"` // max_output_tokens, content_filter
+	Reason string `json:"reason"` // max_output_tokens, content_filter, agent_limit, schema_mismatch
+
+	Category    string `json:"category,omitempty"` // e.g. max_turns for agent_limit
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// StopDetails explains how a completed response ended
+type StopDetails struct {
+	Type        string `json:"type"`               // schema
+	Category    string `json:"category,omitempty"` // repaired
+	Explanation string `json:"explanation,omitempty"`
 }
 
 type ResponseOutput struct {
@@ -961,6 +1041,9 @@ type ResponseOutput struct {
 	*ComputerCallItem
 	*ShellCallItem
 	*ToolSearchCallItem
+	*MCPApprovalRequestItem
+	*MCPCallItem
+	*CodeInterpreterCallItem
 	*ReasoningOutputItem
 	*CompactionOutputItem
 }
@@ -1093,6 +1176,36 @@ func (r ResponseOutput) MarshalJSON() ([]byte, error) {
 				Arguments: arguments,
 			})
 		}
+	case ResponseOutputTypeMCPApprovalRequest:
+		if r.MCPApprovalRequestItem != nil {
+			return json.Marshal(struct {
+				Type        ResponseOutputType `json:"type"`
+				ID          string             `json:"id"`
+				ServerLabel string             `json:"server_label"`
+				Name        string             `json:"name"`
+				Arguments   string             `json:"arguments"`
+			}{
+				Type:        r.Type,
+				ID:          r.MCPApprovalRequestItem.ID,
+				ServerLabel: r.MCPApprovalRequestItem.ServerLabel,
+				Name:        r.MCPApprovalRequestItem.Name,
+				Arguments:   r.MCPApprovalRequestItem.Arguments,
+			})
+		}
+	case ResponseOutputTypeMCPCall:
+		if r.MCPCallItem != nil {
+			item := *r.MCPCallItem
+			item.Type = string(r.Type)
+
+			return json.Marshal(item)
+		}
+	case ResponseOutputTypeCodeInterpreterCall:
+		if r.CodeInterpreterCallItem != nil {
+			item := *r.CodeInterpreterCallItem
+			item.Type = string(r.Type)
+
+			return json.Marshal(item)
+		}
 	case ResponseOutputTypeCustomToolCall:
 		if r.CustomToolCallItem != nil {
 			return json.Marshal(struct {
@@ -1163,8 +1276,54 @@ var (
 	ResponseOutputTypeToolSearchCall ResponseOutputType = "tool_search_call"
 	ResponseOutputTypeReasoning      ResponseOutputType = "reasoning"
 	ResponseOutputTypeCompaction     ResponseOutputType = "compaction"
+
+	ResponseOutputTypeMCPApprovalRequest ResponseOutputType = "mcp_approval_request"
+	ResponseOutputTypeMCPCall            ResponseOutputType = "mcp_call"
+
+	ResponseOutputTypeCodeInterpreterCall ResponseOutputType = "code_interpreter_call"
 )
 
+// CodeInterpreterCallItem reports commands the server ran in its sandbox for
+// a code_interpreter tool
+type CodeInterpreterCallItem struct {
+	ID          string                  `json:"id"`
+	Type        string                  `json:"type"`   // code_interpreter_call
+	Status      string                  `json:"status"` // in_progress, interpreting, completed, failed
+	ContainerID string                  `json:"container_id"`
+	Code        *string                 `json:"code"`
+	Outputs     []CodeInterpreterOutput `json:"outputs"`
+}
+
+// CodeInterpreterOutput is the output of a code_interpreter call; only the
+// logs type is produced
+type CodeInterpreterOutput struct {
+	Type string `json:"type"` // logs
+	Logs string `json:"logs"`
+}
+
+// MCPCallItem reports a tool call the server ran itself, such as the tool
+// calls of an agent
+type MCPCallItem struct {
+	ID          string  `json:"id"`
+	Type        string  `json:"type"` // mcp_call
+	ServerLabel string  `json:"server_label"`
+	Name        string  `json:"name"`
+	Arguments   string  `json:"arguments"`
+	Output      *string `json:"output,omitempty"`
+	Error       *string `json:"error,omitempty"`
+	Status      string  `json:"status"` // in_progress, completed, failed
+}
+
+// MCPApprovalRequestItem asks the client to approve a tool call before it
+// runs. The item id is the approval_request_id of the client's answer.
+type MCPApprovalRequestItem struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"` // mcp_approval_request
+	ServerLabel string `json:"server_label"`
+	Name        string `json:"name"`
+	Arguments   string `json:"arguments"`
+}
+
 // ToolSearchCallItem represents a tool_search call in the output.
 type ToolSearchCallItem struct {
 	ID        string          `json:"id"`
@@ -1686,6 +1845,91 @@ type ComputerCallOutputItemDoneEvent struct {
 	Item           *ComputerCallItem `json:"item"`
 }
 
+// MCPApprovalRequestOutputItemAddedEvent wraps mcp_approval_request in output_item.added
+type MCPApprovalRequestOutputItemAddedEvent struct {
+	Type           string                  `json:"type"` // response.output_item.added
+	SequenceNumber int                     `json:"sequence_number"`
+	OutputIndex    int                     `json:"output_index"`
+	Item           *MCPApprovalRequestItem `json:"item"`
+}
+
+// MCPApprovalRequestOutputItemDoneEvent wraps mcp_approval_request in output_item.done
+type MCPApprovalRequestOutputItemDoneEvent struct {
+	Type           string                  `json:"type"` // response.output_item.done
+	SequenceNumber int                     `json:"sequence_number"`
+	OutputIndex    int                     `json:"output_index"`
+	Item           *MCPApprovalRequestItem `json:"item"`
+}
+
+// MCPCallOutputItemAddedEvent wraps mcp_call in output_item.added
+type MCPCallOutputItemAddedEvent struct {
+	Type           string       `json:"type"` // response.output_item.added
+	SequenceNumber int          `json:"sequence_number"`
+	OutputIndex    int          `json:"output_index"`
+	Item           *MCPCallItem `json:"item"`
+}
+
+// MCPCallOutputItemDoneEvent wraps mcp_call in output_item.done
+type MCPCallOutputItemDoneEvent struct {
+	Type           string       `json:"type"` // response.output_item.done
+	SequenceNumber int          `json:"sequence_number"`
+	OutputIndex    int          `json:"output_index"`
+	Item           *MCPCallItem `json:"item"`
+}
+
+// MCPCallStatusEvent is response.mcp_call.in_progress, .completed or .failed
+type MCPCallStatusEvent struct {
+	Type           string `json:"type"`
+	SequenceNumber int    `json:"sequence_number"`
+	OutputIndex    int    `json:"output_index"`
+	ItemID         string `json:"item_id"`
+}
+
+// MCPCallArgumentsDoneEvent is response.mcp_call_arguments.done
+type MCPCallArgumentsDoneEvent struct {
+	Type           string `json:"type"`
+	SequenceNumber int    `json:"sequence_number"`
+	OutputIndex    int    `json:"output_index"`
+	ItemID         string `json:"item_id"`
+	Arguments      string `json:"arguments"`
+}
+
+// CodeInterpreterCallOutputItemAddedEvent wraps code_interpreter_call in
+// output_item.added
+type CodeInterpreterCallOutputItemAddedEvent struct {
+	Type           string                   `json:"type"` // response.output_item.added
+	SequenceNumber int                      `json:"sequence_number"`
+	OutputIndex    int                      `json:"output_index"`
+	Item           *CodeInterpreterCallItem `json:"item"`
+}
+
+// CodeInterpreterCallOutputItemDoneEvent wraps code_interpreter_call in
+// output_item.done
+type CodeInterpreterCallOutputItemDoneEvent struct {
+	Type           string                   `json:"type"` // response.output_item.done
+	SequenceNumber int                      `json:"sequence_number"`
+	OutputIndex    int                      `json:"output_index"`
+	Item           *CodeInterpreterCallItem `json:"item"`
+}
+
+// CodeInterpreterCallStatusEvent is response.code_interpreter_call.in_progress,
+// .interpreting or .completed
+type CodeInterpreterCallStatusEvent struct {
+	Type           string `json:"type"`
+	SequenceNumber int    `json:"sequence_number"`
+	OutputIndex    int    `json:"output_index"`
+	ItemID         string `json:"item_id"`
+}
+
+// CodeInterpreterCallCodeDoneEvent is response.code_interpreter_call_code.done
+type CodeInterpreterCallCodeDoneEvent struct {
+	Type           string `json:"type"`
+	SequenceNumber int    `json:"sequence_number"`
+	OutputIndex    int    `json:"output_index"`
+	ItemID         string `json:"item_id"`
+	Code           string `json:"code"`
+}
+
 // ToolSearchCallOutputItemAddedEvent wraps tool_search_call in output_item.added
 type ToolSearchCallOutputItemAddedEvent struct {
 	Type           string              `json:"type"` // response.output_item.added
@@ -0,0 +1,51 @@
+package responses
+
+import (
+	"sync"
+)
+
+// maxChainedResponses bounds the responses whose session is remembered
+const maxChainedResponses = 4096
+
+// sessionChain remembers the session of recent responses, so a request
+// continuing one with previous_response_id works in the same session
+type sessionChain struct {
+	mu sync.Mutex
+
+	sessions map[string]string
+
+	// order holds the response ids oldest first, for eviction
+	order []string
+}
+
+func newSessionChain() *sessionChain {
+	return &sessionChain{
+		sessions: make(map[string]string),
+	}
+}
+
+func (c *sessionChain) get(id string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	session, ok := c.sessions[id]
+	return session, ok
+}
+
+func (c *sessionChain) put(id, session string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.sessions[id]; ok {
+		c.sessions[id] = session
+		return
+	}
+
+	if len(c.order) >= maxChainedResponses {
+		delete(c.sessions, c.order[0])
+		c.order = c.order[1:]
+	}
+
+	c.sessions[id] = session
+	c.order = append(c.order, id)
+}
@@ -0,0 +1,63 @@
+package responses
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"testing"
+
+	"github.com/adrianliechti/wingman/config"
+	"github.com/adrianliechti/wingman/pkg/agent"
+	"github.com/adrianliechti/wingman/pkg/policy/noop"
+	"github.com/adrianliechti/wingman/pkg/provider"
+)
+
+// sessionCompleter records the session of each request
+type sessionCompleter struct {
+	sessions []string
+}
+
+func (c *sessionCompleter) Complete(ctx context.Context, _ []provider.Message, _ *provider.CompleteOptions) iter.Seq2[*provider.Completion, error] {
+	c.sessions = append(c.sessions, agent.Session(ctx))
+
+	return echoCompleter{}.Complete(ctx, nil, nil)
+}
+
+func TestResponsesContinueSessionOfPreviousResponse(t *testing.T) {
+	c := &sessionCompleter{}
+
+	cfg := &config.Config{Policy: noop.New()}
+	cfg.RegisterCompleter(storeTestModel, c)
+
+	h := New(cfg)
+
+	rec := postResponses(t, h, `{"model": "`+storeTestModel+`", "input": "hello"}`)
+
+	session := rec.Header().Get("X-Session-Id")
+
+	if session == "" || c.sessions[0] != session {
+		t.Fatalf("expected the new session %q in the header, got %q", c.sessions[0], session)
+	}
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stream := range []string{"false", "true"} {
+		rec = postResponses(t, h, `{"model": "`+storeTestModel+`", "stream": `+stream+`, "previous_response_id": "`+resp.ID+`", "input": "again"}`)
+
+		if got := c.sessions[len(c.sessions)-1]; got != session || rec.Header().Get("X-Session-Id") != session {
+			t.Fatalf("expected the session %q to continue, got %q", session, got)
+		}
+	}
+
+	postResponses(t, h, `{"model": "`+storeTestModel+`", "previous_response_id": "resp_unknown", "input": "hello"}`)
+
+	if got := c.sessions[len(c.sessions)-1]; got == "" || got == session {
+		t.Fatalf("expected a new session for an unknown response, got %q", got)
+	}
+}
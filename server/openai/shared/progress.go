@@ -0,0 +1,66 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+)
+
+// ToolProgressEventType is the SSE event name streams without a native item
+// for hosted tool calls (chat completions, messages) report agent tool
+// progress under. Responses streams use it for progress messages only.
+const ToolProgressEventType = "tool_progress"
+
+// ToolProgressEvent reports a tool call an agent runs on its own
+type ToolProgressEvent struct {
+	Type string `json:"type"` // tool_progress
+
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	Depth int `json:"depth"`
+
+	Status string `json:"status"` // in_progress, completed, failed
+
+	Arguments string `json:"arguments,omitempty"`
+
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func NewToolProgressEvent(p agent.ToolProgress) ToolProgressEvent {
+	return ToolProgressEvent{
+		Type: ToolProgressEventType,
+
+		ID:   p.ID,
+		Name: p.Name,
+
+		Depth: p.Depth,
+
+		Status: string(p.Status),
+
+		Arguments: p.Arguments,
+
+		Output: p.Output,
+		Error:  p.Error,
+	}
+}
+
+// WriteToolProgressEvent writes p as a tool_progress SSE event
+func WriteToolProgressEvent(w http.ResponseWriter, p agent.ToolProgress) error {
+	var data bytes.Buffer
+
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.Encode(NewToolProgressEvent(p))
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ToolProgressEventType, strings.TrimSpace(data.String())); err != nil {
+		return err
+	}
+
+	return http.NewResponseController(w).Flush()
+}
@@ -0,0 +1,26 @@
+package shared
+
+import (
+	"net/http"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+
+	"github.com/google/uuid"
+)
+
+// WithSession runs the request in the session the client named or, without
+// one, in a new session. The session is returned in the X-Session-Id header,
+// so clients can reach the files server-side tools keep for it and continue
+// the session in later requests.
+func WithSession(w http.ResponseWriter, r *http.Request) *http.Request {
+	session := agent.Session(r.Context())
+
+	if session == "" {
+		session = uuid.NewString()
+		r = r.WithContext(agent.WithSession(r.Context(), session))
+	}
+
+	w.Header().Set("X-Session-Id", session)
+
+	return r
+}
@@ -69,12 +69,17 @@ func New(cfg *config.Config) (*Server, error) {
 
 		AllowedHeaders: []string{"*"},
 
+		// Clients learn new sessions from the response
+		ExposedHeaders: []string{"X-Session-Id"},
+
 		MaxAge: 300,
 	}))
 
 	mux.Use(otelhttp.NewMiddleware("http"))
 	mux.Use(handleRouteTag)
 	mux.Use(s.handleAuth)
+	mux.Use(s.handlePriority)
+	mux.Use(s.handleSession)
 
 	mux.Route("/v1", func(r chi.Router) {
 		s.api.Attach(r)
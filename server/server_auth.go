@@ -3,6 +3,7 @@ package server
 import (
 	"net/http"
 
+	"github.com/adrianliechti/wingman/pkg/limiter"
 	"github.com/adrianliechti/wingman/pkg/otel"
 )
 
@@ -31,3 +32,16 @@ func (s *Server) handleAuth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+func (s *Server) handlePriority(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Priority == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := limiter.WithPriority(r.Context(), s.Priority.Select(r))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
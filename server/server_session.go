@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/adrianliechti/wingman/pkg/agent"
+)
+
+// handleSession runs the request in the session named by the X-Session-Id
+// header, so server-side tools such as the text editor keep their workspace
+// across requests of the session
+func (s *Server) handleSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := strings.TrimSpace(r.Header.Get("X-Session-Id"))
+
+		if session == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := agent.WithSession(r.Context(), session)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}